@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"glox/emitgo"
+	"glox/emitjs"
+	"os"
+)
+
+// buildCmd implements `glox build --emit=go|js [-o file] script`, which
+// compiles a script and the modules it imports to a Go program or a
+// JavaScript module. The Go program imports the runtime package glox/rt,
+// so it is built from within the glox module, as with
+// `go build -o script script.go`, or from a module that requires glox and
+// replaces it with a checkout of glox, as described by emitgo.Emit. The
+// JavaScript module carries its runtime, and exports the function running
+// it. It returns the exit status.
+func buildCmd(args []string) int {
+	flags := flag.NewFlagSet("build", flag.ExitOnError)
+	emit := flags.String("emit", "go", "the language to compile to: go or js")
+	output := flags.String("o", "", "write the program to `file` instead of stdout")
+	flags.Parse(args)
+
+	if flags.NArg() != 1 {
+		fmt.Fprintln(os.Stderr, "Usage: glox build --emit=go|js [-o file] script")
+		return 64
+	}
+
+	emitters := map[string]func(string) ([]byte, error){
+		"go": emitgo.Emit,
+		"js": emitjs.Emit,
+	}
+
+	emitter, ok := emitters[*emit]
+	if !ok {
+		fmt.Fprintf(os.Stderr, "Unknown language '%s'.\n", *emit)
+		return 64
+	}
+
+	src, err := emitter(flags.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 65
+	}
+
+	if *output == "" {
+		os.Stdout.Write(src)
+		return 0
+	}
+
+	if err := os.WriteFile(*output, src, 0o644); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 73
+	}
+
+	return 0
+}
@@ -0,0 +1,231 @@
+package cache
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"glox/generated"
+	"glox/parser"
+	"glox/resolver"
+	"glox/scanner"
+	"os"
+	"path/filepath"
+)
+
+// A cache file holds the SHA-256 hash of the source it was written for and
+// the resolver.Version of the bindings, then the length of the encoded tree and the tree itself, then the
+// bindings of the resolver: the number of bound expressions followed by
+// the index and scope distance of each, and the number of tail calls
+// followed by the index of each. Indexes are into the list of expressions
+// returned by generated.DecodeStmts.
+
+var (
+	errStale   = errors.New("stale cache")
+	errCorrupt = errors.New("corrupt cache")
+)
+
+// Path returns the file caching the script at path, kept next to it.
+func Path(path string) string {
+	return path + "c"
+}
+
+// Load returns the resolved statements of the script at path, whose
+// contents are source, and binds their variables in b as the resolver
+// would.
+//
+// The statements come from the cache next to the script when it was
+// written for the same source by a build with the same nodes and resolver.
+// Otherwise
+// the script is scanned, parsed and resolved, and the cache written for
+// the next run. A cache that cannot be read or written is ignored.
+func Load(path, source string, b resolver.Binder) ([]generated.Stmt, error) {
+	hash := sha256.Sum256([]byte(source))
+	if stmts, err := read(Path(path), hash, b); err == nil {
+		return stmts, nil
+	}
+
+	tokens, err := scanner.NewScanner(source).ScanTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	stmts, err := parser.NewParser(tokens).Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &recorder{
+		next:   b,
+		locals: make(map[generated.Expr]int),
+		tails:  make(map[*generated.Call]bool),
+	}
+	err = resolver.NewResolver(rec).Resolve(stmts)
+	if err != nil {
+		return nil, err
+	}
+
+	write(Path(path), hash, stmts, rec)
+
+	return stmts, nil
+}
+
+// recorder passes the bindings of the resolver on to another binder,
+// keeping a copy to cache.
+type recorder struct {
+	next   resolver.Binder
+	locals map[generated.Expr]int
+	tails  map[*generated.Call]bool
+}
+
+func (r *recorder) Resolve(expr generated.Expr, depth int) {
+	r.locals[expr] = depth
+	r.next.Resolve(expr, depth)
+}
+
+func (r *recorder) TailCall(call *generated.Call) {
+	r.tails[call] = true
+	if tc, ok := r.next.(resolver.TailCaller); ok {
+		tc.TailCall(call)
+	}
+}
+
+// read loads the cache in file if it was written for the source with the
+// given hash. The whole cache is checked before anything is bound, so
+// that a bad one leaves b as it was.
+func read(file string, hash [sha256.Size]byte, b resolver.Binder) ([]generated.Stmt, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if !bytes.HasPrefix(data, hash[:]) {
+		return nil, errStale
+	}
+	data = data[len(hash):]
+
+	v, n := binary.Uvarint(data)
+	if n <= 0 || v != resolver.Version {
+		return nil, errStale
+	}
+	data = data[n:]
+
+	size, n := binary.Uvarint(data)
+	if n <= 0 || size > uint64(len(data)-n) {
+		return nil, errCorrupt
+	}
+	stmts, list, err := generated.DecodeStmts(data[n : n+int(size)])
+	if err != nil {
+		return nil, err
+	}
+	data = data[n+int(size):]
+
+	// index reads an expression index, and uint any other number.
+	uint := func() (uint64, bool) {
+		v, n := binary.Uvarint(data)
+		if n <= 0 {
+			return 0, false
+		}
+		data = data[n:]
+		return v, true
+	}
+	index := func() (int, bool) {
+		idx, ok := uint()
+		return int(idx), ok && idx < uint64(len(list))
+	}
+
+	count, ok := uint()
+	if !ok || count > uint64(len(data)) {
+		return nil, errCorrupt
+	}
+	locals := make(map[generated.Expr]int, count)
+	for ; count > 0; count-- {
+		idx, ok := index()
+		depth, dok := uint()
+		if !ok || !dok {
+			return nil, errCorrupt
+		}
+		locals[list[idx]] = int(depth)
+	}
+
+	count, ok = uint()
+	if !ok || count > uint64(len(data)) {
+		return nil, errCorrupt
+	}
+	calls := make([]*generated.Call, 0, count)
+	for ; count > 0; count-- {
+		idx, ok := index()
+		if !ok {
+			return nil, errCorrupt
+		}
+		call, ok := list[idx].(*generated.Call)
+		if !ok {
+			return nil, errCorrupt
+		}
+		calls = append(calls, call)
+	}
+
+	if len(data) != 0 {
+		return nil, errCorrupt
+	}
+
+	for expr, depth := range locals {
+		b.Resolve(expr, depth)
+	}
+
+	if tc, ok := b.(resolver.TailCaller); ok {
+		for _, call := range calls {
+			tc.TailCall(call)
+		}
+	}
+
+	return stmts, nil
+}
+
+// write saves a resolved tree, replacing the cache only once it is
+// complete so that an interrupted write is never read.
+func write(file string, hash [sha256.Size]byte, stmts []generated.Stmt, rec *recorder) error {
+	tree, list, err := generated.EncodeStmts(stmts)
+	if err != nil {
+		return err
+	}
+
+	var locals, tails []uint64
+	for idx, expr := range list {
+		if depth, ok := rec.locals[expr]; ok {
+			locals = append(locals, uint64(idx), uint64(depth))
+		}
+		if call, ok := expr.(*generated.Call); ok && rec.tails[call] {
+			tails = append(tails, uint64(idx))
+		}
+	}
+
+	data := append([]byte{}, hash[:]...)
+	data = binary.AppendUvarint(data, resolver.Version)
+	data = binary.AppendUvarint(data, uint64(len(tree)))
+	data = append(data, tree...)
+	data = binary.AppendUvarint(data, uint64(len(locals)/2))
+	for _, v := range locals {
+		data = binary.AppendUvarint(data, v)
+	}
+	data = binary.AppendUvarint(data, uint64(len(tails)))
+	for _, v := range tails {
+		data = binary.AppendUvarint(data, v)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(file), filepath.Base(file)+".*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+
+	_, err = tmp.Write(data)
+	if cerr := tmp.Close(); err == nil {
+		err = cerr
+	}
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmp.Name(), file)
+}
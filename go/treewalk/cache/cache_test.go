@@ -0,0 +1,209 @@
+package cache
+
+import (
+	"crypto/sha256"
+	"glox/generated"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+const source = `fun f(n) { var a = n; if (n == 0) return a; return f(n - 1); }
+print f(3);
+`
+
+// binder counts the bindings it is given.
+type binder struct {
+	locals int
+	tails  int
+}
+
+func (b *binder) Resolve(expr generated.Expr, depth int) {
+	b.locals++
+}
+
+func (b *binder) TailCall(call *generated.Call) {
+	b.tails++
+}
+
+// script writes source to a new script and returns its path.
+func script(t *testing.T, source string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), "script.lox")
+	if err := os.WriteFile(path, []byte(source), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+// load loads the script at path, checking that it is bound as the
+// resolver binds source.
+func load(t *testing.T, path, source string) {
+	t.Helper()
+
+	var b binder
+	stmts, err := Load(path, source, &b)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(stmts) != 2 || b.locals != 4 || b.tails != 1 {
+		t.Errorf("got %d statements, %d locals and %d tail calls, want 2, 4 and 1", len(stmts), b.locals, b.tails)
+	}
+}
+
+// age moves the modification time of the cache of the script at path into
+// the past, and returns it, so that a rewrite of the cache shows.
+func age(t *testing.T, path string) time.Time {
+	t.Helper()
+
+	old := time.Now().Add(-time.Hour).Truncate(time.Second)
+	if err := os.Chtimes(Path(path), old, old); err != nil {
+		t.Fatal(err)
+	}
+
+	return old
+}
+
+func modified(t *testing.T, path string) time.Time {
+	t.Helper()
+
+	info, err := os.Stat(Path(path))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return info.ModTime()
+}
+
+// fresh checks that the cache of the script at path holds source.
+func fresh(t *testing.T, path, source string) {
+	t.Helper()
+
+	var b binder
+	if _, err := read(Path(path), sha256.Sum256([]byte(source)), &b); err != nil {
+		t.Errorf("cache not fresh: %v", err)
+	}
+}
+
+func TestMiss(t *testing.T) {
+	path := script(t, source)
+
+	load(t, path, source)
+	fresh(t, path, source)
+}
+
+func TestHit(t *testing.T) {
+	path := script(t, source)
+	load(t, path, source)
+
+	old := age(t, path)
+	load(t, path, source)
+
+	if got := modified(t, path); !got.Equal(old) {
+		t.Errorf("cache rewritten on a hit")
+	}
+}
+
+func TestInvalidation(t *testing.T) {
+	t.Run("source", func(t *testing.T) {
+		path := script(t, source)
+		load(t, path, source)
+
+		changed := "var unused = 1;\n" + source
+		if err := os.WriteFile(path, []byte(changed), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		var b binder
+		if _, err := read(Path(path), sha256.Sum256([]byte(changed)), &b); err != errStale {
+			t.Fatalf("got %v, want %v", err, errStale)
+		}
+		if b.locals != 0 || b.tails != 0 {
+			t.Errorf("stale cache bound %d locals and %d tail calls", b.locals, b.tails)
+		}
+
+		stmts, err := Load(path, changed, &b)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(stmts) != 3 {
+			t.Errorf("got %d statements, want 3", len(stmts))
+		}
+		fresh(t, path, changed)
+	})
+
+	t.Run("resolver", func(t *testing.T) {
+		path := script(t, source)
+		load(t, path, source)
+
+		// Pretend the cache was written by another version of the
+		// resolver.
+		data, err := os.ReadFile(Path(path))
+		if err != nil {
+			t.Fatal(err)
+		}
+		data[sha256.Size]++
+		if err := os.WriteFile(Path(path), data, 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		old := age(t, path)
+		load(t, path, source)
+
+		if got := modified(t, path); got.Equal(old) {
+			t.Errorf("cache of another resolver not rewritten")
+		}
+		fresh(t, path, source)
+	})
+}
+
+func TestCorrupt(t *testing.T) {
+	hash := sha256.Sum256([]byte(source))
+
+	tests := []struct {
+		name    string
+		corrupt func(data []byte) []byte
+	}{
+		{"empty", func(data []byte) []byte { return nil }},
+		{"truncated", func(data []byte) []byte { return data[:len(data)-3] }},
+		{"trailing", func(data []byte) []byte { return append(data, 0) }},
+		{"tree", func(data []byte) []byte {
+			// Keep the header, but replace the tree and bindings.
+			garbage := make([]byte, 64)
+			for idx := range garbage {
+				garbage[idx] = 0xff
+			}
+			return append(data[:len(hash)+1], garbage...)
+		}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := script(t, source)
+			load(t, path, source)
+
+			data, err := os.ReadFile(Path(path))
+			if err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(Path(path), tt.corrupt(data), 0o644); err != nil {
+				t.Fatal(err)
+			}
+
+			var b binder
+			if _, err := read(Path(path), hash, &b); err == nil {
+				t.Fatal("corrupt cache read")
+			}
+			if b.locals != 0 || b.tails != 0 {
+				t.Errorf("corrupt cache bound %d locals and %d tail calls", b.locals, b.tails)
+			}
+
+			load(t, path, source)
+			fresh(t, path, source)
+		})
+	}
+}
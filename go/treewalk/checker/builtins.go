@@ -0,0 +1,78 @@
+package checker
+
+// fn builds the type of a native taking exactly the given parameters.
+func fn(ret Type, params ...Type) *Fun {
+	return &Fun{Params: params, Min: len(params), Return: ret}
+}
+
+// builtins holds the types of the globals the interpreter defines before
+// running a script. It must be kept in step with the natives registered in
+// the interpreter package.
+var builtins = map[string]Type{
+	"clock": fn(Float),
+	"args":  List,
+
+	"len":        fn(Int, Any),
+	"substr":     fn(String, String, Number, Number),
+	"indexOf":    fn(Int, Any, Any),
+	"contains":   fn(Bool, Any, Any),
+	"split":      fn(List, String, String),
+	"join":       fn(String, List, String),
+	"upper":      fn(String, String),
+	"lower":      fn(String, String),
+	"trim":       fn(String, String),
+	"replace":    fn(String, String, String, String),
+	"startsWith": fn(Bool, String, String),
+	"endsWith":   fn(Bool, String, String),
+	"charAt":     fn(String, String, Number),
+	"ord":        fn(Int, String),
+	"chr":        fn(String, Number),
+	"str":        fn(String, Any),
+	"num":        fn(Number, String),
+
+	"pi":    Float,
+	"inf":   Float,
+	"nan":   Float,
+	"floor": fn(Number, Number),
+	"ceil":  fn(Number, Number),
+	"round": fn(Number, Number),
+	"sqrt":  fn(Float, Number),
+	"abs":   fn(Number, Number),
+	"sin":   fn(Float, Number),
+	"cos":   fn(Float, Number),
+	"log":   fn(Float, Number),
+	"exp":   fn(Float, Number),
+	"pow":   fn(Float, Number, Number),
+	"atan2": fn(Float, Number, Number),
+	"min":   &Fun{Params: []Type{Number}, Min: 1, Rest: Number, Return: Number},
+	"max":   &Fun{Params: []Type{Number}, Min: 1, Rest: Number, Return: Number},
+	"isNaN": fn(Bool, Number),
+
+	"random":    fn(Float),
+	"randomInt": fn(Int, Number, Number),
+	"shuffle":   fn(Nil, List),
+	"seed":      fn(Nil, Number),
+
+	"readFile":   fn(String, String),
+	"writeFile":  fn(Nil, String, String),
+	"appendFile": fn(Nil, String, String),
+	"exists":     fn(Bool, String),
+	"readLine":   fn(String),
+
+	"list":   &Fun{Rest: Any, Return: List},
+	"map":    fn(Map),
+	"push":   fn(Nil, List, Any),
+	"pop":    fn(Any, List),
+	"get":    fn(Any, Any, Any),
+	"set":    fn(Nil, Any, Any, Any),
+	"has":    fn(Bool, Any, Any),
+	"remove": fn(Any, Any, Any),
+	"keys":   fn(List, Map),
+
+	"jsonParse":     fn(Any, String),
+	"jsonStringify": &Fun{Params: []Type{Any, Any}, Min: 1, Return: String},
+
+	"assert":       &Fun{Params: []Type{Any, Any}, Min: 1, Return: Nil},
+	"assertEqual":  &Fun{Params: []Type{Any, Any, Any}, Min: 2, Return: Nil},
+	"assertThrows": &Fun{Params: []Type{anyFun, Any}, Min: 1, Return: Any},
+}
@@ -0,0 +1,648 @@
+package checker
+
+import (
+	"fmt"
+	"glox/generated"
+	"glox/lerr"
+	"glox/token"
+)
+
+// Checker infers the static type of every expression in a program and
+// reports operations that are certain to fail at runtime, such as
+// subtracting from a string or calling a function with the wrong number of
+// arguments. Annotated variables, parameters and return types are checked
+// against the values stored in them. Unannotated variables and parameters
+// are of type any, since Lox lets them hold values of any type over time;
+// functions have the type of their declaration.
+type Checker interface {
+	generated.VisitorStmt
+	generated.VisitorExpr
+	Check([]generated.Stmt) []error
+}
+
+// function holds what the checker knows about the function whose body it
+// is walking.
+type function struct {
+	declared Type
+	returns  []Type
+}
+
+// binding is what the checker knows about a variable. Only the type of an
+// annotated variable restricts what may be assigned to it.
+type binding struct {
+	typ       Type
+	annotated bool
+}
+
+type checker struct {
+	globals  map[string]binding
+	scopes   []map[string]binding
+	hoisted  map[*generated.FunctionStmt]*Fun
+	currFunc *function
+	errs     []error
+}
+
+func NewChecker() Checker {
+	globals := make(map[string]binding, len(builtins))
+	for name, t := range builtins {
+		globals[name] = binding{typ: t}
+	}
+
+	return &checker{
+		globals: globals,
+		scopes:  []map[string]binding{},
+		hoisted: map[*generated.FunctionStmt]*Fun{},
+	}
+}
+
+// Check walks stmts and returns every type error found. Top-level functions
+// are declared before anything else is checked, since global functions may
+// be called from functions declared before them.
+func (c *checker) Check(stmts []generated.Stmt) []error {
+	for _, stmt := range stmts {
+		if fs, ok := stmt.(*generated.FunctionStmt); ok {
+			sig := c.signature(fs)
+			c.hoisted[fs] = sig
+			c.globals[fs.Name.GetLexeme()] = binding{typ: sig}
+		}
+	}
+
+	c.checkStmts(stmts)
+
+	return c.errs
+}
+
+func (c *checker) errorf(t token.Token, format string, args ...interface{}) {
+	c.errs = append(c.errs, lerr.NewTypeErrAt(lerr.SpanOf(t), fmt.Sprintf(format, args...)))
+}
+
+func (c *checker) checkStmts(stmts []generated.Stmt) {
+	for _, stmt := range stmts {
+		stmt.Accept(c)
+	}
+}
+
+func (c *checker) checkBlock(stmts []generated.Stmt) {
+	c.beginScope()
+	c.checkStmts(stmts)
+	c.endScope()
+}
+
+func (c *checker) check(expr generated.Expr) Type {
+	t, _ := expr.Accept(c)
+	return t.(Type)
+}
+
+func (c *checker) beginScope() {
+	c.scopes = append(c.scopes, map[string]binding{})
+}
+
+func (c *checker) endScope() {
+	c.scopes = c.scopes[:len(c.scopes)-1]
+}
+
+// define defines the variable name, of type t. annotated says whether t
+// was declared by an annotation.
+func (c *checker) define(name token.Token, t Type, annotated bool) {
+	b := binding{typ: t, annotated: annotated}
+	if len(c.scopes) == 0 {
+		c.globals[name.GetLexeme()] = b
+		return
+	}
+
+	c.scopes[len(c.scopes)-1][name.GetLexeme()] = b
+}
+
+// lookup returns what is known about the variable name. Names the checker
+// has not seen, such as globals defined by an imported module, are of type
+// any.
+func (c *checker) lookup(name token.Token) binding {
+	for i := len(c.scopes) - 1; i >= 0; i-- {
+		if b, ok := c.scopes[i][name.GetLexeme()]; ok {
+			return b
+		}
+	}
+
+	if b, ok := c.globals[name.GetLexeme()]; ok {
+		return b
+	}
+
+	return binding{typ: Any}
+}
+
+// annotation returns the type named by an annotation, or nil if there is
+// none.
+func (c *checker) annotation(t token.Token) Type {
+	if t == nil {
+		return nil
+	}
+
+	typ, ok := typeNames[t.GetLexeme()]
+	if !ok {
+		c.errorf(t, "Unknown type '%s'.", t.GetLexeme())
+		return Any
+	}
+
+	return typ
+}
+
+// signature returns the type of a function as far as it is known from its
+// declaration alone. An unannotated return type is any until the body has
+// been checked.
+func (c *checker) signature(stmt *generated.FunctionStmt) *Fun {
+	sig := &Fun{Return: Any}
+	for idx, param := range stmt.Params {
+		t := c.annotation(stmt.ParamTypes[idx])
+		if t == nil {
+			t = Any
+		}
+
+		sig.Params = append(sig.Params, t)
+		sig.Names = append(sig.Names, param.GetLexeme())
+		if stmt.Defaults[idx] == nil {
+			sig.Min++
+		}
+	}
+
+	if stmt.Rest != nil {
+		sig.Rest = Any
+	}
+
+	if stmt.ReturnType != nil {
+		sig.Return = c.annotation(stmt.ReturnType)
+	}
+
+	return sig
+}
+
+func (c *checker) VisitBlockStmt(stmt *generated.BlockStmt) (interface{}, error) {
+	c.checkBlock(stmt.Statements)
+	return nil, nil
+}
+
+func (c *checker) VisitVarStmt(stmt *generated.VarStmt) (interface{}, error) {
+	declared := c.annotation(stmt.Type)
+
+	var init Type
+	if stmt.Initializer != nil {
+		init = c.check(stmt.Initializer)
+	}
+
+	if declared == nil {
+		c.define(stmt.Name, Any, false)
+		return nil, nil
+	}
+
+	if init != nil && !assignable(declared, init) {
+		c.errorf(stmt.Name, "Cannot assign %s to variable '%s' of type %s.", init, stmt.Name.GetLexeme(), declared)
+	}
+
+	c.define(stmt.Name, declared, true)
+
+	return nil, nil
+}
+
+func (c *checker) VisitFunctionStmt(stmt *generated.FunctionStmt) (interface{}, error) {
+	sig, ok := c.hoisted[stmt]
+	if !ok || len(c.scopes) != 0 {
+		sig = c.signature(stmt)
+	}
+
+	c.define(stmt.Name, sig, false)
+
+	ret := c.checkFunction(stmt, sig)
+	if stmt.ReturnType == nil {
+		sig.Return = ret
+	}
+
+	return nil, nil
+}
+
+// checkFunction checks the body of a function and returns the type of the
+// values it returns.
+func (c *checker) checkFunction(stmt *generated.FunctionStmt, sig *Fun) Type {
+	enclosing := c.currFunc
+	c.currFunc = &function{}
+	if stmt.ReturnType != nil {
+		c.currFunc.declared = sig.Return
+	}
+
+	c.beginScope()
+	for idx, param := range stmt.Params {
+		if stmt.Defaults[idx] != nil {
+			def := c.check(stmt.Defaults[idx])
+			if !assignable(sig.Params[idx], def) {
+				c.errorf(param, "Default of type %s for parameter '%s' of type %s.", def, param.GetLexeme(), sig.Params[idx])
+			}
+		}
+
+		c.define(param, sig.Params[idx], stmt.ParamTypes[idx] != nil)
+	}
+
+	if stmt.Rest != nil {
+		c.define(stmt.Rest, Any, false)
+	}
+
+	c.checkStmts(stmt.Body)
+	c.endScope()
+
+	ret := Type(Nil)
+	for _, t := range c.currFunc.returns {
+		if ret == Nil {
+			ret = t
+		} else if t != Nil {
+			ret = join(ret, t)
+		}
+	}
+
+	c.currFunc = enclosing
+
+	return ret
+}
+
+func (c *checker) VisitExprStmt(stmt *generated.ExprStmt) (interface{}, error) {
+	c.check(stmt.Expr)
+	return nil, nil
+}
+
+func (c *checker) VisitPrintStmt(stmt *generated.PrintStmt) (interface{}, error) {
+	c.check(stmt.Expr)
+	return nil, nil
+}
+
+func (c *checker) VisitIfStmt(stmt *generated.IfStmt) (interface{}, error) {
+	c.check(stmt.Condition)
+	stmt.IfBranch.Accept(c)
+
+	if stmt.ElseBranch != nil {
+		stmt.ElseBranch.Accept(c)
+	}
+
+	return nil, nil
+}
+
+func (c *checker) VisitWhileStmt(stmt *generated.WhileStmt) (interface{}, error) {
+	c.check(stmt.Condition)
+	stmt.Stmt.Accept(c)
+
+	return nil, nil
+}
+
+func (c *checker) VisitReturnStmt(stmt *generated.ReturnStmt) (interface{}, error) {
+	t := Type(Nil)
+	if stmt.Value != nil {
+		t = c.check(stmt.Value)
+	}
+
+	// Returning from top-level code is reported by the resolver.
+	if c.currFunc == nil {
+		return nil, nil
+	}
+
+	if c.currFunc.declared != nil && !assignable(c.currFunc.declared, t) {
+		c.errorf(stmt.Keyword, "Cannot return %s from function declared to return %s.", t, c.currFunc.declared)
+	}
+
+	c.currFunc.returns = append(c.currFunc.returns, t)
+
+	return nil, nil
+}
+
+func (c *checker) VisitImportStmt(stmt *generated.ImportStmt) (interface{}, error) {
+	if stmt.Alias != nil {
+		c.define(stmt.Alias, Module, false)
+		return nil, nil
+	}
+
+	for _, name := range stmt.Names {
+		c.define(name, Any, false)
+	}
+
+	return nil, nil
+}
+
+func (c *checker) VisitThrowStmt(stmt *generated.ThrowStmt) (interface{}, error) {
+	c.check(stmt.Value)
+	return nil, nil
+}
+
+func (c *checker) VisitTryStmt(stmt *generated.TryStmt) (interface{}, error) {
+	c.checkBlock(stmt.Body)
+
+	if stmt.CatchName != nil {
+		c.beginScope()
+		c.define(stmt.CatchName, Any, false)
+		c.checkBlock(stmt.CatchBody)
+		c.endScope()
+	}
+
+	if stmt.FinallyBody != nil {
+		c.checkBlock(stmt.FinallyBody)
+	}
+
+	return nil, nil
+}
+
+func (c *checker) VisitTestStmt(stmt *generated.TestStmt) (interface{}, error) {
+	c.checkBlock(stmt.Body)
+	return nil, nil
+}
+
+func (c *checker) VisitMatchStmt(stmt *generated.MatchStmt) (interface{}, error) {
+	c.checkMatch(stmt.Subject, stmt.Cases)
+	return nil, nil
+}
+
+func (c *checker) VisitMatchExpr(expr *generated.MatchExpr) (interface{}, error) {
+	return c.checkMatch(expr.Subject, expr.Cases), nil
+}
+
+// checkMatch checks each arm in a scope of its own, mirroring the resolver,
+// and returns the type of the arm values of a match expression.
+func (c *checker) checkMatch(subject generated.Expr, cases []*generated.MatchCase) Type {
+	c.check(subject)
+
+	var result Type
+	for _, mc := range cases {
+		c.beginScope()
+
+		if mc.Binding != nil {
+			c.define(mc.Binding, Any, false)
+		}
+
+		for _, pattern := range mc.Patterns {
+			c.check(pattern)
+		}
+
+		if mc.Guard != nil {
+			c.check(mc.Guard)
+		}
+
+		if mc.Body != nil {
+			mc.Body.Accept(c)
+		} else {
+			t := c.check(mc.Value)
+			if result == nil {
+				result = t
+			} else {
+				result = join(result, t)
+			}
+		}
+
+		c.endScope()
+	}
+
+	if result == nil {
+		return Nil
+	}
+
+	return result
+}
+
+func (c *checker) VisitAssign(expr *generated.Assign) (interface{}, error) {
+	value := c.check(expr.Value)
+
+	target := c.lookup(expr.Name)
+	if target.annotated && !assignable(target.typ, value) {
+		c.errorf(expr.Name, "Cannot assign %s to variable '%s' of type %s.", value, expr.Name.GetLexeme(), target.typ)
+	}
+
+	return value, nil
+}
+
+func (c *checker) VisitLogical(expr *generated.Logical) (interface{}, error) {
+	return join(c.check(expr.Left), c.check(expr.Right)), nil
+}
+
+func (c *checker) VisitBinary(expr *generated.Binary) (interface{}, error) {
+	left := c.check(expr.Left)
+	right := c.check(expr.Right)
+	op := expr.Operator
+
+	switch op.GetType() {
+	case token.EQUAL_EQUAL, token.BANG_EQUAL:
+		return Bool, nil
+	case token.PLUS:
+		switch {
+		case left == String && (right == String || right == Any),
+			right == String && left == Any:
+			return String, nil
+		case numeric(left) && numeric(right):
+			return arithmetic(left, right), nil
+		}
+
+		c.errorf(op, "Operands of '+' must be two numbers or two strings, got %s and %s.", left, right)
+		return Any, nil
+	case token.GREATER, token.GREATER_EQUAL, token.LESS, token.LESS_EQUAL:
+		c.numberOperands(op, left, right)
+		return Bool, nil
+	case token.SLASH:
+		c.numberOperands(op, left, right)
+		return Float, nil
+	case token.AMPERSAND, token.PIPE, token.CARET, token.LESS_LESS, token.GREATER_GREATER:
+		if !integer(left) || !integer(right) {
+			c.errorf(op, "Operands of '%s' must be integers, got %s and %s.", op.GetLexeme(), left, right)
+		}
+		return Int, nil
+	}
+
+	if !c.numberOperands(op, left, right) {
+		return Any, nil
+	}
+
+	return arithmetic(left, right), nil
+}
+
+func (c *checker) numberOperands(op token.Token, left, right Type) bool {
+	if numeric(left) && numeric(right) {
+		return true
+	}
+
+	c.errorf(op, "Operands of '%s' must be numbers, got %s and %s.", op.GetLexeme(), left, right)
+
+	return false
+}
+
+// numeric reports whether a value of type t may be a number.
+func numeric(t Type) bool {
+	return t == Any || isNumeric(t)
+}
+
+// integer reports whether a value of type t may be an integer.
+func integer(t Type) bool {
+	return numeric(t) && t != Float
+}
+
+// arithmetic returns the type of an arithmetic operation on two numbers:
+// integers stay integers, and a float operand makes the result a float.
+func arithmetic(left, right Type) Type {
+	switch {
+	case left == Int && right == Int:
+		return Int
+	case left == Float || right == Float:
+		return Float
+	}
+
+	return Number
+}
+
+func (c *checker) VisitTernary(expr *generated.Ternary) (interface{}, error) {
+	c.check(expr.Condition)
+	return join(c.check(expr.ValueTrue), c.check(expr.ValueFalse)), nil
+}
+
+func (c *checker) VisitGrouping(expr *generated.Grouping) (interface{}, error) {
+	return c.check(expr.Expression), nil
+}
+
+func (c *checker) VisitLiteral(expr *generated.Literal) (interface{}, error) {
+	switch expr.Value.(type) {
+	case bool:
+		return Bool, nil
+	case int64:
+		return Int, nil
+	case float64:
+		return Float, nil
+	case string:
+		return String, nil
+	}
+
+	return Nil, nil
+}
+
+func (c *checker) VisitUnary(expr *generated.Unary) (interface{}, error) {
+	right := c.check(expr.Right)
+	op := expr.Operator
+
+	switch op.GetType() {
+	case token.MINUS:
+		if !numeric(right) {
+			c.errorf(op, "Operand of '-' must be a number, got %s.", right)
+			return Any, nil
+		}
+		if right == Any {
+			return Number, nil
+		}
+		return right, nil
+	case token.TILDE:
+		if !integer(right) {
+			c.errorf(op, "Operand of '~' must be an integer, got %s.", right)
+		}
+		return Int, nil
+	}
+
+	return Bool, nil
+}
+
+func (c *checker) VisitCall(expr *generated.Call) (interface{}, error) {
+	callee := c.check(expr.Callee)
+
+	args := make([]Type, len(expr.Arguments))
+	for idx, arg := range expr.Arguments {
+		args[idx] = c.check(arg)
+	}
+
+	if callee == Any {
+		return Any, nil
+	}
+
+	f, ok := callee.(*Fun)
+	if !ok {
+		c.errorf(expr.Paren, "Can only call functions, not %s.", callee)
+		return Any, nil
+	}
+
+	if f != anyFun {
+		c.checkArguments(expr, f, args)
+	}
+
+	return f.Return, nil
+}
+
+// checkArguments checks the number and types of the arguments of a call
+// to f, binding named arguments the way the interpreter does.
+func (c *checker) checkArguments(expr *generated.Call, f *Fun, args []Type) {
+	positional := 0
+	for idx, name := range expr.Names {
+		if name != nil {
+			continue
+		}
+
+		if want := f.param(positional); want != nil && !assignable(want, args[idx]) {
+			c.errorf(expr.Paren, "Cannot pass %s as argument %d, which expects %s.", args[idx], positional+1, want)
+		}
+		positional++
+	}
+
+	max := len(f.Params)
+	if f.Rest != nil {
+		max = -1
+	}
+
+	bound := map[string]bool{}
+	for idx, name := range expr.Names {
+		if name == nil {
+			continue
+		}
+
+		if f.Names == nil {
+			c.errorf(name, "Named arguments can only be passed to Lox functions.")
+			return
+		}
+
+		pidx := -1
+		for i, n := range f.Names {
+			if n == name.GetLexeme() {
+				pidx = i
+			}
+		}
+
+		switch {
+		case pidx < 0:
+			c.errorf(name, "Function has no parameter '%s'.", name.GetLexeme())
+		case bound[name.GetLexeme()] || pidx < positional:
+			c.errorf(name, "Argument '%s' given more than once.", name.GetLexeme())
+		case !assignable(f.Params[pidx], args[idx]):
+			c.errorf(name, "Cannot pass %s as argument '%s', which expects %s.", args[idx], name.GetLexeme(), f.Params[pidx])
+		}
+
+		bound[name.GetLexeme()] = true
+	}
+
+	if len(bound) == 0 {
+		switch {
+		case positional >= f.Min && (max < 0 || positional <= max):
+		case f.Min == max:
+			c.errorf(expr.Paren, "Expected %d arguments but got %d.", f.Min, positional)
+		case positional < f.Min:
+			c.errorf(expr.Paren, "Expected at least %d arguments but got %d.", f.Min, positional)
+		default:
+			c.errorf(expr.Paren, "Expected at most %d arguments but got %d.", max, positional)
+		}
+
+		return
+	}
+
+	if max >= 0 && positional > max {
+		c.errorf(expr.Paren, "Expected at most %d arguments but got %d.", max, positional)
+	}
+
+	for idx := positional; idx < f.Min; idx++ {
+		if !bound[f.Names[idx]] {
+			c.errorf(expr.Paren, "Missing argument '%s'.", f.Names[idx])
+		}
+	}
+}
+
+func (c *checker) VisitVarExpr(expr *generated.VarExpr) (interface{}, error) {
+	return c.lookup(expr.Name).typ, nil
+}
+
+func (c *checker) VisitGet(expr *generated.Get) (interface{}, error) {
+	object := c.check(expr.Object)
+	if object != Any && object != Module && object != Map {
+		c.errorf(expr.Name, "Only modules and maps have properties, not %s.", object)
+	}
+
+	return Any, nil
+}
@@ -0,0 +1,78 @@
+package checker
+
+import (
+	"glox/lerr"
+	"glox/parser"
+	"glox/scanner"
+	"reflect"
+	"testing"
+)
+
+func TestCheck(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		// Annotated variables.
+		{"annotated", `var a: int = 1; var b: number = 1.5; var c: string = "s"; var d: any = nil;`, nil},
+		{"annotated mismatch", `var a: int = "s";`, []string{"Cannot assign string to variable 'a' of type int."}},
+		{"annotated int from float", `var a: int = 1.5;`, []string{"Cannot assign float to variable 'a' of type int."}},
+		{"annotated assignment", `var a: string = "s"; a = "t"; a = 1;`, []string{"Cannot assign int to variable 'a' of type string."}},
+		{"annotated without initializer", `var a: int; a = 2;`, nil},
+		{"unknown type", `var a: text = "s";`, []string{"Unknown type 'text'."}},
+
+		// Unannotated variables hold anything.
+		{"unannotated reassigned", `var d = 1; d = "str"; print d + "x";`, nil},
+		{"unannotated local", `{ var d = list(); d = 2; print d - 1; }`, nil},
+		{"unannotated without initializer", `var d; d = 1; d = "s";`, nil},
+		{"literal operands", `print "a" - 1;`, []string{"Operands of '-' must be numbers, got string and int."}},
+
+		// Parameters and defaults.
+		{"annotated parameter", `fun f(a: int) { a = "s"; } fun g(b) { b = "s"; }`, []string{"Cannot assign string to variable 'a' of type int."}},
+		{"default", `fun f(a: int = 1, b: string = "s") {} f(); f(2, "t");`, nil},
+		{"default mismatch", `fun f(a: int = "s") {}`, []string{"Default of type string for parameter 'a' of type int."}},
+		{"argument mismatch", `fun f(a: int) {} f("s");`, []string{"Cannot pass string as argument 1, which expects int."}},
+		{"arity", `fun f(a, b = 1) {} f(); f(1, 2, 3);`, []string{"Expected at least 1 arguments but got 0.", "Expected at most 2 arguments but got 3."}},
+		{"rest", `fun f(a, ...rest) {} f(1, 2, 3, 4);`, nil},
+
+		// Named arguments.
+		{"named", `fun f(a, b: string = "s") {} f(1, b: "t"); f(b: "t", a: 1);`, nil},
+		{"named mismatch", `fun f(a, b: string = "s") {} f(1, b: 2);`, []string{"Cannot pass int as argument 'b', which expects string."}},
+		{"named unknown", `fun f(a) {} f(c: 1);`, []string{"Function has no parameter 'c'.", "Missing argument 'a'."}},
+		{"named twice", `fun f(a, b) {} f(1, a: 2);`, []string{"Argument 'a' given more than once.", "Missing argument 'b'."}},
+		{"named to a native", `len(s: "s");`, []string{"Named arguments can only be passed to Lox functions."}},
+
+		// Returns.
+		{"return", `fun f(): int { return 1; } fun g(): string { return nil; } var a: int = f();`, nil},
+		{"return mismatch", `fun f(): int { return "s"; }`, []string{"Cannot return string from function declared to return int."}},
+		{"inferred return", `fun f() { return "s"; } var a: int = f();`, []string{"Cannot assign string to variable 'a' of type int."}},
+		{"call before declaration", `fun f() { return g() - 1; } fun g() { return 1; }`, nil},
+
+		// Calls.
+		{"not callable", `var a: int = 1; a();`, []string{"Can only call functions, not int."}},
+		{"unannotated call", `var f = 1; f = clock; f();`, nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := scanner.NewScanner(tt.source).ScanTokens()
+			if err != nil {
+				t.Fatal(err)
+			}
+			stmts, err := parser.NewParser(tokens).Parse()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, err := range NewChecker().Check(stmts) {
+				got = append(got, lerr.Diagnose(err).Message)
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
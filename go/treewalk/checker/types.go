@@ -0,0 +1,123 @@
+package checker
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Type is the static type of an expression as inferred by the checker.
+type Type interface {
+	String() string
+}
+
+type basic string
+
+func (b basic) String() string {
+	return string(b)
+}
+
+const (
+	Any    basic = "any"
+	Nil    basic = "nil"
+	Bool   basic = "bool"
+	Number basic = "number"
+	Int    basic = "int"
+	Float  basic = "float"
+	String basic = "string"
+	List   basic = "list"
+	Map    basic = "map"
+	Module basic = "module"
+)
+
+// Fun is the type of a function. Min is the number of required parameters
+// and Rest, if set, is the type of each argument collected by a rest
+// parameter. Names holds the parameter names of Lox functions, which may be
+// passed by name; it is nil for natives.
+type Fun struct {
+	Params []Type
+	Names  []string
+	Min    int
+	Rest   Type
+	Return Type
+}
+
+func (f *Fun) String() string {
+	params := make([]string, 0, len(f.Params))
+	for _, p := range f.Params {
+		params = append(params, p.String())
+	}
+
+	if f.Rest != nil {
+		params = append(params, "..."+f.Rest.String())
+	}
+
+	return fmt.Sprintf("fun(%s): %s", strings.Join(params, ", "), f.Return)
+}
+
+// param returns the type expected for the argument at idx, or nil if the
+// function takes fewer arguments.
+func (f *Fun) param(idx int) Type {
+	if idx < len(f.Params) {
+		return f.Params[idx]
+	}
+
+	return f.Rest
+}
+
+// anyFun is the type written as `fun` in annotations: some function whose
+// signature is not known.
+var anyFun = &Fun{Rest: Any, Return: Any}
+
+// typeNames maps the names usable in annotations to their types.
+var typeNames = map[string]Type{
+	"any":    Any,
+	"nil":    Nil,
+	"bool":   Bool,
+	"number": Number,
+	"int":    Int,
+	"float":  Float,
+	"string": String,
+	"list":   List,
+	"map":    Map,
+	"module": Module,
+	"fun":    anyFun,
+}
+
+func isNumeric(t Type) bool {
+	return t == Number || t == Int || t == Float
+}
+
+// assignable reports whether a value of type src may be stored where dst
+// is expected. any is compatible with everything in both directions, nil
+// may be stored anywhere, and an int may be used where a float is
+// expected. A number of unknown kind is accepted where an int or float is
+// expected, since only definite mismatches are reported.
+func assignable(dst, src Type) bool {
+	if dst == Any || src == Any || src == Nil || dst == src {
+		return true
+	}
+
+	if isNumeric(dst) && isNumeric(src) {
+		return !(dst == Int && src == Float)
+	}
+
+	if _, ok := dst.(*Fun); ok {
+		_, ok := src.(*Fun)
+		return ok
+	}
+
+	return false
+}
+
+// join returns the type of an expression that evaluates to either a or b.
+func join(a, b Type) Type {
+	if a == b {
+		return a
+	}
+
+	if isNumeric(a) && isNumeric(b) {
+		return Number
+	}
+
+	return Any
+}
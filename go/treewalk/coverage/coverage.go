@@ -0,0 +1,169 @@
+package coverage
+
+import (
+	"glox/generated"
+	"sort"
+)
+
+// statement is a statement that can run, found when its module is loaded.
+type statement struct {
+	line int
+	hits int64
+}
+
+type function struct {
+	name string
+	line int
+	hits int64
+}
+
+// branchPoint is an IfStmt, Logical or Ternary, which always has two
+// branches.
+type branchPoint struct {
+	line  int
+	block int
+	hits  [2]int64
+	// reached is false until either branch is taken.
+	reached bool
+}
+
+// file is the coverage of one module.
+type file struct {
+	path      string
+	stmts     []*statement
+	functions []*function
+	branches  []*branchPoint
+}
+
+// Collector records the statements, functions and branches that run. It
+// implements interpreter.Coverage, and may be shared by the interpreters
+// running several scripts so that their coverage adds up.
+type Collector struct {
+	files []*file
+	paths map[string]*file
+
+	stmts     map[generated.Stmt]*statement
+	functions map[*generated.FunctionStmt]*function
+	branches  map[interface{}]*branchPoint
+}
+
+func NewCollector() *Collector {
+	return &Collector{
+		paths:     map[string]*file{},
+		stmts:     map[generated.Stmt]*statement{},
+		functions: map[*generated.FunctionStmt]*function{},
+		branches:  map[interface{}]*branchPoint{},
+	}
+}
+
+// Load records what can run in a module. Scripts not run from a file are
+// not covered.
+func (c *Collector) Load(path string, stmts []generated.Stmt) {
+	if path == "" {
+		return
+	}
+
+	f, ok := c.paths[path]
+	if !ok {
+		f = &file{path: path}
+		c.paths[path] = f
+		c.files = append(c.files, f)
+	}
+
+	w := &walker{c: c, f: f}
+	w.stmts(stmts)
+}
+
+func (c *Collector) Statement(stmt generated.Stmt) {
+	if s, ok := c.stmts[stmt]; ok {
+		s.hits++
+	}
+}
+
+func (c *Collector) Call(decl *generated.FunctionStmt) {
+	if f, ok := c.functions[decl]; ok {
+		f.hits++
+	}
+}
+
+func (c *Collector) Branch(node interface{}, branch int) {
+	if b, ok := c.branches[node]; ok {
+		b.reached = true
+		b.hits[branch]++
+	}
+}
+
+// lineHits returns how often each executable line of f ran: as often as
+// the statement on it that ran most.
+func (f *file) lineHits() (lines []int, hits map[int]int64) {
+	hits = map[int]int64{}
+	for _, s := range f.stmts {
+		h, seen := hits[s.line]
+		if !seen {
+			lines = append(lines, s.line)
+		}
+		if !seen || s.hits > h {
+			hits[s.line] = s.hits
+		}
+	}
+
+	sort.Ints(lines)
+
+	return lines, hits
+}
+
+// Totals counts what can run and what ran.
+type Totals struct {
+	Lines, LinesHit         int
+	Functions, FunctionsHit int
+	Branches, BranchesHit   int
+}
+
+func (f *file) totals() Totals {
+	var t Totals
+
+	lines, hits := f.lineHits()
+	t.Lines = len(lines)
+	for _, line := range lines {
+		if hits[line] > 0 {
+			t.LinesHit++
+		}
+	}
+
+	t.Functions = len(f.functions)
+	for _, fn := range f.functions {
+		if fn.hits > 0 {
+			t.FunctionsHit++
+		}
+	}
+
+	t.Branches = 2 * len(f.branches)
+	for _, b := range f.branches {
+		for _, h := range b.hits {
+			if h > 0 {
+				t.BranchesHit++
+			}
+		}
+	}
+
+	return t
+}
+
+func (t *Totals) add(o Totals) {
+	t.Lines += o.Lines
+	t.LinesHit += o.LinesHit
+	t.Functions += o.Functions
+	t.FunctionsHit += o.FunctionsHit
+	t.Branches += o.Branches
+	t.BranchesHit += o.BranchesHit
+}
+
+// Totals returns the counts over every file.
+func (c *Collector) Totals() Totals {
+	var t Totals
+	for _, f := range c.files {
+		t.add(f.totals())
+	}
+
+	return t
+}
@@ -0,0 +1,134 @@
+package coverage
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// WriteLCOV writes the coverage in the LCOV tracefile format read by
+// genhtml and most coverage services.
+func (c *Collector) WriteLCOV(w io.Writer) error {
+	for _, f := range c.files {
+		var b strings.Builder
+
+		b.WriteString("TN:\n")
+		fmt.Fprintf(&b, "SF:%s\n", f.path)
+
+		for _, fn := range f.functions {
+			fmt.Fprintf(&b, "FN:%d,%s\n", fn.line, fn.name)
+		}
+		for _, fn := range f.functions {
+			fmt.Fprintf(&b, "FNDA:%d,%s\n", fn.hits, fn.name)
+		}
+
+		t := f.totals()
+		fmt.Fprintf(&b, "FNF:%d\nFNH:%d\n", t.Functions, t.FunctionsHit)
+
+		for _, br := range f.branches {
+			for idx, h := range br.hits {
+				taken := "-"
+				if br.reached {
+					taken = strconv.FormatInt(h, 10)
+				}
+				fmt.Fprintf(&b, "BRDA:%d,%d,%d,%s\n", br.line, br.block, idx, taken)
+			}
+		}
+		fmt.Fprintf(&b, "BRF:%d\nBRH:%d\n", t.Branches, t.BranchesHit)
+
+		lines, hits := f.lineHits()
+		for _, line := range lines {
+			fmt.Fprintf(&b, "DA:%d,%d\n", line, hits[line])
+		}
+		fmt.Fprintf(&b, "LF:%d\nLH:%d\n", t.Lines, t.LinesHit)
+
+		b.WriteString("end_of_record\n")
+
+		if _, err := io.WriteString(w, b.String()); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+const (
+	colorRed    = "\x1b[31m"
+	colorYellow = "\x1b[33m"
+	colorGreen  = "\x1b[32m"
+	colorReset  = "\x1b[0m"
+)
+
+// Summary writes a table of the line, branch and function coverage of
+// every file, with the lines that never ran. Percentages are colored by
+// how good they are when color is set.
+func (c *Collector) Summary(w io.Writer, color bool) {
+	pct := func(hit, total int) string {
+		if total == 0 {
+			return fmt.Sprintf("%7s", "-")
+		}
+
+		p := 100 * float64(hit) / float64(total)
+		s := fmt.Sprintf("%6.1f%%", p)
+		if !color {
+			return s
+		}
+
+		switch {
+		case p >= 80:
+			return colorGreen + s + colorReset
+		case p >= 50:
+			return colorYellow + s + colorReset
+		}
+		return colorRed + s + colorReset
+	}
+
+	wd, _ := os.Getwd()
+	name := func(path string) string {
+		if rel, err := filepath.Rel(wd, path); err == nil && !strings.HasPrefix(rel, "..") {
+			return rel
+		}
+		return path
+	}
+
+	fmt.Fprintf(w, "%-30s %7s %9s %9s  %s\n", "File", "Lines", "Branches", "Functions", "Missed lines")
+	for _, f := range c.files {
+		t := f.totals()
+		fmt.Fprintf(w, "%-30s %s   %s   %s  %s\n", name(f.path),
+			pct(t.LinesHit, t.Lines), pct(t.BranchesHit, t.Branches), pct(t.FunctionsHit, t.Functions), missed(f))
+	}
+
+	t := c.Totals()
+	fmt.Fprintf(w, "%-30s %s   %s   %s\n", "Total",
+		pct(t.LinesHit, t.Lines), pct(t.BranchesHit, t.Branches), pct(t.FunctionsHit, t.Functions))
+}
+
+// missed lists the lines of f that never ran, collapsing runs of
+// consecutive executable lines into ranges.
+func missed(f *file) string {
+	lines, hits := f.lineHits()
+
+	var ranges []string
+	for idx := 0; idx < len(lines); idx++ {
+		if hits[lines[idx]] > 0 {
+			continue
+		}
+
+		end := idx
+		for end+1 < len(lines) && hits[lines[end+1]] == 0 {
+			end++
+		}
+
+		if end == idx {
+			ranges = append(ranges, strconv.Itoa(lines[idx]))
+		} else {
+			ranges = append(ranges, fmt.Sprintf("%d-%d", lines[idx], lines[end]))
+		}
+		idx = end
+	}
+
+	return strings.Join(ranges, ", ")
+}
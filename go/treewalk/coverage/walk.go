@@ -0,0 +1,211 @@
+package coverage
+
+import "glox/generated"
+
+var _ generated.VisitorStmt = (*walker)(nil)
+var _ generated.VisitorExpr = (*walker)(nil)
+
+// walker finds the statements, functions and branches of a module. When
+// a module is loaded again, by another interpreter, the nodes of the new
+// parse are matched with the records of the first in the order they are
+// found, so that their counts add up.
+type walker struct {
+	c *Collector
+	f *file
+
+	nstmts, nfunctions, nbranches int
+}
+
+func (w *walker) stmts(stmts []generated.Stmt) {
+	for _, stmt := range stmts {
+		w.stmt(stmt)
+	}
+}
+
+// stmt records a statement that can run. Blocks only group statements,
+// and a statement without a line cannot be reported.
+func (w *walker) stmt(stmt generated.Stmt) {
+	if stmt == nil {
+		return
+	}
+
+	if _, ok := stmt.(*generated.BlockStmt); !ok {
+		if line := generated.StmtLine(stmt); line > 0 {
+			if w.nstmts == len(w.f.stmts) {
+				w.f.stmts = append(w.f.stmts, &statement{line: line})
+			}
+			w.c.stmts[stmt] = w.f.stmts[w.nstmts]
+			w.nstmts++
+		}
+	}
+
+	stmt.Accept(w)
+}
+
+func (w *walker) expr(expr generated.Expr) {
+	if expr != nil {
+		expr.Accept(w)
+	}
+}
+
+func (w *walker) branch(node interface{}, line int) {
+	if w.nbranches == len(w.f.branches) {
+		w.f.branches = append(w.f.branches, &branchPoint{line: line, block: w.nbranches})
+	}
+	w.c.branches[node] = w.f.branches[w.nbranches]
+	w.nbranches++
+}
+
+func (w *walker) cases(cases []*generated.MatchCase) {
+	for _, c := range cases {
+		for _, p := range c.Patterns {
+			w.expr(p)
+		}
+		w.expr(c.Guard)
+		w.stmt(c.Body)
+		w.expr(c.Value)
+	}
+}
+
+func (w *walker) VisitBlockStmt(stmt *generated.BlockStmt) (interface{}, error) {
+	w.stmts(stmt.Statements)
+	return nil, nil
+}
+
+func (w *walker) VisitIfStmt(stmt *generated.IfStmt) (interface{}, error) {
+	w.branch(stmt, stmt.Keyword.GetLine())
+	w.expr(stmt.Condition)
+	w.stmt(stmt.IfBranch)
+	w.stmt(stmt.ElseBranch)
+	return nil, nil
+}
+
+func (w *walker) VisitWhileStmt(stmt *generated.WhileStmt) (interface{}, error) {
+	w.expr(stmt.Condition)
+	w.stmt(stmt.Stmt)
+	return nil, nil
+}
+
+func (w *walker) VisitExprStmt(stmt *generated.ExprStmt) (interface{}, error) {
+	w.expr(stmt.Expr)
+	return nil, nil
+}
+
+func (w *walker) VisitPrintStmt(stmt *generated.PrintStmt) (interface{}, error) {
+	w.expr(stmt.Expr)
+	return nil, nil
+}
+
+func (w *walker) VisitVarStmt(stmt *generated.VarStmt) (interface{}, error) {
+	w.expr(stmt.Initializer)
+	return nil, nil
+}
+
+func (w *walker) VisitFunctionStmt(stmt *generated.FunctionStmt) (interface{}, error) {
+	if w.nfunctions == len(w.f.functions) {
+		w.f.functions = append(w.f.functions, &function{name: stmt.Name.GetLexeme(), line: stmt.Name.GetLine()})
+	}
+	w.c.functions[stmt] = w.f.functions[w.nfunctions]
+	w.nfunctions++
+
+	for _, def := range stmt.Defaults {
+		w.expr(def)
+	}
+	w.stmts(stmt.Body)
+	return nil, nil
+}
+
+func (w *walker) VisitReturnStmt(stmt *generated.ReturnStmt) (interface{}, error) {
+	w.expr(stmt.Value)
+	return nil, nil
+}
+
+func (w *walker) VisitImportStmt(stmt *generated.ImportStmt) (interface{}, error) {
+	return nil, nil
+}
+
+func (w *walker) VisitThrowStmt(stmt *generated.ThrowStmt) (interface{}, error) {
+	w.expr(stmt.Value)
+	return nil, nil
+}
+
+func (w *walker) VisitTryStmt(stmt *generated.TryStmt) (interface{}, error) {
+	w.stmts(stmt.Body)
+	w.stmts(stmt.CatchBody)
+	w.stmts(stmt.FinallyBody)
+	return nil, nil
+}
+
+func (w *walker) VisitMatchStmt(stmt *generated.MatchStmt) (interface{}, error) {
+	w.expr(stmt.Subject)
+	w.cases(stmt.Cases)
+	return nil, nil
+}
+
+func (w *walker) VisitTestStmt(stmt *generated.TestStmt) (interface{}, error) {
+	w.stmts(stmt.Body)
+	return nil, nil
+}
+
+func (w *walker) VisitAssign(expr *generated.Assign) (interface{}, error) {
+	w.expr(expr.Value)
+	return nil, nil
+}
+
+func (w *walker) VisitLogical(expr *generated.Logical) (interface{}, error) {
+	w.branch(expr, expr.Operator.GetLine())
+	w.expr(expr.Left)
+	w.expr(expr.Right)
+	return nil, nil
+}
+
+func (w *walker) VisitBinary(expr *generated.Binary) (interface{}, error) {
+	w.expr(expr.Left)
+	w.expr(expr.Right)
+	return nil, nil
+}
+
+func (w *walker) VisitTernary(expr *generated.Ternary) (interface{}, error) {
+	w.branch(expr, expr.Question.GetLine())
+	w.expr(expr.Condition)
+	w.expr(expr.ValueTrue)
+	w.expr(expr.ValueFalse)
+	return nil, nil
+}
+
+func (w *walker) VisitGrouping(expr *generated.Grouping) (interface{}, error) {
+	w.expr(expr.Expression)
+	return nil, nil
+}
+
+func (w *walker) VisitLiteral(expr *generated.Literal) (interface{}, error) {
+	return nil, nil
+}
+
+func (w *walker) VisitUnary(expr *generated.Unary) (interface{}, error) {
+	w.expr(expr.Right)
+	return nil, nil
+}
+
+func (w *walker) VisitCall(expr *generated.Call) (interface{}, error) {
+	w.expr(expr.Callee)
+	for _, arg := range expr.Arguments {
+		w.expr(arg)
+	}
+	return nil, nil
+}
+
+func (w *walker) VisitVarExpr(expr *generated.VarExpr) (interface{}, error) {
+	return nil, nil
+}
+
+func (w *walker) VisitGet(expr *generated.Get) (interface{}, error) {
+	w.expr(expr.Object)
+	return nil, nil
+}
+
+func (w *walker) VisitMatchExpr(expr *generated.MatchExpr) (interface{}, error) {
+	w.expr(expr.Subject)
+	w.cases(expr.Cases)
+	return nil, nil
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"glox/debugger"
+	"glox/interpreter"
+	"io"
+	"os"
+)
+
+// debugCmd implements `glox debug [--dap] script [args...]`. By default it
+// runs script under an interactive command line debugger; with --dap it
+// acts as a debug adapter on stdin and stdout instead, and the script may
+// also be named by the client's launch request.
+func debugCmd(args []string) int {
+	fs := flag.NewFlagSet("debug", flag.ExitOnError)
+	dap := fs.Bool("dap", false, "speak the Debug Adapter Protocol on stdin and stdout")
+	fs.Parse(args)
+
+	if *dap {
+		// The protocol owns stdout, so what the script prints is sent to
+		// the client as output events.
+		adapter := debugger.NewDAP(os.Stdin, os.Stdout, fs.Arg(0), func(program string, d interpreter.Debugger, stdout io.Writer) {
+			_, err := debugFile(program, fs.Args(), d, interpreter.WithStdout(stdout))
+			if err != nil {
+				fmt.Fprintln(stdout, err)
+			}
+		})
+
+		if err := adapter.Serve(); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+		return 0
+	}
+
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: glox debug [--dap] script [args...]")
+		return 64
+	}
+
+	source, err := os.ReadFile(fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 66
+	}
+
+	cli := debugger.NewCLI(os.Stdin, os.Stdout, fs.Arg(0), string(source))
+	status, err := debugFile(fs.Arg(0), fs.Args(), cli)
+	if errors.Is(err, interpreter.ErrStopped) {
+		// The user quit before the script finished, as if interrupting it.
+		return 130
+	}
+	if err != nil {
+		fmt.Println(err)
+		return 65
+	}
+
+	return status
+}
+
+// debugFile runs the script at path under d, returning its exit status, or
+// interpreter.ErrStopped if d stopped it. args are the command line
+// arguments, starting with the script itself; opts configure the
+// interpreter further.
+func debugFile(path string, args []string, d interpreter.Debugger, opts ...interpreter.Option) (int, error) {
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return 0, err
+	}
+
+	var scriptArgs []string
+	if len(args) > 1 {
+		scriptArgs = args[1:]
+	}
+
+	watch := &stopWatch{Debugger: d}
+	opts = append([]interpreter.Option{
+		interpreter.WithArgs(scriptArgs),
+		interpreter.WithScriptPath(path),
+		interpreter.WithDebugger(watch),
+	}, opts...)
+
+	status, err := run("", string(source), reporter{}, opts...)
+	if err == nil && watch.stopped {
+		return status, interpreter.ErrStopped
+	}
+
+	return status, err
+}
+
+// stopWatch remembers whether its Debugger stopped the script, which the
+// interpreter does not report.
+type stopWatch struct {
+	interpreter.Debugger
+	stopped bool
+}
+
+func (w *stopWatch) Break(stack []interpreter.Frame) error {
+	err := w.Debugger.Break(stack)
+	if errors.Is(err, interpreter.ErrStopped) {
+		w.stopped = true
+	}
+
+	return err
+}
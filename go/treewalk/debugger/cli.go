@@ -0,0 +1,176 @@
+package debugger
+
+import (
+	"bufio"
+	"fmt"
+	"glox/interpreter"
+	"io"
+	"strconv"
+	"strings"
+)
+
+const cliHelp = `Commands:
+  c, continue          run until the next breakpoint
+  s, step              step into calls
+  n, next              step over calls
+  o, out               run until the current function returns
+  b, break [file:]N    set a breakpoint on line N
+  d, delete [file:]N   delete the breakpoint on line N
+  bt, stack            print the call stack
+  l, locals            print the variables in scope
+  p, print NAME        print a variable
+  list                 print the source around the current line
+  q, quit              stop debugging
+An empty line repeats the previous command.
+`
+
+// CLI is a line-oriented debugger front end in the style of gdb. It stops
+// before the first statement of the script.
+type CLI struct {
+	*controller
+
+	in    *bufio.Scanner
+	out   io.Writer
+	path  string
+	lines []string
+	last  string
+}
+
+// NewCLI returns a debugger for the script at path, whose source is
+// source, reading commands from in.
+func NewCLI(in io.Reader, out io.Writer, path string, source string) *CLI {
+	c := &CLI{
+		controller: newController(true),
+		in:         bufio.NewScanner(in),
+		out:        out,
+		path:       path,
+		lines:      strings.Split(source, "\n"),
+	}
+	c.stopped = c.prompt
+
+	return c
+}
+
+func (c *CLI) prompt(stack []interpreter.Frame, reason string) command {
+	top := stack[0]
+	fmt.Fprintf(c.out, "Stopped (%s) at line %d in %s: %s\n", reason, top.Line, top.Name, c.source(top))
+
+	for {
+		fmt.Fprint(c.out, "(glox) ")
+		if !c.in.Scan() {
+			// Without a user, let the script run to the end.
+			c.mu.Lock()
+			c.breakpoints = map[string]map[int]bool{}
+			c.mu.Unlock()
+			fmt.Fprintln(c.out)
+			return cmdContinue
+		}
+
+		line := strings.TrimSpace(c.in.Text())
+		if line == "" {
+			line = c.last
+		}
+		c.last = line
+
+		fields := strings.Fields(line)
+		if len(fields) == 0 {
+			continue
+		}
+
+		switch fields[0] {
+		case "c", "continue":
+			return cmdContinue
+		case "s", "step":
+			return cmdStepIn
+		case "n", "next":
+			return cmdStepOver
+		case "o", "out":
+			return cmdStepOut
+		case "b", "break", "d", "delete":
+			c.breakpoint(fields, fields[0] == "b" || fields[0] == "break")
+		case "bt", "stack":
+			for idx, frame := range stack {
+				fmt.Fprintf(c.out, "#%d %s at line %d\n", idx, frame.Name, frame.Line)
+			}
+		case "l", "locals":
+			for _, s := range scopes(top) {
+				fmt.Fprintf(c.out, "%s:\n", s.name)
+				for _, v := range variables(s.env) {
+					fmt.Fprintf(c.out, "  %s = %s\n", v.name, v.value)
+				}
+			}
+		case "p", "print":
+			if len(fields) != 2 {
+				fmt.Fprintln(c.out, "Usage: print NAME")
+				continue
+			}
+			c.print(top, fields[1])
+		case "list":
+			c.list(top)
+		case "q", "quit":
+			return cmdStop
+		case "h", "help":
+			fmt.Fprint(c.out, cliHelp)
+		default:
+			fmt.Fprintf(c.out, "Unknown command '%s'. Type 'help' for a list of commands.\n", fields[0])
+		}
+	}
+}
+
+// source returns the text of the line a frame is on, if it is in the main
+// script.
+func (c *CLI) source(frame interpreter.Frame) string {
+	if frame.Path != canonical(c.path) || frame.Line > len(c.lines) {
+		return ""
+	}
+
+	return strings.TrimSpace(c.lines[frame.Line-1])
+}
+
+func (c *CLI) breakpoint(fields []string, on bool) {
+	if len(fields) != 2 {
+		fmt.Fprintf(c.out, "Usage: %s [file:]LINE\n", fields[0])
+		return
+	}
+
+	path, spec := c.path, fields[1]
+	if idx := strings.LastIndex(spec, ":"); idx >= 0 {
+		path, spec = spec[:idx], spec[idx+1:]
+	}
+
+	line, err := strconv.Atoi(spec)
+	if err != nil || line < 1 {
+		fmt.Fprintf(c.out, "Invalid line '%s'.\n", spec)
+		return
+	}
+
+	c.toggleBreakpoint(path, line, on)
+}
+
+func (c *CLI) print(frame interpreter.Frame, name string) {
+	for _, s := range scopes(frame) {
+		if value, ok := s.env.Lookup(name); ok {
+			fmt.Fprintf(c.out, "%s = %s\n", name, interpreter.Stringify(value))
+			return
+		}
+	}
+
+	fmt.Fprintf(c.out, "No variable '%s' in scope.\n", name)
+}
+
+// list prints the lines around the one a frame is on.
+func (c *CLI) list(frame interpreter.Frame) {
+	if frame.Path != canonical(c.path) {
+		fmt.Fprintln(c.out, "Source is only available for the main script.")
+		return
+	}
+
+	from, to := max(frame.Line-5, 1), min(frame.Line+5, len(c.lines))
+	for n := from; n <= to; n++ {
+		marker := " "
+		if n == frame.Line {
+			marker = ">"
+		}
+		fmt.Fprintf(c.out, "%s%4d  %s\n", marker, n, c.lines[n-1])
+	}
+}
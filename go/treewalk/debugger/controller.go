@@ -0,0 +1,208 @@
+package debugger
+
+import (
+	"glox/environment"
+	"glox/interpreter"
+	"path/filepath"
+	"sort"
+	"sync"
+)
+
+// command tells a stopped script how to carry on.
+type command int
+
+const (
+	cmdContinue command = iota
+	cmdStepIn
+	cmdStepOver
+	cmdStepOut
+	cmdStop
+)
+
+// Reasons a script stops for.
+const (
+	reasonEntry      = "entry"
+	reasonStep       = "step"
+	reasonBreakpoint = "breakpoint"
+	reasonPause      = "pause"
+)
+
+// controller decides where a script stops. It implements
+// interpreter.Debugger for the front ends, which embed it and supply the
+// stopped callback asking the user how to continue.
+//
+// A script only stops when it reaches a new line or call depth, so that a
+// line holding several statements, or a loop written on one line, is
+// stepped over as a whole.
+type controller struct {
+	stopped func(stack []interpreter.Frame, reason string) command
+
+	mu          sync.Mutex
+	breakpoints map[string]map[int]bool
+	entry       bool
+	pause       bool
+	cmd         command
+	depth       int
+	lastLine    int
+	lastDepth   int
+}
+
+func newController(stopOnEntry bool) *controller {
+	return &controller{
+		breakpoints: map[string]map[int]bool{},
+		entry:       stopOnEntry,
+	}
+}
+
+func (c *controller) Break(stack []interpreter.Frame) error {
+	top, depth := stack[0], len(stack)
+	if top.Line == 0 {
+		return nil
+	}
+
+	c.mu.Lock()
+	moved := top.Line != c.lastLine || depth != c.lastDepth
+	c.lastLine, c.lastDepth = top.Line, depth
+
+	var reason string
+	switch {
+	case !moved:
+	case c.entry:
+		reason = reasonEntry
+	case c.pause:
+		reason = reasonPause
+	case c.breakpoints[top.Path][top.Line]:
+		reason = reasonBreakpoint
+	case c.cmd == cmdStepIn,
+		c.cmd == cmdStepOver && depth <= c.depth,
+		c.cmd == cmdStepOut && depth < c.depth:
+		reason = reasonStep
+	}
+	c.mu.Unlock()
+
+	if reason == "" {
+		return nil
+	}
+
+	cmd := c.stopped(stack, reason)
+	if cmd == cmdStop {
+		return interpreter.ErrStopped
+	}
+
+	c.mu.Lock()
+	c.entry, c.pause = false, false
+	c.cmd, c.depth = cmd, depth
+	c.mu.Unlock()
+
+	return nil
+}
+
+// setBreakpoints replaces the breakpoints in the file at path.
+func (c *controller) setBreakpoints(path string, lines []int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	set := map[int]bool{}
+	for _, line := range lines {
+		set[line] = true
+	}
+	c.breakpoints[canonical(path)] = set
+}
+
+// toggleBreakpoint sets or clears one breakpoint.
+func (c *controller) toggleBreakpoint(path string, line int, on bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	path = canonical(path)
+	if c.breakpoints[path] == nil {
+		c.breakpoints[path] = map[int]bool{}
+	}
+
+	if on {
+		c.breakpoints[path][line] = true
+	} else {
+		delete(c.breakpoints[path], line)
+	}
+}
+
+func (c *controller) setStopOnEntry(on bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.entry = on
+}
+
+// requestPause stops the script at the next line it reaches.
+func (c *controller) requestPause() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.pause = true
+}
+
+// canonical returns the path the interpreter reports for the module in the
+// file at path.
+func canonical(path string) string {
+	abs, err := filepath.Abs(path)
+	if err != nil {
+		return path
+	}
+
+	if real, err := filepath.EvalSymlinks(abs); err == nil {
+		return real
+	}
+
+	return abs
+}
+
+// scope is one environment in the chain visible from a frame.
+type scope struct {
+	name string
+	env  *environment.Environment
+}
+
+// scopes returns the environments visible from a frame, innermost first,
+// leaving out the builtins every script shares.
+func scopes(frame interpreter.Frame) []scope {
+	var chain []*environment.Environment
+	for env := frame.Env; env != nil && env.Enclosing() != nil; env = env.Enclosing() {
+		chain = append(chain, env)
+	}
+
+	result := make([]scope, 0, len(chain))
+	for idx, env := range chain {
+		name := "Closure"
+		switch {
+		case idx == len(chain)-1:
+			name = "Globals"
+		case idx == 0:
+			name = "Locals"
+		}
+
+		result = append(result, scope{name: name, env: env})
+	}
+
+	return result
+}
+
+// variable is a binding shown to the user.
+type variable struct {
+	name  string
+	value string
+}
+
+func variables(env *environment.Environment) []variable {
+	values := env.Values()
+
+	vars := make([]variable, 0, len(values))
+	for name, value := range values {
+		vars = append(vars, variable{name: name, value: interpreter.Stringify(value)})
+	}
+
+	sort.Slice(vars, func(i, j int) bool {
+		return vars[i].name < vars[j].name
+	})
+
+	return vars
+}
@@ -0,0 +1,363 @@
+package debugger
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"glox/environment"
+	"glox/interpreter"
+	"io"
+	"net/textproto"
+	"strconv"
+	"sync"
+)
+
+// DAP is a debugger front end speaking the Debug Adapter Protocol, so that
+// editors such as VS Code can drive it. Scripts only ever have one thread,
+// whose ID is 1.
+type DAP struct {
+	*controller
+
+	in     *bufio.Reader
+	out    io.Writer
+	run    func(program string, d interpreter.Debugger, stdout io.Writer)
+	stdout *dapOutput
+
+	// writeMu guards writes to out and seq, which happen from both the
+	// goroutine serving requests and the one running the script.
+	writeMu sync.Mutex
+	seq     int
+
+	program string
+	cmds    chan command
+
+	// stack and refs describe the script while it is stopped. A
+	// variablesReference indexes refs and is only valid until the script
+	// is resumed. They are guarded by stateMu.
+	stateMu sync.Mutex
+	stack   []interpreter.Frame
+	refs    []*environment.Environment
+}
+
+type dapMessage struct {
+	Seq       int             `json:"seq"`
+	Type      string          `json:"type"`
+	Command   string          `json:"command,omitempty"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+type dapResponse struct {
+	Seq        int         `json:"seq"`
+	Type       string      `json:"type"`
+	RequestSeq int         `json:"request_seq"`
+	Success    bool        `json:"success"`
+	Command    string      `json:"command"`
+	Message    string      `json:"message,omitempty"`
+	Body       interface{} `json:"body,omitempty"`
+}
+
+type dapEvent struct {
+	Seq   int         `json:"seq"`
+	Type  string      `json:"type"`
+	Event string      `json:"event"`
+	Body  interface{} `json:"body,omitempty"`
+}
+
+type dapSource struct {
+	Path string `json:"path"`
+}
+
+// NewDAP returns an adapter reading requests from in and writing responses
+// and events to out. run is called on a new goroutine to run the program
+// named by the launch request under the given debugger, once the client
+// has finished configuring breakpoints; what the program prints to stdout
+// is sent to the client as output events, all of them before the
+// terminated event. program is the program to run if the launch request
+// does not name one.
+func NewDAP(in io.Reader, out io.Writer, program string, run func(program string, d interpreter.Debugger, stdout io.Writer)) *DAP {
+	d := &DAP{
+		controller: newController(false),
+		in:         bufio.NewReader(in),
+		out:        out,
+		run:        run,
+		program:    program,
+		cmds:       make(chan command),
+	}
+	d.stopped = d.wait
+	d.stdout = &dapOutput{dap: d}
+
+	return d
+}
+
+// Serve handles requests until the client disconnects or closes the input.
+func (d *DAP) Serve() error {
+	for {
+		body, err := d.read()
+		if err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return err
+		}
+
+		var req dapMessage
+		if err := json.Unmarshal(body, &req); err != nil {
+			return err
+		}
+
+		if req.Type != "request" {
+			continue
+		}
+
+		if done := d.handle(&req); done {
+			return nil
+		}
+	}
+}
+
+func (d *DAP) read() ([]byte, error) {
+	header, err := textproto.NewReader(d.in).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %w", err)
+	}
+
+	body := make([]byte, length)
+	_, err = io.ReadFull(d.in, body)
+
+	return body, err
+}
+
+func (d *DAP) write(msg interface{}) {
+	d.writeMu.Lock()
+	defer d.writeMu.Unlock()
+
+	d.seq++
+	switch m := msg.(type) {
+	case *dapResponse:
+		m.Seq = d.seq
+	case *dapEvent:
+		m.Seq = d.seq
+	}
+
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintf(d.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (d *DAP) respond(req *dapMessage, body interface{}) {
+	d.write(&dapResponse{Type: "response", RequestSeq: req.Seq, Success: true, Command: req.Command, Body: body})
+}
+
+func (d *DAP) fail(req *dapMessage, msg string) {
+	d.write(&dapResponse{Type: "response", RequestSeq: req.Seq, Command: req.Command, Message: msg})
+}
+
+func (d *DAP) event(name string, body interface{}) {
+	d.write(&dapEvent{Type: "event", Event: name, Body: body})
+}
+
+// Output sends text the script printed to the client's console.
+func (d *DAP) Output(text string) {
+	d.event("output", map[string]string{"category": "stdout", "output": text})
+}
+
+// dapOutput sends what is written to it to the client as output, a line
+// at a time.
+type dapOutput struct {
+	dap *DAP
+
+	mu  sync.Mutex
+	buf []byte
+}
+
+func (o *dapOutput) Write(p []byte) (int, error) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.buf = append(o.buf, p...)
+	for {
+		idx := bytes.IndexByte(o.buf, '\n')
+		if idx < 0 {
+			break
+		}
+		o.dap.Output(string(o.buf[:idx+1]))
+		o.buf = o.buf[idx+1:]
+	}
+
+	return len(p), nil
+}
+
+// flush sends the rest of a line that was never ended.
+func (o *dapOutput) flush() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	if len(o.buf) > 0 {
+		o.dap.Output(string(o.buf))
+		o.buf = nil
+	}
+}
+
+// handle serves one request and reports whether the session is over.
+func (d *DAP) handle(req *dapMessage) bool {
+	d.stateMu.Lock()
+	defer d.stateMu.Unlock()
+
+	switch req.Command {
+	case "initialize":
+		d.respond(req, map[string]bool{"supportsConfigurationDoneRequest": true})
+		d.event("initialized", nil)
+	case "launch":
+		var args struct {
+			Program     string `json:"program"`
+			StopOnEntry bool   `json:"stopOnEntry"`
+		}
+		json.Unmarshal(req.Arguments, &args)
+
+		if args.Program != "" {
+			d.program = args.Program
+		}
+		if d.program == "" {
+			d.fail(req, "No program to debug.")
+			return false
+		}
+
+		d.setStopOnEntry(args.StopOnEntry)
+		d.respond(req, nil)
+	case "setBreakpoints":
+		var args struct {
+			Source      dapSource `json:"source"`
+			Breakpoints []struct {
+				Line int `json:"line"`
+			} `json:"breakpoints"`
+		}
+		json.Unmarshal(req.Arguments, &args)
+
+		lines := []int{}
+		verified := []map[string]interface{}{}
+		for _, bp := range args.Breakpoints {
+			lines = append(lines, bp.Line)
+			verified = append(verified, map[string]interface{}{"verified": true, "line": bp.Line})
+		}
+		d.setBreakpoints(args.Source.Path, lines)
+		d.respond(req, map[string]interface{}{"breakpoints": verified})
+	case "setExceptionBreakpoints":
+		d.respond(req, map[string]interface{}{"breakpoints": []interface{}{}})
+	case "configurationDone":
+		d.respond(req, nil)
+		go func() {
+			d.run(d.program, d, d.stdout)
+			d.stdout.flush()
+			d.event("terminated", nil)
+		}()
+	case "threads":
+		d.respond(req, map[string]interface{}{
+			"threads": []map[string]interface{}{{"id": 1, "name": "main"}},
+		})
+	case "stackTrace":
+		frames := []map[string]interface{}{}
+		for idx, frame := range d.stack {
+			f := map[string]interface{}{"id": idx + 1, "name": frame.Name, "line": frame.Line, "column": 1}
+			if frame.Path != "" {
+				f["source"] = dapSource{Path: frame.Path}
+			}
+			frames = append(frames, f)
+		}
+		d.respond(req, map[string]interface{}{"stackFrames": frames, "totalFrames": len(frames)})
+	case "scopes":
+		var args struct {
+			FrameID int `json:"frameId"`
+		}
+		json.Unmarshal(req.Arguments, &args)
+
+		result := []map[string]interface{}{}
+		if args.FrameID > 0 && args.FrameID <= len(d.stack) {
+			for _, s := range scopes(d.stack[args.FrameID-1]) {
+				d.refs = append(d.refs, s.env)
+				result = append(result, map[string]interface{}{
+					"name":               s.name,
+					"variablesReference": len(d.refs),
+					"expensive":          false,
+				})
+			}
+		}
+		d.respond(req, map[string]interface{}{"scopes": result})
+	case "variables":
+		var args struct {
+			VariablesReference int `json:"variablesReference"`
+		}
+		json.Unmarshal(req.Arguments, &args)
+
+		result := []map[string]interface{}{}
+		if ref := args.VariablesReference; ref > 0 && ref <= len(d.refs) {
+			for _, v := range variables(d.refs[ref-1]) {
+				result = append(result, map[string]interface{}{
+					"name":               v.name,
+					"value":              v.value,
+					"variablesReference": 0,
+				})
+			}
+		}
+		d.respond(req, map[string]interface{}{"variables": result})
+	case "continue":
+		d.respond(req, map[string]bool{"allThreadsContinued": true})
+		d.resume(cmdContinue)
+	case "next":
+		d.respond(req, nil)
+		d.resume(cmdStepOver)
+	case "stepIn":
+		d.respond(req, nil)
+		d.resume(cmdStepIn)
+	case "stepOut":
+		d.respond(req, nil)
+		d.resume(cmdStepOut)
+	case "pause":
+		d.requestPause()
+		d.respond(req, nil)
+	case "disconnect", "terminate":
+		d.respond(req, nil)
+		return true
+	default:
+		d.fail(req, "Unsupported request: "+req.Command)
+	}
+
+	return false
+}
+
+// wait is called on the script's goroutine when it stops, and blocks
+// until the client resumes it.
+func (d *DAP) wait(stack []interpreter.Frame, reason string) command {
+	d.stateMu.Lock()
+	d.stack = stack
+	d.refs = nil
+	d.stateMu.Unlock()
+
+	d.event("stopped", map[string]interface{}{
+		"reason":            reason,
+		"threadId":          1,
+		"allThreadsStopped": true,
+	})
+
+	return <-d.cmds
+}
+
+// resume continues a stopped script. Requests to resume a script that is
+// running are ignored. The caller holds stateMu; the script is blocked
+// receiving from cmds, so sending cannot deadlock.
+func (d *DAP) resume(cmd command) {
+	if d.stack == nil {
+		return
+	}
+
+	d.stack = nil
+	d.cmds <- cmd
+}
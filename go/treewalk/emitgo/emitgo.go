@@ -0,0 +1,900 @@
+package emitgo
+
+import (
+	"fmt"
+	"glox/generated"
+	"glox/lerr"
+	"glox/parser"
+	"glox/resolver"
+	"glox/scanner"
+	"glox/token"
+	"go/format"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var _ generated.VisitorStmt = (*emitter)(nil)
+var _ generated.VisitorExpr = (*emitter)(nil)
+
+// Emit compiles the script at path, and the modules it imports, to the
+// source of a Go program. The program is not standalone: it imports the
+// runtime package glox/rt, and with it the interpreter, so it builds
+// within the glox module, or within a module that requires glox and
+// replaces it with a checkout of glox:
+//
+//	require glox v0.0.0
+//
+//	replace glox => ../path/to/glox
+//
+// Each module becomes a Go function. Local variables become Go variables,
+// which Go closures capture the way Lox closures capture their
+// environment, while globals stay in the module's environment. Operators,
+// calls and natives go through package rt, which applies the rules of the
+// interpreter to the same values.
+func Emit(path string) ([]byte, error) {
+	abs, err := canonicalPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &emitter{
+		locals:  make(map[generated.Expr]bool),
+		tails:   make(map[*generated.Call]bool),
+		tokens:  make(map[token.Token]string),
+		modules: map[string]int{abs: 0},
+		paths:   []string{abs},
+	}
+
+	// Imports found while emitting a module add to e.paths.
+	var modules []string
+	for idx := 0; idx < len(e.paths); idx++ {
+		code, err := e.module(idx)
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, code)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by glox build from %s; DO NOT EDIT.\n\n", filepath.Base(path))
+	b.WriteString("package main\n\nimport \"glox/rt\"\n\n")
+
+	b.WriteString("var (\n")
+	for _, decl := range e.decls {
+		b.WriteString(decl + "\n")
+	}
+	b.WriteString(")\n\n")
+
+	fmt.Fprintf(&b, "func main() {\nrt.Main(%s, module0)\n}\n", strconv.Quote(abs))
+	for _, code := range modules {
+		b.WriteString("\n" + code)
+	}
+
+	src, err := format.Source([]byte(b.String()))
+	if err != nil {
+		return nil, fmt.Errorf("emitted invalid Go: %v", err)
+	}
+
+	return src, nil
+}
+
+// emitter implements resolver.Binder to learn which variables are local,
+// and resolver.TailCaller to learn which calls are tail calls. Expressions
+// emit the Go code evaluating them, and statements write theirs to out.
+type emitter struct {
+	locals map[generated.Expr]bool
+	tails  map[*generated.Call]bool
+	tokens map[token.Token]string
+	decls  []string
+
+	modules map[string]int
+	paths   []string
+	path    string
+
+	out   *strings.Builder
+	temps int
+
+	// scopes counts the scopes the resolver would have open, so that
+	// declarations outside of any are known to be globals. inFunction
+	// is set inside a function, and tries counts the try blocks around the
+	// code being emitted within it, which are Go closures.
+	scopes     int
+	inFunction bool
+	tries      int
+}
+
+func (e *emitter) Resolve(expr generated.Expr, _ int) {
+	e.locals[expr] = true
+}
+
+func (e *emitter) TailCall(call *generated.Call) {
+	e.tails[call] = true
+}
+
+// module emits the module with the given index as a Go function.
+func (e *emitter) module(idx int) (string, error) {
+	e.path = e.paths[idx]
+
+	source, err := os.ReadFile(e.path)
+	if err != nil {
+		return "", err
+	}
+
+	tokens, err := scanner.NewScanner(string(source)).ScanTokens()
+	if err != nil {
+		return "", err
+	}
+
+	stmts, err := parser.NewParser(tokens).Parse()
+	if err != nil {
+		return "", err
+	}
+
+	err = resolver.NewResolver(e).Resolve(stmts)
+	if err != nil {
+		return "", err
+	}
+
+	e.out = &strings.Builder{}
+	fmt.Fprintf(e.out, "// module%d is %s.\nfunc module%d(m *rt.Module) {\n", idx, filepath.Base(e.path), idx)
+	err = e.stmts(stmts)
+	if err != nil {
+		return "", err
+	}
+	e.out.WriteString("}\n")
+
+	return e.out.String(), nil
+}
+
+func (e *emitter) stmts(stmts []generated.Stmt) error {
+	for _, stmt := range stmts {
+		if _, err := stmt.Accept(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// block emits stmts in a scope of their own.
+func (e *emitter) block(stmts []generated.Stmt) error {
+	e.scopes++
+	defer func() { e.scopes-- }()
+
+	return e.stmts(stmts)
+}
+
+func (e *emitter) line(format string, args ...interface{}) {
+	fmt.Fprintf(e.out, format+"\n", args...)
+}
+
+func (e *emitter) expr(expr generated.Expr) (string, error) {
+	code, err := expr.Accept(e)
+	if err != nil {
+		return "", err
+	}
+
+	return code.(string), nil
+}
+
+// operands emits expressions evaluated in order. Go only orders the calls
+// among them, so a local variable read before an operand that could change
+// it is read through a call too.
+func (e *emitter) operands(exprs ...generated.Expr) ([]string, error) {
+	codes := make([]string, len(exprs))
+	for idx, expr := range exprs {
+		code, err := e.expr(expr)
+		if err != nil {
+			return nil, err
+		}
+
+		if e.isLocal(expr) {
+			for _, later := range exprs[idx+1:] {
+				if effects(later) {
+					code = "rt.Read(" + code + ")"
+					break
+				}
+			}
+		}
+
+		codes[idx] = code
+	}
+
+	return codes, nil
+}
+
+// isLocal reports whether expr reads a local variable.
+func (e *emitter) isLocal(expr generated.Expr) bool {
+	for {
+		g, ok := expr.(*generated.Grouping)
+		if !ok {
+			break
+		}
+		expr = g.Expression
+	}
+
+	v, ok := expr.(*generated.VarExpr)
+
+	return ok && e.locals[v]
+}
+
+// effects reports whether evaluating expr could assign a variable.
+func effects(expr generated.Expr) bool {
+	switch x := expr.(type) {
+	case *generated.Call, *generated.Assign, *generated.MatchExpr:
+		return true
+	case *generated.Grouping:
+		return effects(x.Expression)
+	case *generated.Unary:
+		return effects(x.Right)
+	case *generated.Get:
+		return effects(x.Object)
+	case *generated.Binary:
+		return effects(x.Left) || effects(x.Right)
+	case *generated.Logical:
+		return effects(x.Left) || effects(x.Right)
+	case *generated.Ternary:
+		return effects(x.Condition) || effects(x.ValueTrue) || effects(x.ValueFalse)
+	}
+
+	return false
+}
+
+// tok returns the Go variable holding a token of the script, declaring it
+// the first time.
+func (e *emitter) tok(t token.Token) string {
+	if name, ok := e.tokens[t]; ok {
+		return name
+	}
+
+	name := fmt.Sprintf("t%d", len(e.decls))
+	e.tokens[t] = name
+	e.decls = append(e.decls, fmt.Sprintf("%s = rt.Tok(%s, %s, %d, %d)",
+		name, strconv.Quote(string(t.GetType())), strconv.Quote(t.GetLexeme()), t.GetLine(), t.GetColumn()))
+
+	return name
+}
+
+// local names the Go variable holding a local Lox variable.
+func local(name token.Token) string {
+	return "v_" + name.GetLexeme()
+}
+
+func (e *emitter) temp(prefix string) string {
+	e.temps++
+	return fmt.Sprintf("%s%d", prefix, e.temps)
+}
+
+// declare defines a variable, global or local as the resolver decided.
+func (e *emitter) declare(name token.Token, value string) {
+	if e.scopes == 0 {
+		e.line("m.Define(%s, %s)", strconv.Quote(name.GetLexeme()), value)
+		return
+	}
+
+	e.line("var %s rt.Value = %s", local(name), value)
+	e.line("_ = %s", local(name))
+}
+
+// ret emits a return from the current function.
+func (e *emitter) ret(value string) {
+	if e.tries > 0 {
+		e.line("return %s, true", value)
+	} else {
+		e.line("return %s", value)
+	}
+}
+
+func endsInReturn(stmts []generated.Stmt) bool {
+	if len(stmts) == 0 {
+		return false
+	}
+
+	_, ok := stmts[len(stmts)-1].(*generated.ReturnStmt)
+
+	return ok
+}
+
+func canonicalPath(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.EvalSymlinks(abs)
+}
+
+func (e *emitter) VisitBlockStmt(stmt *generated.BlockStmt) (interface{}, error) {
+	e.line("{")
+	if err := e.block(stmt.Statements); err != nil {
+		return nil, err
+	}
+	e.line("}")
+
+	return nil, nil
+}
+
+func (e *emitter) VisitIfStmt(stmt *generated.IfStmt) (interface{}, error) {
+	cond, err := e.expr(stmt.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	e.line("if rt.Truthy(%s) {", cond)
+	if _, err := stmt.IfBranch.Accept(e); err != nil {
+		return nil, err
+	}
+
+	if stmt.ElseBranch != nil {
+		e.line("} else {")
+		if _, err := stmt.ElseBranch.Accept(e); err != nil {
+			return nil, err
+		}
+	}
+	e.line("}")
+
+	return nil, nil
+}
+
+func (e *emitter) VisitWhileStmt(stmt *generated.WhileStmt) (interface{}, error) {
+	cond, err := e.expr(stmt.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	e.line("for rt.Truthy(%s) {", cond)
+	if _, err := stmt.Stmt.Accept(e); err != nil {
+		return nil, err
+	}
+	e.line("}")
+
+	return nil, nil
+}
+
+func (e *emitter) VisitExprStmt(stmt *generated.ExprStmt) (interface{}, error) {
+	expr := stmt.Expr
+	for {
+		g, ok := expr.(*generated.Grouping)
+		if !ok {
+			break
+		}
+		expr = g.Expression
+	}
+
+	if assign, ok := expr.(*generated.Assign); ok && e.locals[assign] {
+		value, err := e.expr(assign.Value)
+		if err != nil {
+			return nil, err
+		}
+
+		e.line("%s = %s", local(assign.Name), value)
+		return nil, nil
+	}
+
+	code, err := e.expr(expr)
+	if err != nil {
+		return nil, err
+	}
+
+	switch expr.(type) {
+	case *generated.Literal, *generated.VarExpr:
+		e.line("_ = %s", code)
+	default:
+		e.line("%s", code)
+	}
+
+	return nil, nil
+}
+
+func (e *emitter) VisitPrintStmt(stmt *generated.PrintStmt) (interface{}, error) {
+	value, err := e.expr(stmt.Expr)
+	if err != nil {
+		return nil, err
+	}
+
+	e.line("rt.Print(%s)", value)
+
+	return nil, nil
+}
+
+func (e *emitter) VisitVarStmt(stmt *generated.VarStmt) (interface{}, error) {
+	value := "nil"
+	if stmt.Initializer != nil {
+		var err error
+		value, err = e.expr(stmt.Initializer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	e.declare(stmt.Name, value)
+
+	return nil, nil
+}
+
+// VisitFunctionStmt declares a local function before creating it, so that
+// its body can refer to it.
+func (e *emitter) VisitFunctionStmt(stmt *generated.FunctionStmt) (interface{}, error) {
+	global := e.scopes == 0
+
+	if !global {
+		e.line("var %s rt.Value", local(stmt.Name))
+	}
+
+	body := e.out
+	e.out = &strings.Builder{}
+	err := e.function(stmt)
+	code := e.out.String()
+	e.out = body
+	if err != nil {
+		return nil, err
+	}
+
+	if global {
+		e.line("m.Define(%s, %s)", strconv.Quote(stmt.Name.GetLexeme()), code)
+	} else {
+		e.line("%s = %s", local(stmt.Name), code)
+		e.line("_ = %s", local(stmt.Name))
+	}
+
+	return nil, nil
+}
+
+// function writes the expression creating a function to out. Parameters
+// the call left unset take their default, evaluated before the parameter
+// is declared as the resolver has it, or are reported as missing.
+func (e *emitter) function(stmt *generated.FunctionStmt) error {
+	scopes, inFunction, tries := e.scopes, e.inFunction, e.tries
+	e.scopes, e.inFunction, e.tries = e.scopes+1, true, 0
+	defer func() {
+		e.scopes, e.inFunction, e.tries = scopes, inFunction, tries
+	}()
+
+	name := e.tok(stmt.Name)
+
+	params := make([]string, len(stmt.Params))
+	required := 0
+	for idx, param := range stmt.Params {
+		params[idx] = strconv.Quote(param.GetLexeme())
+		if stmt.Defaults[idx] == nil {
+			required++
+		}
+	}
+
+	e.line("rt.NewFunction(%s, []string{%s}, %d, %t, func(p []rt.Value) rt.Value {",
+		name, strings.Join(params, ", "), required, stmt.Rest != nil)
+
+	var vars []string
+	for idx, param := range stmt.Params {
+		if def := stmt.Defaults[idx]; def != nil {
+			value, err := e.expr(def)
+			if err != nil {
+				return err
+			}
+			e.line("if p[%d] == rt.Unset {\np[%d] = %s\n}", idx, idx, value)
+		} else {
+			e.line("if p[%d] == rt.Unset {\nrt.MissingArg(%s, %s)\n}", idx, e.tok(param), name)
+		}
+
+		e.line("%s := p[%d]", local(param), idx)
+		vars = append(vars, local(param))
+	}
+
+	if stmt.Rest != nil {
+		e.line("%s := p[%d]", local(stmt.Rest), len(stmt.Params))
+		vars = append(vars, local(stmt.Rest))
+	}
+
+	if len(vars) > 0 {
+		e.line("%s = %s", strings.TrimSuffix(strings.Repeat("_, ", len(vars)), ", "), strings.Join(vars, ", "))
+	}
+
+	if err := e.stmts(stmt.Body); err != nil {
+		return err
+	}
+
+	if !endsInReturn(stmt.Body) {
+		e.line("return nil")
+	}
+	e.out.WriteString("})")
+
+	return nil
+}
+
+// VisitReturnStmt returns tail calls to Lox functions for rt.Function to
+// make once the function returning has finished, so that deep tail
+// recursion runs in constant Go stack space as it does in the interpreter.
+func (e *emitter) VisitReturnStmt(stmt *generated.ReturnStmt) (interface{}, error) {
+	value := "nil"
+	if stmt.Value != nil {
+		var err error
+		value, err = e.expr(stmt.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if call, ok := stmt.Value.(*generated.Call); ok && e.tails[call] {
+		value = "rt.Tail" + strings.TrimPrefix(value, "rt.")
+	}
+
+	e.ret(value)
+
+	return nil, nil
+}
+
+// VisitImportStmt resolves the path of an imported module as the
+// interpreter does, relative to the importing module, and queues the
+// module to be emitted.
+func (e *emitter) VisitImportStmt(stmt *generated.ImportStmt) (interface{}, error) {
+	rel := stmt.Path.GetLiteral().(string)
+
+	p := rel
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(filepath.Dir(e.path), p)
+	}
+
+	path, err := canonicalPath(p)
+	if err != nil {
+		return nil, lerr.NewSyntaxErrAt(lerr.SpanOf(stmt.Path), "", fmt.Sprintf("Cannot import '%s': %v.", rel, err))
+	}
+
+	idx, ok := e.modules[path]
+	if !ok {
+		idx = len(e.paths)
+		e.modules[path] = idx
+		e.paths = append(e.paths, path)
+	}
+
+	load := fmt.Sprintf("rt.Import(%s, %s, module%d)", e.tok(stmt.Path), strconv.Quote(path), idx)
+	if stmt.Alias != nil {
+		e.declare(stmt.Alias, load)
+		return nil, nil
+	}
+
+	mod := e.temp("mod")
+	e.line("%s := %s", mod, load)
+	for _, name := range stmt.Names {
+		e.declare(name, fmt.Sprintf("rt.Member(%s, %s)", mod, e.tok(name)))
+	}
+
+	return nil, nil
+}
+
+func (e *emitter) VisitThrowStmt(stmt *generated.ThrowStmt) (interface{}, error) {
+	value, err := e.expr(stmt.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	e.line("rt.Throw(%s, %s)", e.tok(stmt.Keyword), value)
+
+	return nil, nil
+}
+
+// VisitTryStmt emits the blocks of a try statement as closures passed to
+// rt.Try, which report a return from the function they are in so that it
+// can be made once they are done.
+func (e *emitter) VisitTryStmt(stmt *generated.TryStmt) (interface{}, error) {
+	e.tries++
+	defer func() { e.tries-- }()
+
+	closure := func(stmts []generated.Stmt, scopes int) (string, error) {
+		body := e.out
+		e.out = &strings.Builder{}
+
+		e.scopes += scopes
+		err := e.stmts(stmts)
+		e.scopes -= scopes
+
+		if !endsInReturn(stmts) {
+			e.line("return nil, false")
+		}
+
+		code := e.out.String()
+		e.out = body
+
+		return code, err
+	}
+
+	body, err := closure(stmt.Body, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	catch := "nil"
+	if stmt.CatchName != nil {
+		code, err := closure(stmt.CatchBody, 2)
+		if err != nil {
+			return nil, err
+		}
+
+		name := local(stmt.CatchName)
+		catch = fmt.Sprintf("func(%s rt.Value) (rt.Value, bool) {\n_ = %s\n%s}", name, name, code)
+	}
+
+	finally := "nil"
+	if stmt.FinallyBody != nil {
+		code, err := closure(stmt.FinallyBody, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		finally = "func() (rt.Value, bool) {\n" + code + "}"
+	}
+
+	try := fmt.Sprintf("rt.Try(func() (rt.Value, bool) {\n%s}, %s, %s)", body, catch, finally)
+	if !e.inFunction {
+		e.line("%s", try)
+		return nil, nil
+	}
+
+	e.tries--
+	e.line("if ret, ok := %s; ok {", try)
+	e.ret("ret")
+	e.line("}")
+	e.tries++
+
+	return nil, nil
+}
+
+// VisitMatchStmt emits the arms of a match in a loop that is left once one
+// of them runs, since an arm whose guard fails falls through to the next.
+func (e *emitter) VisitMatchStmt(stmt *generated.MatchStmt) (interface{}, error) {
+	subject, err := e.expr(stmt.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	s := e.temp("subject")
+	e.line("for %s := %s; ; {", s, subject)
+	e.line("_ = %s", s)
+
+	for _, mc := range stmt.Cases {
+		err := e.arm(s, mc, func() error {
+			if _, err := mc.Body.Accept(e); err != nil {
+				return err
+			}
+			e.line("break")
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	e.line("break")
+	e.line("}")
+
+	return nil, nil
+}
+
+// arm emits one arm of a match on the value in the Go variable s, calling
+// body to emit what it does once it is selected.
+func (e *emitter) arm(s string, mc *generated.MatchCase, body func() error) error {
+	e.scopes++
+	defer func() { e.scopes-- }()
+
+	e.line("{")
+	if mc.Binding != nil {
+		e.line("var %s rt.Value = %s", local(mc.Binding), s)
+		e.line("_ = %s", local(mc.Binding))
+	}
+
+	var conds []string
+	if len(mc.Patterns) > 0 {
+		var matches []string
+		for _, pattern := range mc.Patterns {
+			p, err := e.expr(pattern)
+			if err != nil {
+				return err
+			}
+			matches = append(matches, fmt.Sprintf("rt.Equal(%s, %s)", s, p))
+		}
+		conds = append(conds, strings.Join(matches, " || "))
+	}
+
+	if mc.Guard != nil {
+		guard, err := e.expr(mc.Guard)
+		if err != nil {
+			return err
+		}
+		conds = append(conds, fmt.Sprintf("rt.Truthy(%s)", guard))
+	}
+
+	for _, cond := range conds {
+		e.line("if %s {", cond)
+	}
+
+	if err := body(); err != nil {
+		return err
+	}
+
+	e.line("%s}", strings.Repeat("}\n", len(conds)))
+
+	return nil
+}
+
+// VisitTestStmt emits nothing, since tests only run under glox test.
+func (e *emitter) VisitTestStmt(stmt *generated.TestStmt) (interface{}, error) {
+	return nil, nil
+}
+
+func (e *emitter) VisitAssign(expr *generated.Assign) (interface{}, error) {
+	value, err := e.expr(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if e.locals[expr] {
+		return fmt.Sprintf("rt.Set(&%s, %s)", local(expr.Name), value), nil
+	}
+
+	return fmt.Sprintf("m.Set(%s, %s)", e.tok(expr.Name), value), nil
+}
+
+// VisitLogical emits a closure called in place, since only one operand may
+// be evaluated.
+func (e *emitter) VisitLogical(expr *generated.Logical) (interface{}, error) {
+	left, err := e.expr(expr.Left)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := e.expr(expr.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	decided := "rt.Truthy(left)"
+	if expr.Operator.GetType() != token.OR {
+		decided = "!" + decided
+	}
+
+	return fmt.Sprintf("func() rt.Value {\nif left := rt.Value(%s); %s {\nreturn left\n}\nreturn %s\n}()", left, decided, right), nil
+}
+
+func (e *emitter) VisitBinary(expr *generated.Binary) (interface{}, error) {
+	ops, err := e.operands(expr.Left, expr.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("rt.Binary(%s, %s, %s)", e.tok(expr.Operator), ops[0], ops[1]), nil
+}
+
+func (e *emitter) VisitTernary(expr *generated.Ternary) (interface{}, error) {
+	cond, err := e.expr(expr.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	valueTrue, err := e.expr(expr.ValueTrue)
+	if err != nil {
+		return nil, err
+	}
+
+	valueFalse, err := e.expr(expr.ValueFalse)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("func() rt.Value {\nif rt.Truthy(%s) {\nreturn %s\n}\nreturn %s\n}()", cond, valueTrue, valueFalse), nil
+}
+
+func (e *emitter) VisitGrouping(expr *generated.Grouping) (interface{}, error) {
+	return e.expr(expr.Expression)
+}
+
+func (e *emitter) VisitLiteral(expr *generated.Literal) (interface{}, error) {
+	switch v := expr.Value.(type) {
+	case nil:
+		return "nil", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case string:
+		return strconv.Quote(v), nil
+	case int64:
+		return fmt.Sprintf("int64(%d)", v), nil
+	case float64:
+		return fmt.Sprintf("float64(%s)", strconv.FormatFloat(v, 'g', -1, 64)), nil
+	}
+
+	return nil, fmt.Errorf("cannot emit a literal of type %T", expr.Value)
+}
+
+func (e *emitter) VisitUnary(expr *generated.Unary) (interface{}, error) {
+	right, err := e.expr(expr.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("rt.Unary(%s, %s)", e.tok(expr.Operator), right), nil
+}
+
+func (e *emitter) VisitCall(expr *generated.Call) (interface{}, error) {
+	ops, err := e.operands(append([]generated.Expr{expr.Callee}, expr.Arguments...)...)
+	if err != nil {
+		return nil, err
+	}
+
+	callee, args := ops[0], ops[1:]
+	paren := e.tok(expr.Paren)
+
+	named := false
+	names := make([]string, len(expr.Names))
+	for idx, name := range expr.Names {
+		names[idx] = "nil"
+		if name != nil {
+			names[idx] = e.tok(name)
+			named = true
+		}
+	}
+
+	if !named {
+		return fmt.Sprintf("rt.Call(%s)", strings.Join(append([]string{callee, paren}, args...), ", ")), nil
+	}
+
+	list := fmt.Sprintf("[]rt.Token{%s}", strings.Join(names, ", "))
+
+	return fmt.Sprintf("rt.CallNamed(%s)", strings.Join(append([]string{callee, paren, list}, args...), ", ")), nil
+}
+
+func (e *emitter) VisitVarExpr(expr *generated.VarExpr) (interface{}, error) {
+	if e.locals[expr] {
+		return local(expr.Name), nil
+	}
+
+	return fmt.Sprintf("m.Get(%s)", e.tok(expr.Name)), nil
+}
+
+func (e *emitter) VisitGet(expr *generated.Get) (interface{}, error) {
+	object, err := e.expr(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("rt.Get(%s, %s)", object, e.tok(expr.Name)), nil
+}
+
+// VisitMatchExpr emits a closure called in place, returning the value of
+// the arm selected.
+func (e *emitter) VisitMatchExpr(expr *generated.MatchExpr) (interface{}, error) {
+	subject, err := e.expr(expr.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	// The arms are emitted to a builder of their own, since expressions
+	// return their code rather than writing it.
+	outer := e.out
+	e.out = &strings.Builder{}
+	defer func() { e.out = outer }()
+
+	s := e.temp("subject")
+	e.line("func() rt.Value {")
+	e.line("%s := %s", s, subject)
+	e.line("_ = %s", s)
+
+	for _, mc := range expr.Cases {
+		err := e.arm(s, mc, func() error {
+			value, err := e.expr(mc.Value)
+			if err != nil {
+				return err
+			}
+			e.line("return %s", value)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	e.line("rt.Fail(%s, %s)", e.tok(expr.Keyword), strconv.Quote("No match case for value."))
+	e.line("return nil")
+	e.out.WriteString("}()")
+
+	return e.out.String(), nil
+}
@@ -0,0 +1,74 @@
+package emitgo
+
+import (
+	"fmt"
+	"glox/internal/corpustest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestCorpus compiles the scripts of the corpus to Go and expects the
+// programs to print what the interpreter prints and exit as it does.
+//
+// The programs are built in a module of their own that requires glox,
+// replaced by this checkout, which is how a program compiled by glox build
+// is built outside the glox module.
+func TestCorpus(t *testing.T) {
+	if testing.Short() {
+		t.Skip("builds Go programs")
+	}
+
+	gobin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skip("go not found")
+	}
+
+	scripts := corpustest.Scripts(t)
+
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	mod := fmt.Sprintf("module corpus\n\ngo 1.22\n\nrequire glox v0.0.0\n\nreplace glox => %s\n", root)
+	if err := os.WriteFile(filepath.Join(dir, "go.mod"), []byte(mod), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "go.sum"), sum, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, script := range scripts {
+		src, err := Emit(script)
+		if err != nil {
+			t.Fatalf("%s: %v", script, err)
+		}
+
+		pkg := filepath.Join(dir, corpustest.Name(script))
+		if err := os.Mkdir(pkg, 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(pkg, "main.go"), src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bin := filepath.Join(dir, "bin")
+	build := exec.Command(gobin, "build", "-o", bin+string(filepath.Separator), "./...")
+	build.Dir = dir
+	build.Env = append(os.Environ(), "GOWORK=off", "GOFLAGS=-mod=mod")
+	if out, err := build.CombinedOutput(); err != nil {
+		t.Fatalf("go build: %v\n%s", err, out)
+	}
+
+	corpustest.Compare(t, scripts, func(t *testing.T, script string) *exec.Cmd {
+		return exec.Command(filepath.Join(bin, corpustest.Name(script)))
+	})
+}
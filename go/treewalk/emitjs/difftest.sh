@@ -0,0 +1,75 @@
+#!/bin/sh
+# difftest.sh runs Lox scripts with the interpreter and compiled to
+# JavaScript, and reports those whose output or exit status differ.
+#
+#   emitjs/difftest.sh [script.lox | dir]...
+#
+# Directories are searched for *.lox files; the default is the corpus in
+# testdata/corpus, which go test also runs through TestCorpus. Scripts get
+# the arguments "a b" and no input. Compiled scripts run under node, or
+# deno, whichever is installed; without either, nothing is compared. The
+# exit status is 1 if any script differs.
+
+if command -v node >/dev/null 2>&1; then
+	engine="node"
+elif command -v deno >/dev/null 2>&1; then
+	engine="deno run"
+else
+	echo "difftest: no JavaScript engine found, skipping." >&2
+	exit 0
+fi
+
+tmp=$(mktemp -d) || exit 1
+trap 'rm -rf "$tmp"' EXIT
+
+treewalk=$(cd "$(dirname "$0")/.." && pwd)
+(cd "$treewalk" && go build -o "$tmp/glox" .) || exit 1
+
+cat >"$tmp/main.mjs" <<'JS'
+import run from "./script.mjs";
+
+const deno = typeof Deno !== "undefined";
+const status = run({ args: deno ? Deno.args : process.argv.slice(2) });
+if (deno) {
+  Deno.exit(status);
+}
+process.exitCode = status;
+JS
+
+[ $# -eq 0 ] && set -- "$treewalk/testdata/corpus"
+
+passed=0
+failed=0
+skipped=0
+for script in $(find "$@" -name '*.lox' | sort); do
+	"$tmp/glox" "$script" a b >"$tmp/want" 2>&1 </dev/null
+	want=$?
+
+	if ! "$tmp/glox" build --emit=js -o "$tmp/script.mjs" "$script" >"$tmp/got" 2>&1; then
+		# Scripts the interpreter rejects before running them are not
+		# compiled either.
+		if [ $want -eq 65 ]; then
+			skipped=$((skipped + 1))
+			continue
+		fi
+		echo "FAIL $script: does not compile"
+		sed 's/^/    /' "$tmp/got"
+		failed=$((failed + 1))
+		continue
+	fi
+
+	$engine "$tmp/main.mjs" a b >"$tmp/got" 2>&1 </dev/null
+	got=$?
+
+	if [ $want -ne $got ] || ! cmp -s "$tmp/want" "$tmp/got"; then
+		echo "FAIL $script: exit status $got, want $want"
+		diff "$tmp/want" "$tmp/got" | sed 's/^/    /'
+		failed=$((failed + 1))
+		continue
+	fi
+
+	passed=$((passed + 1))
+done
+
+echo "$passed passed, $failed failed, $skipped skipped"
+[ $failed -eq 0 ]
@@ -0,0 +1,875 @@
+package emitjs
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"glox/generated"
+	"glox/lerr"
+	"glox/parser"
+	"glox/resolver"
+	"glox/scanner"
+	"glox/token"
+	"math"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+var _ generated.VisitorStmt = (*emitter)(nil)
+var _ generated.VisitorExpr = (*emitter)(nil)
+
+// runtime defines rt, which compiled code calls for the rules of Lox.
+//
+//go:embed runtime.js
+var runtime string
+
+// Emit compiles the script at path, and the modules it imports, to a
+// self-contained JavaScript module. It exports run, which runs the program
+// and returns the exit status glox would have; see rt.main in runtime.js
+// for its options.
+//
+// Each Lox module becomes a JavaScript function. Local variables become
+// let bindings, which JavaScript closures capture the way Lox closures
+// capture their environment, while globals stay in the module's
+// environment. Operators, calls and natives go through rt, which applies
+// the rules of the interpreter to values kept as the interpreter keeps
+// them, so that integers stay apart from floats.
+func Emit(path string) ([]byte, error) {
+	abs, err := canonicalPath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	e := &emitter{
+		locals:  make(map[generated.Expr]bool),
+		tails:   make(map[*generated.Call]bool),
+		tokens:  make(map[token.Token]string),
+		modules: map[string]int{abs: 0},
+		paths:   []string{abs},
+	}
+
+	// Imports found while emitting a module add to e.paths.
+	var modules []string
+	for idx := 0; idx < len(e.paths); idx++ {
+		code, err := e.module(idx)
+		if err != nil {
+			return nil, err
+		}
+		modules = append(modules, code)
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "// Code generated by glox build from %s; DO NOT EDIT.\n\n", filepath.Base(path))
+	b.WriteString(runtime + "\n")
+
+	for _, decl := range e.decls {
+		b.WriteString(decl + "\n")
+	}
+
+	for _, code := range modules {
+		b.WriteString("\n" + code)
+	}
+
+	fmt.Fprintf(&b, "\nexport function run(options = {}) {\n  return rt.main(options, %s, module0);\n}\n", quote(abs))
+	b.WriteString("\nexport default run;\n")
+
+	return []byte(b.String()), nil
+}
+
+// emitter implements resolver.Binder to learn which variables are local,
+// and resolver.TailCaller to learn which calls are tail calls. Expressions
+// emit the JavaScript evaluating them, and statements write theirs to out.
+type emitter struct {
+	locals map[generated.Expr]bool
+	tails  map[*generated.Call]bool
+	tokens map[token.Token]string
+	decls  []string
+
+	modules map[string]int
+	paths   []string
+	path    string
+
+	out   *strings.Builder
+	depth int
+	temps int
+
+	// scopes mirrors the scopes the resolver would have open, mapping the
+	// Lox variables declared so far in each to their JavaScript names, so
+	// that declarations outside of any are known to be globals. A variable
+	// shadowing another gets a name of its own, since a let binding is
+	// visible throughout its block, even before it is declared.
+	scopes  []map[string]string
+	shadows int
+}
+
+func (e *emitter) Resolve(expr generated.Expr, _ int) {
+	e.locals[expr] = true
+}
+
+func (e *emitter) TailCall(call *generated.Call) {
+	e.tails[call] = true
+}
+
+// module emits the module with the given index as a JavaScript function.
+func (e *emitter) module(idx int) (string, error) {
+	e.path = e.paths[idx]
+
+	source, err := os.ReadFile(e.path)
+	if err != nil {
+		return "", err
+	}
+
+	tokens, err := scanner.NewScanner(string(source)).ScanTokens()
+	if err != nil {
+		return "", err
+	}
+
+	stmts, err := parser.NewParser(tokens).Parse()
+	if err != nil {
+		return "", err
+	}
+
+	err = resolver.NewResolver(e).Resolve(stmts)
+	if err != nil {
+		return "", err
+	}
+
+	e.out = &strings.Builder{}
+	fmt.Fprintf(e.out, "// module%d is %s.\nfunction module%d(m) {\n", idx, filepath.Base(e.path), idx)
+	e.depth = 1
+	err = e.stmts(stmts)
+	if err != nil {
+		return "", err
+	}
+	e.out.WriteString("}\n")
+
+	return e.out.String(), nil
+}
+
+func (e *emitter) stmts(stmts []generated.Stmt) error {
+	for _, stmt := range stmts {
+		if _, err := stmt.Accept(e); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// block emits stmts in a scope of their own.
+func (e *emitter) block(stmts []generated.Stmt) error {
+	e.scopes = append(e.scopes, map[string]string{})
+	defer func() { e.scopes = e.scopes[:len(e.scopes)-1] }()
+
+	return e.stmts(stmts)
+}
+
+// body emits a statement inside braces the caller has opened, leaving out
+// the braces of a block.
+func (e *emitter) body(stmt generated.Stmt) error {
+	e.depth++
+	defer func() { e.depth-- }()
+
+	if b, ok := stmt.(*generated.BlockStmt); ok {
+		return e.block(b.Statements)
+	}
+
+	_, err := stmt.Accept(e)
+
+	return err
+}
+
+func (e *emitter) line(format string, args ...interface{}) {
+	e.out.WriteString(strings.Repeat("  ", e.depth))
+	fmt.Fprintf(e.out, format+"\n", args...)
+}
+
+// nested writes the code emitted by emit one level deeper to a builder of
+// its own, and returns it, for code spanning lines within an expression.
+func (e *emitter) nested(emit func() error) (string, error) {
+	out := e.out
+	e.out = &strings.Builder{}
+	e.depth++
+	defer func() {
+		e.out = out
+		e.depth--
+	}()
+
+	err := emit()
+
+	return e.out.String(), err
+}
+
+func (e *emitter) expr(expr generated.Expr) (string, error) {
+	code, err := expr.Accept(e)
+	if err != nil {
+		return "", err
+	}
+
+	return code.(string), nil
+}
+
+// tok returns the constant holding a token of the script, declaring it the
+// first time.
+func (e *emitter) tok(t token.Token) string {
+	if name, ok := e.tokens[t]; ok {
+		return name
+	}
+
+	name := fmt.Sprintf("t%d", len(e.decls))
+	e.tokens[t] = name
+	e.decls = append(e.decls, fmt.Sprintf("const %s = rt.tok(%s, %s, %d);",
+		name, quote(string(t.GetType())), quote(t.GetLexeme()), t.GetLine()))
+
+	return name
+}
+
+func (e *emitter) temp(prefix string) string {
+	e.temps++
+	return fmt.Sprintf("%s%d", prefix, e.temps)
+}
+
+// bind declares a local variable in the innermost scope, returning its
+// JavaScript name.
+func (e *emitter) bind(name token.Token) string {
+	id := "v_" + name.GetLexeme()
+	if _, ok := e.local(name); ok {
+		e.shadows++
+		id = fmt.Sprintf("%s$%d", id, e.shadows)
+	}
+
+	e.scopes[len(e.scopes)-1][name.GetLexeme()] = id
+
+	return id
+}
+
+// local returns the JavaScript name of the local variable called name.
+func (e *emitter) local(name token.Token) (string, bool) {
+	for idx := len(e.scopes) - 1; idx >= 0; idx-- {
+		if id, ok := e.scopes[idx][name.GetLexeme()]; ok {
+			return id, true
+		}
+	}
+
+	return "", false
+}
+
+// resolved returns the JavaScript name of a variable the resolver found
+// to be local.
+func (e *emitter) resolved(name token.Token) (string, error) {
+	id, ok := e.local(name)
+	if !ok {
+		return "", lerr.NewSyntaxErrAt(lerr.SpanOf(name), "", fmt.Sprintf("Cannot find local variable '%s'.", name.GetLexeme()))
+	}
+
+	return id, nil
+}
+
+// declare defines a variable, global or local as the resolver decided.
+func (e *emitter) declare(name token.Token, value string) {
+	if len(e.scopes) == 0 {
+		e.line("m.define(%s, %s);", quote(name.GetLexeme()), value)
+		return
+	}
+
+	e.line("let %s = %s;", e.bind(name), value)
+}
+
+// quote returns a JavaScript string literal for s.
+func quote(s string) string {
+	b, _ := json.Marshal(s)
+	return string(b)
+}
+
+func endsInReturn(stmts []generated.Stmt) bool {
+	if len(stmts) == 0 {
+		return false
+	}
+
+	_, ok := stmts[len(stmts)-1].(*generated.ReturnStmt)
+
+	return ok
+}
+
+func canonicalPath(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.EvalSymlinks(abs)
+}
+
+func (e *emitter) VisitBlockStmt(stmt *generated.BlockStmt) (interface{}, error) {
+	e.line("{")
+	if err := e.body(stmt); err != nil {
+		return nil, err
+	}
+	e.line("}")
+
+	return nil, nil
+}
+
+func (e *emitter) VisitIfStmt(stmt *generated.IfStmt) (interface{}, error) {
+	cond, err := e.expr(stmt.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	e.line("if (rt.truthy(%s)) {", cond)
+	if err := e.body(stmt.IfBranch); err != nil {
+		return nil, err
+	}
+
+	if stmt.ElseBranch != nil {
+		e.line("} else {")
+		if err := e.body(stmt.ElseBranch); err != nil {
+			return nil, err
+		}
+	}
+	e.line("}")
+
+	return nil, nil
+}
+
+func (e *emitter) VisitWhileStmt(stmt *generated.WhileStmt) (interface{}, error) {
+	cond, err := e.expr(stmt.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	e.line("while (rt.truthy(%s)) {", cond)
+	if err := e.body(stmt.Stmt); err != nil {
+		return nil, err
+	}
+	e.line("}")
+
+	return nil, nil
+}
+
+func (e *emitter) VisitExprStmt(stmt *generated.ExprStmt) (interface{}, error) {
+	code, err := e.expr(stmt.Expr)
+	if err != nil {
+		return nil, err
+	}
+
+	e.line("%s;", code)
+
+	return nil, nil
+}
+
+func (e *emitter) VisitPrintStmt(stmt *generated.PrintStmt) (interface{}, error) {
+	value, err := e.expr(stmt.Expr)
+	if err != nil {
+		return nil, err
+	}
+
+	e.line("rt.print(%s);", value)
+
+	return nil, nil
+}
+
+func (e *emitter) VisitVarStmt(stmt *generated.VarStmt) (interface{}, error) {
+	value := "null"
+	if stmt.Initializer != nil {
+		var err error
+		value, err = e.expr(stmt.Initializer)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	e.declare(stmt.Name, value)
+
+	return nil, nil
+}
+
+// VisitFunctionStmt declares a local function before creating it, so that
+// its body can refer to it.
+func (e *emitter) VisitFunctionStmt(stmt *generated.FunctionStmt) (interface{}, error) {
+	if len(e.scopes) == 0 {
+		code, err := e.function(stmt)
+		if err != nil {
+			return nil, err
+		}
+
+		e.line("m.define(%s, %s);", quote(stmt.Name.GetLexeme()), code)
+		return nil, nil
+	}
+
+	name := e.bind(stmt.Name)
+	code, err := e.function(stmt)
+	if err != nil {
+		return nil, err
+	}
+
+	e.line("let %s = %s;", name, code)
+
+	return nil, nil
+}
+
+// function returns the expression creating a function. Parameters the
+// call left unset take their default, evaluated before the parameter is
+// declared as the resolver has it, or are reported as missing.
+func (e *emitter) function(stmt *generated.FunctionStmt) (string, error) {
+	name := e.tok(stmt.Name)
+
+	params := make([]string, len(stmt.Params))
+	required := 0
+	for idx, param := range stmt.Params {
+		params[idx] = quote(param.GetLexeme())
+		if stmt.Defaults[idx] == nil {
+			required++
+		}
+	}
+
+	e.scopes = append(e.scopes, map[string]string{})
+	defer func() { e.scopes = e.scopes[:len(e.scopes)-1] }()
+
+	body, err := e.nested(func() error {
+		for idx, param := range stmt.Params {
+			if def := stmt.Defaults[idx]; def != nil {
+				value, err := e.expr(def)
+				if err != nil {
+					return err
+				}
+				e.line("if (p[%d] === rt.unset) {", idx)
+				e.line("  p[%d] = %s;", idx, value)
+				e.line("}")
+			} else {
+				e.line("if (p[%d] === rt.unset) {", idx)
+				e.line("  rt.missingArg(%s, %s);", e.tok(param), name)
+				e.line("}")
+			}
+
+			e.line("let %s = p[%d];", e.bind(param), idx)
+		}
+
+		if stmt.Rest != nil {
+			e.line("let %s = p[%d];", e.bind(stmt.Rest), len(stmt.Params))
+		}
+
+		if err := e.stmts(stmt.Body); err != nil {
+			return err
+		}
+
+		if !endsInReturn(stmt.Body) {
+			e.line("return null;")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("rt.fn(%s, [%s], %d, %t, (p) => {\n%s%s})",
+		name, strings.Join(params, ", "), required, stmt.Rest != nil, body, strings.Repeat("  ", e.depth)), nil
+}
+
+// VisitReturnStmt returns tail calls to Lox functions for rt to make once
+// the function returning has finished, so that deep tail recursion runs in
+// constant stack space as it does in the interpreter.
+func (e *emitter) VisitReturnStmt(stmt *generated.ReturnStmt) (interface{}, error) {
+	value := "null"
+	if call, ok := stmt.Value.(*generated.Call); ok && e.tails[call] {
+		var err error
+		value, err = e.call(call, "tailCall")
+		if err != nil {
+			return nil, err
+		}
+	} else if stmt.Value != nil {
+		var err error
+		value, err = e.expr(stmt.Value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	e.line("return %s;", value)
+
+	return nil, nil
+}
+
+// VisitImportStmt resolves the path of an imported module as the
+// interpreter does, relative to the importing module, and queues the
+// module to be emitted.
+func (e *emitter) VisitImportStmt(stmt *generated.ImportStmt) (interface{}, error) {
+	rel := stmt.Path.GetLiteral().(string)
+
+	p := rel
+	if !filepath.IsAbs(p) {
+		p = filepath.Join(filepath.Dir(e.path), p)
+	}
+
+	path, err := canonicalPath(p)
+	if err != nil {
+		return nil, lerr.NewSyntaxErrAt(lerr.SpanOf(stmt.Path), "", fmt.Sprintf("Cannot import '%s': %v.", rel, err))
+	}
+
+	idx, ok := e.modules[path]
+	if !ok {
+		idx = len(e.paths)
+		e.modules[path] = idx
+		e.paths = append(e.paths, path)
+	}
+
+	load := fmt.Sprintf("rt.import(%s, %s, module%d)", e.tok(stmt.Path), quote(path), idx)
+	if stmt.Alias != nil {
+		e.declare(stmt.Alias, load)
+		return nil, nil
+	}
+
+	mod := e.temp("mod")
+	e.line("const %s = %s;", mod, load)
+	for _, name := range stmt.Names {
+		e.declare(name, fmt.Sprintf("rt.member(%s, %s)", mod, e.tok(name)))
+	}
+
+	return nil, nil
+}
+
+func (e *emitter) VisitThrowStmt(stmt *generated.ThrowStmt) (interface{}, error) {
+	value, err := e.expr(stmt.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	e.line("throw rt.thrown(%s, %s);", e.tok(stmt.Keyword), value)
+
+	return nil, nil
+}
+
+// VisitTryStmt emits a JavaScript try statement, whose finally clause
+// takes precedence over how the others were left as it does in Lox. The
+// catch clause binds what rt.caught makes of the exception, in a scope
+// around that of its block as in the resolver.
+func (e *emitter) VisitTryStmt(stmt *generated.TryStmt) (interface{}, error) {
+	e.line("try {")
+	if err := e.body(generated.NewBlockStmt(stmt.Body)); err != nil {
+		return nil, err
+	}
+
+	if stmt.CatchName != nil {
+		exception := e.temp("e")
+		e.line("} catch (%s) {", exception)
+
+		e.depth++
+		e.scopes = append(e.scopes, map[string]string{})
+		e.line("let %s = rt.caught(%s);", e.bind(stmt.CatchName), exception)
+		e.line("{")
+		if err := e.body(generated.NewBlockStmt(stmt.CatchBody)); err != nil {
+			return nil, err
+		}
+		e.line("}")
+		e.scopes = e.scopes[:len(e.scopes)-1]
+		e.depth--
+	}
+
+	if len(stmt.FinallyBody) > 0 || stmt.CatchName == nil {
+		e.line("} finally {")
+		if err := e.body(generated.NewBlockStmt(stmt.FinallyBody)); err != nil {
+			return nil, err
+		}
+	}
+	e.line("}")
+
+	return nil, nil
+}
+
+// VisitMatchStmt emits the arms of a match in a labeled block that is left
+// once one of them runs, since an arm whose guard fails falls through to
+// the next.
+func (e *emitter) VisitMatchStmt(stmt *generated.MatchStmt) (interface{}, error) {
+	subject, err := e.expr(stmt.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	label := e.temp("match")
+	s := e.temp("subject")
+	e.line("%s: {", label)
+	e.depth++
+	e.line("const %s = %s;", s, subject)
+
+	for _, mc := range stmt.Cases {
+		err := e.arm(s, mc, func() error {
+			if _, err := mc.Body.Accept(e); err != nil {
+				return err
+			}
+			e.line("break %s;", label)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	e.depth--
+	e.line("}")
+
+	return nil, nil
+}
+
+// arm emits one arm of a match on the value in the constant s, calling
+// body to emit what it does once it is selected.
+func (e *emitter) arm(s string, mc *generated.MatchCase, body func() error) error {
+	e.scopes = append(e.scopes, map[string]string{})
+	defer func() { e.scopes = e.scopes[:len(e.scopes)-1] }()
+
+	e.line("{")
+	e.depth++
+	if mc.Binding != nil {
+		e.line("let %s = %s;", e.bind(mc.Binding), s)
+	}
+
+	var conds []string
+	if len(mc.Patterns) > 0 {
+		var matches []string
+		for _, pattern := range mc.Patterns {
+			p, err := e.expr(pattern)
+			if err != nil {
+				return err
+			}
+			matches = append(matches, fmt.Sprintf("rt.equal(%s, %s)", s, p))
+		}
+		conds = append(conds, strings.Join(matches, " || "))
+	}
+
+	if mc.Guard != nil {
+		guard, err := e.expr(mc.Guard)
+		if err != nil {
+			return err
+		}
+		conds = append(conds, fmt.Sprintf("rt.truthy(%s)", guard))
+	}
+
+	for _, cond := range conds {
+		e.line("if (%s) {", cond)
+		e.depth++
+	}
+
+	if err := body(); err != nil {
+		return err
+	}
+
+	for range conds {
+		e.depth--
+		e.line("}")
+	}
+
+	e.depth--
+	e.line("}")
+
+	return nil
+}
+
+// VisitTestStmt emits nothing, since tests only run under glox test.
+func (e *emitter) VisitTestStmt(stmt *generated.TestStmt) (interface{}, error) {
+	return nil, nil
+}
+
+func (e *emitter) VisitAssign(expr *generated.Assign) (interface{}, error) {
+	value, err := e.expr(expr.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	if !e.locals[expr] {
+		return fmt.Sprintf("m.set(%s, %s)", e.tok(expr.Name), value), nil
+	}
+
+	name, err := e.resolved(expr.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("(%s = %s)", name, value), nil
+}
+
+// VisitLogical emits an arrow function called in place with the value of
+// the left operand, since the right one may not be evaluated.
+func (e *emitter) VisitLogical(expr *generated.Logical) (interface{}, error) {
+	left, err := e.expr(expr.Left)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := e.expr(expr.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	if expr.Operator.GetType() == token.OR {
+		return fmt.Sprintf("((l) => (rt.truthy(l) ? l : %s))(%s)", right, left), nil
+	}
+
+	return fmt.Sprintf("((l) => (rt.truthy(l) ? %s : l))(%s)", right, left), nil
+}
+
+func (e *emitter) VisitBinary(expr *generated.Binary) (interface{}, error) {
+	left, err := e.expr(expr.Left)
+	if err != nil {
+		return nil, err
+	}
+
+	right, err := e.expr(expr.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("rt.binary(%s, %s, %s)", e.tok(expr.Operator), left, right), nil
+}
+
+func (e *emitter) VisitTernary(expr *generated.Ternary) (interface{}, error) {
+	cond, err := e.expr(expr.Condition)
+	if err != nil {
+		return nil, err
+	}
+
+	valueTrue, err := e.expr(expr.ValueTrue)
+	if err != nil {
+		return nil, err
+	}
+
+	valueFalse, err := e.expr(expr.ValueFalse)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("(rt.truthy(%s) ? %s : %s)", cond, valueTrue, valueFalse), nil
+}
+
+func (e *emitter) VisitGrouping(expr *generated.Grouping) (interface{}, error) {
+	return e.expr(expr.Expression)
+}
+
+// VisitLiteral emits integers as BigInts and floats as numbers, which is
+// how rt tells them apart.
+func (e *emitter) VisitLiteral(expr *generated.Literal) (interface{}, error) {
+	switch v := expr.Value.(type) {
+	case nil:
+		return "null", nil
+	case bool:
+		return strconv.FormatBool(v), nil
+	case string:
+		return quote(v), nil
+	case int64:
+		return fmt.Sprintf("%dn", v), nil
+	case float64:
+		switch {
+		case math.IsNaN(v):
+			return "NaN", nil
+		case math.IsInf(v, 0):
+			return fmt.Sprintf("(%d * Infinity)", int(math.Copysign(1, v))), nil
+		}
+		return strconv.FormatFloat(v, 'g', -1, 64), nil
+	}
+
+	return nil, fmt.Errorf("cannot emit a literal of type %T", expr.Value)
+}
+
+func (e *emitter) VisitUnary(expr *generated.Unary) (interface{}, error) {
+	right, err := e.expr(expr.Right)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("rt.unary(%s, %s)", e.tok(expr.Operator), right), nil
+}
+
+func (e *emitter) VisitCall(expr *generated.Call) (interface{}, error) {
+	return e.call(expr, "call")
+}
+
+// call emits a call through the rt function called fn, or its variant
+// taking names for a call naming arguments.
+func (e *emitter) call(expr *generated.Call, fn string) (string, error) {
+	callee, err := e.expr(expr.Callee)
+	if err != nil {
+		return "", err
+	}
+
+	args := []string{callee, e.tok(expr.Paren)}
+
+	named := false
+	names := make([]string, len(expr.Names))
+	for idx, name := range expr.Names {
+		names[idx] = "null"
+		if name != nil {
+			names[idx] = e.tok(name)
+			named = true
+		}
+	}
+
+	if named {
+		fn += "Named"
+		args = append(args, "["+strings.Join(names, ", ")+"]")
+	}
+
+	for _, arg := range expr.Arguments {
+		code, err := e.expr(arg)
+		if err != nil {
+			return "", err
+		}
+		args = append(args, code)
+	}
+
+	return fmt.Sprintf("rt.%s(%s)", fn, strings.Join(args, ", ")), nil
+}
+
+func (e *emitter) VisitVarExpr(expr *generated.VarExpr) (interface{}, error) {
+	if !e.locals[expr] {
+		return fmt.Sprintf("m.get(%s)", e.tok(expr.Name)), nil
+	}
+
+	return e.resolved(expr.Name)
+}
+
+func (e *emitter) VisitGet(expr *generated.Get) (interface{}, error) {
+	object, err := e.expr(expr.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("rt.get(%s, %s)", object, e.tok(expr.Name)), nil
+}
+
+// VisitMatchExpr emits an arrow function called in place, returning the
+// value of the arm selected.
+func (e *emitter) VisitMatchExpr(expr *generated.MatchExpr) (interface{}, error) {
+	subject, err := e.expr(expr.Subject)
+	if err != nil {
+		return nil, err
+	}
+
+	s := e.temp("subject")
+	arms, err := e.nested(func() error {
+		e.line("const %s = %s;", s, subject)
+
+		for _, mc := range expr.Cases {
+			err := e.arm(s, mc, func() error {
+				value, err := e.expr(mc.Value)
+				if err != nil {
+					return err
+				}
+				e.line("return %s;", value)
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+		}
+
+		e.line("rt.fail(%s, %s);", e.tok(expr.Keyword), quote("No match case for value."))
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return fmt.Sprintf("(() => {\n%s%s})()", arms, strings.Repeat("  ", e.depth)), nil
+}
@@ -0,0 +1,56 @@
+package emitjs
+
+import (
+	"glox/internal/corpustest"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// main runs the compiled script under node or deno, as glox would run the
+// script.
+const main = `import run from "./script.mjs";
+
+const deno = typeof Deno !== "undefined";
+const status = run({ args: deno ? Deno.args : process.argv.slice(2) });
+if (deno) {
+  Deno.exit(status);
+}
+process.exitCode = status;
+`
+
+// TestCorpus compiles the scripts of the corpus to JavaScript and expects
+// the programs to print what the interpreter prints and exit as it does.
+// It is skipped without node or deno; emitjs/difftest.sh runs the same
+// comparison on any script.
+func TestCorpus(t *testing.T) {
+	if testing.Short() {
+		t.Skip("runs a JavaScript engine")
+	}
+
+	engine := []string{"node"}
+	if _, err := exec.LookPath("node"); err != nil {
+		if _, err := exec.LookPath("deno"); err != nil {
+			t.Skip("no JavaScript engine found")
+		}
+		engine = []string{"deno", "run"}
+	}
+
+	corpustest.Compare(t, corpustest.Scripts(t), func(t *testing.T, script string) *exec.Cmd {
+		src, err := Emit(script)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dir := t.TempDir()
+		if err := os.WriteFile(filepath.Join(dir, "script.mjs"), src, 0o644); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(filepath.Join(dir, "main.mjs"), []byte(main), 0o644); err != nil {
+			t.Fatal(err)
+		}
+
+		return exec.Command(engine[0], append(engine[1:], filepath.Join(dir, "main.mjs"))...)
+	})
+}
@@ -0,0 +1,1623 @@
+// rt is the runtime of Lox programs compiled to JavaScript by glox build.
+//
+// Values map onto JavaScript values: nil is null, booleans and strings are
+// themselves, integers are BigInts kept to 64 bits, so that they wrap
+// around as in the interpreter, and floats are numbers. Lists, maps,
+// functions and modules are instances of the classes below.
+//
+// Runtime errors and thrown values are raised as JavaScript exceptions,
+// carrying what the interpreter's errors carry, so that catch clauses and
+// the report of an uncaught error read the same.
+const rt = (() => {
+  class RuntimeError {
+    constructor(token, message) {
+      this.token = token;
+      this.message = message;
+      this.trace = [];
+    }
+
+    get line() {
+      return this.token === null ? 0 : this.token.line;
+    }
+
+    toString() {
+      return this.message;
+    }
+  }
+
+  class Thrown {
+    constructor(keyword, value) {
+      this.keyword = keyword;
+      this.value = value;
+      this.trace = [];
+    }
+
+    toString() {
+      return `[line ${this.keyword.line}] Uncaught exception: ${goValue(this.value)}`;
+    }
+  }
+
+  function fail(token, message) {
+    throw new RuntimeError(token, message);
+  }
+
+  function tok(type, lexeme, line) {
+    return { type, lexeme, line };
+  }
+
+  class List {
+    constructor(elements) {
+      this.elements = elements;
+    }
+  }
+
+  // Dict keeps its keys in insertion order, as Map does.
+  class Dict {
+    constructor() {
+      this.entries = new Map();
+    }
+  }
+
+  const variadic = -1;
+
+  class Native {
+    constructor(name, min, max, fn, display = `<native fn ${name}>`) {
+      this.name = name;
+      this.min = min;
+      this.max = max;
+      this.fn = fn;
+      this.display = display;
+    }
+
+    arity() {
+      return [this.min, this.max];
+    }
+
+    toString() {
+      return this.display;
+    }
+  }
+
+  // unset marks the parameters a call did not bind, which compiled
+  // functions give their default or report as missing.
+  const unset = Symbol("unset");
+
+  // LoxFunction is a compiled Lox function. Its body gets the values of
+  // its parameters, followed by the list of surplus arguments if it has a
+  // rest parameter, and closes over the variables of the code declaring it.
+  class LoxFunction {
+    constructor(name, params, required, rest, body) {
+      this.name = name;
+      this.params = params;
+      this.required = required;
+      this.rest = rest;
+      this.body = body;
+    }
+
+    arity() {
+      return [this.required, this.rest ? variadic : this.params.length];
+    }
+
+    toString() {
+      return `<fn ${this.name.lexeme}>`;
+    }
+  }
+
+  class Env {
+    constructor(enclosing) {
+      this.values = new Map();
+      this.enclosing = enclosing;
+    }
+
+    define(name, value) {
+      this.values.set(name, value);
+    }
+
+    get(name) {
+      for (let env = this; env !== null; env = env.enclosing) {
+        if (env.values.has(name.lexeme)) {
+          return env.values.get(name.lexeme);
+        }
+      }
+
+      fail(name, `Undefined variable '${name.lexeme}'.`);
+    }
+
+    set(name, value) {
+      for (let env = this; env !== null; env = env.enclosing) {
+        if (env.values.has(name.lexeme)) {
+          env.values.set(name.lexeme, value);
+          return;
+        }
+      }
+
+      fail(name, `Undefined variable ${name.lexeme}`);
+    }
+  }
+
+  // Module is a compiled Lox source file and the environment holding its
+  // globals.
+  class Module {
+    constructor(path, env) {
+      this.path = path;
+      this.env = env;
+      this.loading = true;
+    }
+
+    define(name, value) {
+      this.env.define(name, value);
+    }
+
+    get(name) {
+      return this.env.get(name);
+    }
+
+    set(name, value) {
+      this.env.set(name, value);
+      return value;
+    }
+
+    toString() {
+      const base = this.path.slice(this.path.lastIndexOf("/") + 1);
+      const dot = base.lastIndexOf(".");
+
+      return `<module ${dot > 0 ? base.slice(0, dot) : base}>`;
+    }
+  }
+
+  // state is the state of the program running, set up afresh by main.
+  let state = null;
+
+  const isInt = (v) => typeof v === "bigint";
+  const isNumber = (v) => typeof v === "bigint" || typeof v === "number";
+  const wrap = (n) => BigInt.asIntN(64, n);
+
+  function truthy(v) {
+    return v !== null && v !== false;
+  }
+
+  function equal(a, b) {
+    if (isNumber(a) && isNumber(b)) {
+      return isInt(a) && isInt(b) ? a === b : Number(a) === Number(b);
+    }
+
+    return a === b;
+  }
+
+  function binary(op, left, right) {
+    switch (op.type) {
+      case "PLUS":
+        if (typeof left === "string" && typeof right === "string") {
+          return left + right;
+        }
+
+        if (!isNumber(left) || !isNumber(right)) {
+          fail(op, "Operands must be two numbers or two strings.");
+        }
+        break;
+      case "BANG_EQUAL":
+        return !equal(left, right);
+      case "EQUAL_EQUAL":
+        return equal(left, right);
+    }
+
+    return arithmetic(op, left, right);
+  }
+
+  function arithmetic(op, left, right) {
+    if (!isNumber(left) || !isNumber(right)) {
+      fail(op, "Operand(s) must be a number(s).");
+    }
+
+    if (isInt(left) && isInt(right)) {
+      return intArithmetic(op, left, right);
+    }
+
+    switch (op.type) {
+      case "AMPERSAND":
+      case "PIPE":
+      case "CARET":
+      case "LESS_LESS":
+      case "GREATER_GREATER":
+        fail(op, "Operands must be integers.");
+    }
+
+    return floatArithmetic(op, Number(left), Number(right));
+  }
+
+  function intArithmetic(op, l, r) {
+    switch (op.type) {
+      case "PLUS":
+        return wrap(l + r);
+      case "MINUS":
+        return wrap(l - r);
+      case "STAR":
+        return wrap(l * r);
+      case "SLASH":
+        return Number(l) / Number(r);
+      case "TILDE_SLASH":
+        if (r === 0n) {
+          fail(op, "Division by zero.");
+        }
+        return wrap(l / r);
+      case "PERCENT":
+        if (r === 0n) {
+          fail(op, "Division by zero.");
+        }
+        return l % r;
+      case "AMPERSAND":
+        return l & r;
+      case "PIPE":
+        return l | r;
+      case "CARET":
+        return l ^ r;
+      case "LESS_LESS":
+        if (r < 0n) {
+          fail(op, "Negative shift count.");
+        }
+        return r >= 64n ? 0n : wrap(l << r);
+      case "GREATER_GREATER":
+        if (r < 0n) {
+          fail(op, "Negative shift count.");
+        }
+        return l >> (r >= 64n ? 64n : r);
+      case "GREATER":
+        return l > r;
+      case "GREATER_EQUAL":
+        return l >= r;
+      case "LESS":
+        return l < r;
+      case "LESS_EQUAL":
+        return l <= r;
+    }
+
+    fail(op, "Unknown operator.");
+  }
+
+  function floatArithmetic(op, l, r) {
+    switch (op.type) {
+      case "PLUS":
+        return l + r;
+      case "MINUS":
+        return l - r;
+      case "STAR":
+        return l * r;
+      case "SLASH":
+        return l / r;
+      case "TILDE_SLASH":
+        return Math.trunc(l / r);
+      case "PERCENT":
+        return l % r;
+      case "GREATER":
+        return l > r;
+      case "GREATER_EQUAL":
+        return l >= r;
+      case "LESS":
+        return l < r;
+      case "LESS_EQUAL":
+        return l <= r;
+    }
+
+    fail(op, "Unknown operator.");
+  }
+
+  function unary(op, right) {
+    switch (op.type) {
+      case "MINUS":
+        if (isInt(right)) {
+          return wrap(-right);
+        }
+        if (typeof right === "number") {
+          return -right;
+        }
+        fail(op, "Operand(s) must be a number(s).");
+      case "TILDE":
+        if (!isInt(right)) {
+          fail(op, "Operand must be an integer.");
+        }
+        return ~right;
+      case "BANG":
+        return !truthy(right);
+    }
+
+    return null;
+  }
+
+  // floatToInt converts f to an integer when it is integral and in range.
+  function floatToInt(f) {
+    if (!Number.isInteger(f) || f < -(2 ** 63) || f >= 2 ** 63) {
+      return f;
+    }
+
+    return BigInt(f);
+  }
+
+  // stringify spells a value as print does. seen holds the collections
+  // being spelled, which spell as [...] or {...} when reached again.
+  function stringify(v, seen = new Set()) {
+    if (v === null) {
+      return "nil";
+    }
+
+    switch (typeof v) {
+      case "number":
+        return formatFloat(v);
+      case "bigint":
+      case "boolean":
+      case "string":
+        return String(v);
+    }
+
+    if (v instanceof List || v instanceof Dict) {
+      if (seen.has(v)) {
+        return v instanceof List ? "[...]" : "{...}";
+      }
+
+      seen.add(v);
+      try {
+        if (v instanceof List) {
+          return `[${v.elements.map((e) => element(e, seen)).join(", ")}]`;
+        }
+
+        return `{${[...v.entries].map(([k, e]) => `"${k}": ${element(e, seen)}`).join(", ")}}`;
+      } finally {
+        seen.delete(v);
+      }
+    }
+
+    return String(v);
+  }
+
+  // element shows a value inside a list or map, where strings are quoted.
+  function element(v, seen) {
+    return typeof v === "string" ? `"${v}"` : stringify(v, seen);
+  }
+
+  // formatFloat spells floats as the interpreter does, which is as
+  // JavaScript does but for the special values.
+  function formatFloat(f) {
+    if (Number.isNaN(f)) {
+      return "nan";
+    }
+    if (f === Infinity) {
+      return "inf";
+    }
+    if (f === -Infinity) {
+      return "-inf";
+    }
+    if (Object.is(f, -0)) {
+      return "-0";
+    }
+
+    return String(f);
+  }
+
+  // goFloat spells a float as Go's %v and strconv's 'g' format do, which
+  // switch to exponent notation from an exponent of 6.
+  function goFloat(f) {
+    if (Number.isNaN(f)) {
+      return "NaN";
+    }
+    if (!Number.isFinite(f)) {
+      return f > 0 ? "+Inf" : "-Inf";
+    }
+    if (Object.is(f, -0)) {
+      return "-0";
+    }
+
+    const [mantissa, exponent] = f.toExponential().split("e");
+    const exp = Number(exponent);
+    if (exp < -4 || exp >= 6) {
+      return `${mantissa}e${exp < 0 ? "-" : "+"}${String(Math.abs(exp)).padStart(2, "0")}`;
+    }
+
+    return String(f);
+  }
+
+  // goValue shows a value in the message of an uncaught exception, which
+  // the interpreter formats with Go's %v rather than as print does.
+  function goValue(v) {
+    if (v === null) {
+      return "<nil>";
+    }
+    if (typeof v === "number") {
+      return goFloat(v);
+    }
+    if (v instanceof List) {
+      return `&{[${v.elements.map(goValue).join(" ")}]}`;
+    }
+    if (v instanceof Dict) {
+      const keys = [...v.entries.keys()];
+      const entries = [...keys].sort().map((k) => `${k}:${goValue(v.entries.get(k))}`);
+
+      return `&{[${keys.join(" ")}] map[${entries.join(" ")}]}`;
+    }
+
+    return String(v);
+  }
+
+  function print(v) {
+    state.print(stringify(v));
+  }
+
+  // get returns the property called name of a module or map.
+  function get(object, name) {
+    if (object instanceof Module) {
+      if (!object.env.values.has(name.lexeme)) {
+        fail(name, `Undefined property '${name.lexeme}' on ${object}.`);
+      }
+
+      return object.env.values.get(name.lexeme);
+    }
+
+    if (object instanceof Dict) {
+      return object.entries.has(name.lexeme) ? object.entries.get(name.lexeme) : null;
+    }
+
+    fail(name, "Only modules and maps have properties.");
+  }
+
+  // thrown returns the exception a throw statement at keyword raises.
+  function thrown(keyword, value) {
+    return new Thrown(keyword, value);
+  }
+
+  // caught returns the value a catch clause binds for an exception, and
+  // raises again one Lox cannot catch. Runtime errors are turned into a map
+  // holding their message, line and trace.
+  function caught(e) {
+    if (e instanceof Thrown) {
+      return e.value;
+    }
+
+    if (!(e instanceof RuntimeError)) {
+      throw e;
+    }
+
+    const d = new Dict();
+    d.entries.set("type", "RuntimeError");
+    d.entries.set("message", e.message);
+    d.entries.set("line", BigInt(e.line));
+    d.entries.set("trace", new List([...e.trace]));
+
+    return d;
+  }
+
+  // addFrame records that e propagated out of a call to callee made on the
+  // line of paren.
+  function addFrame(e, callee, paren) {
+    const frame = `${callee} [line ${paren.line}]`;
+
+    if (e instanceof Thrown) {
+      e.trace.push(frame);
+    } else if (e instanceof RuntimeError) {
+      if (e.token === null) {
+        e.token = paren;
+      }
+      e.trace.push(frame);
+    }
+  }
+
+  function fn(name, params, required, rest, body) {
+    return new LoxFunction(name, params, required, rest, body);
+  }
+
+  // missingArg reports that the parameter param of the function declared
+  // as name was not given.
+  function missingArg(param, name) {
+    fail(param, `Missing argument '${param.lexeme}' to <fn ${name.lexeme}>.`);
+  }
+
+  function checkArity(callee, paren, got) {
+    const [min, max] = callee.arity();
+    if (got >= min && (max === variadic || got <= max)) {
+      return;
+    }
+
+    if (min === max) {
+      fail(paren, `Expected ${min} arguments but got ${got}.`);
+    } else if (got < min) {
+      fail(paren, `Expected at least ${min} arguments but got ${got}.`);
+    }
+
+    fail(paren, `Expected at most ${max} arguments but got ${got}.`);
+  }
+
+  // TailCall is a call to a Lox function in tail position, returned by
+  // the function making it for invoke to make.
+  class TailCall {
+    constructor(callee, paren, args, names, named) {
+      this.callee = callee;
+      this.paren = paren;
+      this.args = args;
+      this.names = names;
+      this.named = named;
+    }
+  }
+
+  // prepare checks a call to callee at paren as the interpreter does,
+  // separating positional arguments from named ones. names holds the name
+  // of each named argument, and null for the positional ones.
+  function prepare(callee, paren, names, args) {
+    if (!(callee instanceof LoxFunction || callee instanceof Native)) {
+      fail(paren, "Can only call functions and classes.");
+    }
+
+    const c = new TailCall(callee, paren, args, null, null);
+    if (names !== null) {
+      c.args = args.filter((_, idx) => names[idx] === null);
+      c.names = names.filter((name) => name !== null);
+      c.named = args.filter((_, idx) => names[idx] !== null);
+    }
+
+    if (c.names === null || c.names.length === 0) {
+      c.names = null;
+      checkArity(callee, paren, c.args.length);
+    } else if (!(callee instanceof LoxFunction)) {
+      fail(paren, `${callee} does not take named arguments.`);
+    }
+
+    return c;
+  }
+
+  function call(callee, paren, ...args) {
+    return callNamed(callee, paren, null, ...args);
+  }
+
+  // callNamed calls callee at paren. names holds the name of each named
+  // argument, and null for the positional ones.
+  function callNamed(callee, paren, names, ...args) {
+    const c = prepare(callee, paren, names, args);
+
+    try {
+      if (callee instanceof Native) {
+        return callee.fn(c.args);
+      }
+
+      return invoke(callee, c.args, c.names, c.named);
+    } catch (e) {
+      addFrame(e, callee, paren);
+      throw e;
+    }
+  }
+
+  // tailCall returns a call in tail position from a compiled function. A
+  // call to a Lox function is made by invoke once the function has
+  // returned, so that deep tail recursion does not grow the stack; any
+  // other is made at once.
+  function tailCall(callee, paren, ...args) {
+    return tailCallNamed(callee, paren, null, ...args);
+  }
+
+  function tailCallNamed(callee, paren, names, ...args) {
+    const c = prepare(callee, paren, names, args);
+    if (callee instanceof LoxFunction) {
+      return c;
+    }
+
+    try {
+      return callee.fn(c.args);
+    } catch (e) {
+      addFrame(e, callee, paren);
+      throw e;
+    }
+  }
+
+  // invoke calls f, then any function it tail calls, and so on, until one
+  // returns a value. Tail calls made this way are not in the trace of an
+  // error, except for the one the error was raised in.
+  function invoke(f, args, names, named) {
+    let paren = null;
+    for (;;) {
+      let value;
+      if (paren === null) {
+        value = bind(f, args, names, named);
+      } else {
+        try {
+          value = bind(f, args, names, named);
+        } catch (e) {
+          addFrame(e, f, paren);
+          throw e;
+        }
+      }
+
+      if (!(value instanceof TailCall)) {
+        return value;
+      }
+
+      ({ callee: f, paren, args, names, named } = value);
+    }
+  }
+
+  // bind binds positional and named arguments to the parameters of f and
+  // runs its body, as the interpreter does for Lox functions.
+  function bind(f, args, names, named) {
+    if (!f.rest && args.length > f.params.length) {
+      fail(f.name, `Expected at most ${f.params.length} arguments but got ${args.length}.`);
+    }
+
+    const params = f.params.map((_, idx) => (idx < args.length ? args[idx] : unset));
+
+    if (names !== null) {
+      names.forEach((name, idx) => {
+        const pos = f.params.indexOf(name.lexeme);
+        if (pos < 0) {
+          fail(name, `${f} has no parameter '${name.lexeme}'.`);
+        }
+
+        if (params[pos] !== unset) {
+          fail(name, `Argument '${name.lexeme}' given more than once.`);
+        }
+
+        params[pos] = named[idx];
+      });
+    }
+
+    if (f.rest) {
+      params.push(new List(args.slice(f.params.length)));
+    }
+
+    return f.body(params);
+  }
+
+  // callValue calls a function from a native, without a call site.
+  function callValue(callee, args) {
+    if (callee instanceof Native) {
+      return callee.fn(args);
+    }
+
+    return invoke(callee, args, null, null);
+  }
+
+  // import returns the module compiled from the file at path, running
+  // body to load it the first time it is imported. pathTok is the path in
+  // the import statement.
+  function importModule(pathTok, path, body) {
+    const loaded = state.modules.get(path);
+    if (loaded !== undefined) {
+      if (loaded.loading) {
+        fail(pathTok, `Import cycle: ${[...state.importing, path].join(" -> ")}.`);
+      }
+
+      return loaded;
+    }
+
+    const m = new Module(path, new Env(state.builtins));
+    state.modules.set(path, m);
+
+    state.importing.push(path);
+    try {
+      body(m);
+      m.loading = false;
+    } finally {
+      state.importing.pop();
+
+      // A module that failed to load is run again by the next import.
+      if (m.loading) {
+        state.modules.delete(path);
+      }
+    }
+
+    return m;
+  }
+
+  // member returns the definition called name in module m, for an import
+  // statement listing names.
+  function member(m, name) {
+    if (!m.env.values.has(name.lexeme)) {
+      fail(name, `Module ${m} has no definition '${name.lexeme}'.`);
+    }
+
+    return m.env.values.get(name.lexeme);
+  }
+
+  // main runs the main module of a compiled program, which was compiled
+  // from the script at path, and returns the exit status glox would have:
+  // 70 if it raised an error, which is reported like the interpreter does.
+  //
+  // options.print is given each line printed, and defaults to
+  // console.log. options.args are the script's arguments, and
+  // options.readLine returns the next line of input, or null at its end.
+  function main(options, path, body) {
+    state = {
+      print: options.print ?? ((line) => console.log(line)),
+      readLine: options.readLine ?? (() => null),
+      random: Math.random,
+      modules: new Map(),
+      importing: [path],
+      builtins: null,
+    };
+    state.builtins = builtins(options.args ?? []);
+
+    const m = new Module(path, new Env(state.builtins));
+    state.modules.set(path, m);
+
+    try {
+      body(m);
+    } catch (e) {
+      if (!(e instanceof RuntimeError || e instanceof Thrown)) {
+        throw e;
+      }
+
+      state.print(`Error while interpreting : ${e}`);
+      return 70;
+    }
+
+    return 0;
+  }
+
+  function argErr(name, msg) {
+    return new RuntimeError(null, `${name}: ${msg}`);
+  }
+
+  // mapCase changes the case of s one code point at a time, as Go's
+  // strings.ToUpper and strings.ToLower do. JavaScript applies special
+  // casing, mapping "ß" to "SS" and "İ" to "i̇", so code points that map to
+  // several are left alone, apart from those with a simple mapping of their
+  // own, listed in table.
+  function mapCase(s, method, table) {
+    if (/^[\x00-\x7f]*$/.test(s)) {
+      return s[method]();
+    }
+
+    let out = "";
+    for (const c of s) {
+      const mapped = c[method]();
+      const cp = c.codePointAt(0);
+      if (table.has(cp)) {
+        out += String.fromCodePoint(table.get(cp));
+      } else if (mapped.length === 1 || (mapped.length === 2 && mapped.codePointAt(0) > 0xffff)) {
+        out += mapped;
+      } else {
+        out += c;
+      }
+    }
+
+    return out;
+  }
+
+  // upperCase and lowerCase hold the simple case mappings of the code
+  // points whose special casing maps them to several.
+  const upperCase = new Map([
+    ...[0x1f80, 0x1f90, 0x1fa0].flatMap((base) => [0, 1, 2, 3, 4, 5, 6, 7].map((i) => [base + i, base + i + 8])),
+    [0x1fb3, 0x1fbc],
+    [0x1fc3, 0x1fcc],
+    [0x1ff3, 0x1ffc],
+  ]);
+  const lowerCase = new Map([[0x130, 0x69]]);
+
+  function stringArg(name, args, idx) {
+    if (typeof args[idx] !== "string") {
+      throw argErr(name, `argument ${idx + 1} must be a string.`);
+    }
+
+    return args[idx];
+  }
+
+  function numberArg(name, args, idx) {
+    if (!isNumber(args[idx])) {
+      throw argErr(name, `argument ${idx + 1} must be a number.`);
+    }
+
+    return Number(args[idx]);
+  }
+
+  // intArg accepts an integer, or a float with an integral value.
+  function intArg(name, args, idx) {
+    const n = numberArg(name, args, idx);
+    if (!Number.isInteger(n)) {
+      throw argErr(name, `argument ${idx + 1} must be an integer.`);
+    }
+
+    return n;
+  }
+
+  function listArg(name, args, idx) {
+    if (!(args[idx] instanceof List)) {
+      throw argErr(name, `argument ${idx + 1} must be a list.`);
+    }
+
+    return args[idx];
+  }
+
+  function dictArg(name, args, idx) {
+    if (!(args[idx] instanceof Dict)) {
+      throw argErr(name, `argument ${idx + 1} must be a map.`);
+    }
+
+    return args[idx];
+  }
+
+  // builtins returns the environment holding the natives, which strings
+  // count in characters as in the interpreter.
+  function builtins(args) {
+    const b = new Env(null);
+    const define = (name, min, max, fn) => b.define(name, new Native(name, min, max, fn));
+
+    b.define("clock", new Native("clock", 0, 0, () => Date.now(), "<native fn>"));
+    b.define("args", new List([...args]));
+    defineStrings(define);
+    defineMath(b, define);
+    defineRandom(define);
+    defineIO(define);
+    defineCollections(define);
+    defineJSON(define);
+    defineAssert(define);
+
+    return b;
+  }
+
+  function defineStrings(define) {
+    define("len", 1, 1, ([v]) => {
+      if (typeof v === "string") {
+        return BigInt([...v].length);
+      }
+      if (v instanceof List) {
+        return BigInt(v.elements.length);
+      }
+      if (v instanceof Dict) {
+        return BigInt(v.entries.size);
+      }
+
+      throw argErr("len", "argument must be a string, a list or a map.");
+    });
+
+    define("substr", 3, 3, (args) => {
+      const runes = [...stringArg("substr", args, 0)];
+      const [start, end] = sliceBounds("substr", args, 1, runes.length);
+
+      return runes.slice(start, end).join("");
+    });
+
+    define("indexOf", 2, 2, (args) => indexOf(args));
+
+    define("contains", 2, 2, (args) => {
+      try {
+        return indexOf(args) >= 0n;
+      } catch (e) {
+        if (!(e instanceof RuntimeError)) {
+          throw e;
+        }
+
+        throw argErr("contains", "arguments must be a string and a substring, or a list and a value.");
+      }
+    });
+
+    define("split", 2, 2, (args) => {
+      const s = stringArg("split", args, 0);
+      const sep = stringArg("split", args, 1);
+
+      return new List(sep === "" ? [...s] : s.split(sep));
+    });
+
+    define("join", 2, 2, (args) => {
+      const l = listArg("join", args, 0);
+      const sep = stringArg("join", args, 1);
+
+      return l.elements.map((e) => stringify(e)).join(sep);
+    });
+
+    define("upper", 1, 1, (args) => mapCase(stringArg("upper", args, 0), "toUpperCase", upperCase));
+    define("lower", 1, 1, (args) => mapCase(stringArg("lower", args, 0), "toLowerCase", lowerCase));
+    define("trim", 1, 1, (args) => stringArg("trim", args, 0).trim());
+
+    define("replace", 3, 3, (args) => {
+      const s = stringArg("replace", args, 0);
+      const old = stringArg("replace", args, 1);
+      const repl = stringArg("replace", args, 2);
+
+      if (old === "") {
+        return [repl, ...[...s].flatMap((c) => [c, repl])].join("");
+      }
+
+      return s.split(old).join(repl);
+    });
+
+    define("startsWith", 2, 2, (args) => stringArg("startsWith", args, 0).startsWith(stringArg("startsWith", args, 1)));
+    define("endsWith", 2, 2, (args) => stringArg("endsWith", args, 0).endsWith(stringArg("endsWith", args, 1)));
+
+    define("charAt", 2, 2, (args) => {
+      const runes = [...stringArg("charAt", args, 0)];
+      let idx = intArg("charAt", args, 1);
+      if (idx < 0) {
+        idx += runes.length;
+      }
+
+      if (idx < 0 || idx >= runes.length) {
+        throw argErr("charAt", `index ${idx} out of bounds for length ${runes.length}.`);
+      }
+
+      return runes[idx];
+    });
+
+    define("ord", 1, 1, (args) => {
+      const runes = [...stringArg("ord", args, 0)];
+      if (runes.length !== 1) {
+        throw argErr("ord", "argument must be a single character.");
+      }
+
+      return BigInt(runes[0].codePointAt(0));
+    });
+
+    define("chr", 1, 1, (args) => {
+      const n = intArg("chr", args, 0);
+      if (n < 0 || n > 0x10ffff || (n >= 0xd800 && n <= 0xdfff)) {
+        throw argErr("chr", `${n} is not a valid code point.`);
+      }
+
+      return String.fromCodePoint(n);
+    });
+
+    define("str", 1, 1, ([v]) => stringify(v));
+
+    // num parses a number, yielding an integer when the string has no
+    // fractional part or exponent, and nil when it is not a number.
+    define("num", 1, 1, (args) => {
+      const s = stringArg("num", args, 0).trim();
+
+      if (/^[+-]?\d+$/.test(s)) {
+        const n = BigInt(s);
+        if (n === BigInt.asIntN(64, n)) {
+          return n;
+        }
+      }
+
+      switch (s) {
+        case "nan":
+          return NaN;
+        case "inf":
+          return Infinity;
+        case "-inf":
+          return -Infinity;
+      }
+
+      if (!/^[+-]?(\d+\.?\d*|\.\d+)([eE][+-]?\d+)?$/.test(s)) {
+        return null;
+      }
+
+      return Number(s);
+    });
+  }
+
+  function sliceBounds(name, args, idx, length) {
+    let start = intArg(name, args, idx);
+    let end = intArg(name, args, idx + 1);
+
+    if (start < 0) {
+      start += length;
+    }
+    if (end < 0) {
+      end += length;
+    }
+
+    if (start < 0 || end > length || start > end) {
+      throw argErr(name, `range [${start}, ${end}) out of bounds for length ${length}.`);
+    }
+
+    return [start, end];
+  }
+
+  function indexOf(args) {
+    if (args[0] instanceof List) {
+      return BigInt(args[0].elements.findIndex((e) => equal(e, args[1])));
+    }
+
+    const s = stringArg("indexOf", args, 0);
+    const sub = stringArg("indexOf", args, 1);
+
+    const idx = s.indexOf(sub);
+    if (idx < 0) {
+      return -1n;
+    }
+
+    return BigInt([...s.slice(0, idx)].length);
+  }
+
+  function defineMath(b, define) {
+    b.define("pi", Math.PI);
+    b.define("inf", Infinity);
+    b.define("nan", NaN);
+
+    // Rounding natives return integers whenever the result fits in one.
+    const rounding = (name, f) =>
+      define(name, 1, 1, (args) => (isInt(args[0]) ? args[0] : floatToInt(f(numberArg(name, args, 0)))));
+    const unaryMath = (name, f) => define(name, 1, 1, (args) => f(numberArg(name, args, 0)));
+    const binaryMath = (name, f) => define(name, 2, 2, (args) => f(numberArg(name, args, 0), numberArg(name, args, 1)));
+
+    rounding("floor", Math.floor);
+    rounding("ceil", Math.ceil);
+    // Go rounds halves away from zero.
+    rounding("round", (x) => Math.sign(x) * Math.round(Math.abs(x)));
+    unaryMath("sqrt", Math.sqrt);
+
+    define("abs", 1, 1, (args) => {
+      if (isInt(args[0])) {
+        return args[0] < 0n ? wrap(-args[0]) : args[0];
+      }
+
+      return Math.abs(numberArg("abs", args, 0));
+    });
+
+    unaryMath("sin", Math.sin);
+    unaryMath("cos", Math.cos);
+    unaryMath("log", Math.log);
+    unaryMath("exp", Math.exp);
+
+    // Go's Pow has 1 and -1 to any infinite power be 1, as C does.
+    binaryMath("pow", (x, y) => (x === 1 || (x === -1 && Math.abs(y) === Infinity) ? 1 : x ** y));
+    binaryMath("atan2", Math.atan2);
+
+    // min and max compare integers exactly and return them as integers; a
+    // nan argument makes the result nan.
+    const extreme = (name, largest) =>
+      define(name, 1, variadic, (args) => {
+        let best = null;
+        for (let idx = 0; idx < args.length; idx++) {
+          if (Number.isNaN(numberArg(name, args, idx))) {
+            return NaN;
+          }
+
+          if (best === null || numberLess(args[idx], best) !== largest) {
+            best = args[idx];
+          }
+        }
+
+        return best;
+      });
+
+    extreme("min", false);
+    extreme("max", true);
+
+    define("isNaN", 1, 1, ([x]) => typeof x === "number" && Number.isNaN(x));
+  }
+
+  function numberLess(a, b) {
+    if (isInt(a) && isInt(b)) {
+      return a < b;
+    }
+
+    return Number(a) < Number(b);
+  }
+
+  // defineRandom registers the random number natives. They draw from
+  // Math.random until seeded, and then from a generator of their own, so
+  // that a seeded program is reproducible; the numbers are not those the
+  // interpreter draws.
+  function defineRandom(define) {
+    define("random", 0, 0, () => state.random());
+
+    define("randomInt", 2, 2, (args) => {
+      const bound = (idx) => (typeof args[idx] === "bigint" ? args[idx] : BigInt(intArg("randomInt", args, idx)));
+      const lo = bound(0);
+      const hi = bound(1);
+      if (lo > hi) {
+        throw argErr("randomInt", "lower bound must not be greater than upper bound.");
+      }
+
+      // Floats cannot count every integer of wide ranges, so keep within
+      // them when rounding would step out.
+      const span = hi - lo;
+      const n = BigInt(Math.floor(state.random() * (Number(span) + 1)));
+
+      return lo + (n > span ? span : n);
+    });
+
+    define("shuffle", 1, 1, (args) => {
+      const e = listArg("shuffle", args, 0).elements;
+      for (let i = e.length - 1; i > 0; i--) {
+        const j = Math.floor(state.random() * (i + 1));
+        [e[i], e[j]] = [e[j], e[i]];
+      }
+
+      return null;
+    });
+
+    define("seed", 1, 1, (args) => {
+      let s = intArg("seed", args, 0) >>> 0;
+
+      // mulberry32
+      state.random = () => {
+        s = (s + 0x6d2b79f5) >>> 0;
+        let t = s;
+        t = Math.imul(t ^ (t >>> 15), t | 1);
+        t ^= t + Math.imul(t ^ (t >>> 7), t | 61);
+
+        return ((t ^ (t >>> 14)) >>> 0) / 4294967296;
+      };
+
+      return null;
+    });
+  }
+
+  // defineIO registers the input natives. Compiled programs have no files,
+  // so the file natives fail.
+  function defineIO(define) {
+    for (const name of ["readFile", "writeFile", "appendFile", "exists"]) {
+      define(name, name === "readFile" || name === "exists" ? 1 : 2, name === "readFile" || name === "exists" ? 1 : 2, () => {
+        throw argErr(name, "files are not available to compiled programs.");
+      });
+    }
+
+    define("readLine", 0, 0, () => state.readLine());
+  }
+
+  function defineCollections(define) {
+    define("list", 0, variadic, (args) => new List([...args]));
+    define("map", 0, 0, () => new Dict());
+
+    define("push", 2, 2, (args) => {
+      listArg("push", args, 0).elements.push(args[1]);
+      return null;
+    });
+
+    define("pop", 1, 1, (args) => {
+      const l = listArg("pop", args, 0);
+      if (l.elements.length === 0) {
+        throw argErr("pop", "list is empty.");
+      }
+
+      return l.elements.pop();
+    });
+
+    // get returns the element at an index of a list or the value for a
+    // key of a map. Missing map keys yield nil.
+    define("get", 2, 2, (args) => {
+      const c = args[0];
+      if (c instanceof List) {
+        return c.elements[listIndex("get", c, args, 1)];
+      }
+      if (c instanceof Dict) {
+        const key = stringArg("get", args, 1);
+        return c.entries.has(key) ? c.entries.get(key) : null;
+      }
+
+      throw argErr("get", "argument 1 must be a list or a map.");
+    });
+
+    define("set", 3, 3, (args) => {
+      const c = args[0];
+      if (c instanceof List) {
+        c.elements[listIndex("set", c, args, 1)] = args[2];
+        return null;
+      }
+      if (c instanceof Dict) {
+        c.entries.set(stringArg("set", args, 1), args[2]);
+        return null;
+      }
+
+      throw argErr("set", "argument 1 must be a list or a map.");
+    });
+
+    define("has", 2, 2, (args) => dictArg("has", args, 0).entries.has(stringArg("has", args, 1)));
+
+    define("remove", 2, 2, (args) => {
+      dictArg("remove", args, 0).entries.delete(stringArg("remove", args, 1));
+      return null;
+    });
+
+    define("keys", 1, 1, (args) => new List([...dictArg("keys", args, 0).entries.keys()]));
+  }
+
+  function listIndex(name, l, args, idx) {
+    let i = intArg(name, args, idx);
+    if (i < 0) {
+      i += l.elements.length;
+    }
+
+    if (i < 0 || i >= l.elements.length) {
+      throw argErr(name, `index ${i} out of bounds for length ${l.elements.length}.`);
+    }
+
+    return i;
+  }
+
+  // defineJSON registers the JSON natives. JSON objects map onto Lox maps,
+  // arrays onto lists and null onto nil.
+  function defineJSON(define) {
+    define("jsonParse", 1, 1, (args) => parseJSON(stringArg("jsonParse", args, 0)));
+
+    // The optional indent is either the number of spaces to indent nested
+    // values by or the indent string itself; omitting it, 0, "" and nil
+    // produce compact output. Numbers indent by at most 10 spaces.
+    define("jsonStringify", 1, 2, (args) => {
+      let indent = "";
+      const v = args.length < 2 ? null : args[1];
+      if (typeof v === "string") {
+        indent = v;
+      } else if (isNumber(v)) {
+        const n = intArg("jsonStringify", args, 1);
+        if (n < 0) {
+          throw argErr("jsonStringify", "indent must not be negative.");
+        }
+        indent = " ".repeat(Math.min(n, 10));
+      } else if (v !== null) {
+        throw argErr("jsonStringify", "indent must be a number or a string.");
+      }
+
+      return encodeJSON(args[0], indent, "", new Set());
+    });
+  }
+
+  // quoteChar quotes a character as Go's JSON decoder does in its errors.
+  function quoteChar(c) {
+    const escapes = { "'": "\\'", "\\": "\\\\", "\x07": "\\a", "\b": "\\b", "\f": "\\f", "\n": "\\n", "\r": "\\r", "\t": "\\t", "\v": "\\v" };
+    if (c in escapes) {
+      return `'${escapes[c]}'`;
+    }
+
+    const cp = c.codePointAt(0);
+    if (cp < 0x20 || cp === 0x7f) {
+      return `'\\x${cp.toString(16).padStart(2, "0")}'`;
+    }
+
+    return `'${c}'`;
+  }
+
+  // parseJSON reads a JSON document by hand, since JSON.parse loses the
+  // order of integer-like keys and the precision of large integers. Errors
+  // read as those of the Go decoder the interpreter uses.
+  function parseJSON(s) {
+    const truncated = "unexpected end of JSON input";
+    let pos = 0;
+
+    const bad = (msg) => {
+      throw argErr("jsonParse", msg);
+    };
+    const space = () => {
+      while (pos < s.length && " \t\n\r".includes(s[pos])) {
+        pos++;
+      }
+    };
+    // invalid reports the character at pos, or the end of the input.
+    const invalid = (context) => {
+      if (pos >= s.length) {
+        bad("unexpected EOF");
+      }
+      bad(`invalid character ${quoteChar(String.fromCodePoint(s.codePointAt(pos)))} ${context}`);
+    };
+    const expect = (chars, context, eof) => {
+      space();
+      if (pos >= s.length) {
+        bad(eof);
+      }
+      if (!chars.includes(s[pos])) {
+        invalid(context);
+      }
+      return s[pos++];
+    };
+    const digits = () => {
+      if (!(s[pos] >= "0" && s[pos] <= "9")) {
+        invalid("in numeric literal");
+      }
+      while (s[pos] >= "0" && s[pos] <= "9") {
+        pos++;
+      }
+    };
+
+    const number = () => {
+      const start = pos;
+      let integer = true;
+      if (s[pos] === "-") {
+        pos++;
+      }
+      if (s[pos] === "0") {
+        pos++;
+      } else {
+        digits();
+      }
+      if (s[pos] === ".") {
+        pos++;
+        integer = false;
+        digits();
+      }
+      if (s[pos] === "e" || s[pos] === "E") {
+        pos++;
+        integer = false;
+        if (s[pos] === "+" || s[pos] === "-") {
+          pos++;
+        }
+        digits();
+      }
+
+      const text = s.slice(start, pos);
+      if (integer) {
+        const n = BigInt(text);
+        if (n === BigInt.asIntN(64, n)) {
+          return n;
+        }
+      }
+
+      return Number(text);
+    };
+
+    const keyword = (word, value) => {
+      for (const c of word) {
+        if (s[pos] !== c) {
+          invalid(`in literal ${word} (expecting ${quoteChar(c)})`);
+        }
+        pos++;
+      }
+
+      return value;
+    };
+
+    const string = () => {
+      const start = pos++;
+      for (;;) {
+        if (pos >= s.length) {
+          bad("unexpected EOF");
+        }
+
+        const c = s[pos];
+        if (c === '"') {
+          pos++;
+          break;
+        }
+        if (c < " ") {
+          invalid("in string");
+        }
+        if (c !== "\\") {
+          pos++;
+          continue;
+        }
+
+        const seq = s.slice(pos, pos + (s[pos + 1] === "u" ? 6 : 2));
+        if (seq.length < 2 || (seq[1] === "u" && seq.length < 6)) {
+          bad("unexpected EOF");
+        }
+        if (!/^\\(["\\/bfnrt]|u[0-9a-fA-F]{4})$/.test(seq)) {
+          bad(`invalid escape sequence \`${seq}\` in string`);
+        }
+        pos += seq.length;
+      }
+
+      // Go replaces unpaired surrogates, which JavaScript strings keep.
+      return JSON.parse(s.slice(start, pos)).replace(/[\ud800-\udbff](?![\udc00-\udfff])|(?<![\ud800-\udbff])[\udc00-\udfff]/g, "\ufffd");
+    };
+
+    const value = (eof) => {
+      space();
+      if (pos >= s.length) {
+        bad(eof);
+      }
+
+      if (s[pos] === "[") {
+        pos++;
+        const elements = [];
+        space();
+        if (s[pos] === "]") {
+          pos++;
+          return new List(elements);
+        }
+
+        do {
+          space();
+          if (s[pos] === "]" && elements.length > 0) {
+            bad("invalid character ',' looking for beginning of value");
+          }
+          elements.push(value(truncated));
+        } while (expect(",]", "after array element", truncated) === ",");
+
+        return new List(elements);
+      }
+
+      if (s[pos] === "{") {
+        pos++;
+        const d = new Dict();
+        space();
+        if (s[pos] === "}") {
+          pos++;
+          return d;
+        }
+
+        do {
+          space();
+          if (pos >= s.length) {
+            bad(truncated);
+          }
+          if (s[pos] !== '"') {
+            bad("object member name must be a string");
+          }
+
+          const key = value(truncated);
+          expect(":", "after object key", "EOF");
+          d.entries.set(key, value("EOF"));
+        } while (expect(",}", "after object key:value pair", truncated) === ",");
+
+        return d;
+      }
+
+      switch (s[pos]) {
+        case '"':
+          return string();
+        case "t":
+          return keyword("true", true);
+        case "f":
+          return keyword("false", false);
+        case "n":
+          return keyword("null", null);
+      }
+
+      if (s[pos] === "-" || (s[pos] >= "0" && s[pos] <= "9")) {
+        return number();
+      }
+
+      invalid("looking for beginning of value");
+    };
+
+    const result = value("EOF");
+
+    space();
+    if (pos < s.length) {
+      bad("unexpected data after top-level value.");
+    }
+
+    return result;
+  }
+
+  // encodeJSON encodes value, indenting nested values by indent from
+  // prefix. seen holds the lists and maps currently being encoded so that
+  // cycles are reported instead of recursing forever.
+  function encodeJSON(value, indent, prefix, seen) {
+    const bad = (msg) => {
+      throw argErr("jsonStringify", msg);
+    };
+
+    if (value === null) {
+      return "null";
+    }
+
+    switch (typeof value) {
+      case "boolean":
+      case "bigint":
+        return String(value);
+      case "number":
+        if (!Number.isFinite(value)) {
+          bad(`cannot encode ${formatFloat(value)}.`);
+        }
+        return goFloat(value);
+      case "string":
+        return quoteJSON(value);
+    }
+
+    let items;
+    let open;
+    let close;
+    if (value instanceof List || value instanceof Dict) {
+      const kind = value instanceof List ? "list" : "map";
+      if (seen.has(value)) {
+        bad(`cannot encode cyclic ${kind}.`);
+      }
+      seen.add(value);
+
+      const inner = prefix + indent;
+      const sep = indent === "" ? ":" : ": ";
+      try {
+        if (value instanceof List) {
+          items = value.elements.map((e) => encodeJSON(e, indent, inner, seen));
+          [open, close] = ["[", "]"];
+        } else {
+          items = [...value.entries].map(([k, e]) => quoteJSON(k) + sep + encodeJSON(e, indent, inner, seen));
+          [open, close] = ["{", "}"];
+        }
+      } finally {
+        seen.delete(value);
+      }
+
+      if (indent === "" || items.length === 0) {
+        return open + items.join(",") + close;
+      }
+
+      return `${open}\n${inner}${items.join(`,\n${inner}`)}\n${prefix}${close}`;
+    }
+
+    if (value instanceof LoxFunction || value instanceof Native) {
+      bad(`cannot encode function ${value}.`);
+    }
+
+    bad(`cannot encode ${value}.`);
+  }
+
+  // quoteJSON quotes a string as Go's encoding/json does, which also
+  // escapes the characters that are special in HTML.
+  function quoteJSON(s) {
+    return JSON.stringify(s).replace(/[<>&\u2028\u2029]/g, (c) => `\\u${c.charCodeAt(0).toString(16).padStart(4, "0")}`);
+  }
+
+  // defineAssert registers the assertion natives. A failed assertion is a
+  // runtime error.
+  function defineAssert(define) {
+    // assertErr returns the error for a failed assertion, prefixed by the
+    // message the script passed as the argument at idx, if any.
+    const assertErr = (args, idx, msg) =>
+      new RuntimeError(null, idx < args.length ? `${stringify(args[idx])}: ${msg}` : msg);
+
+    define("assert", 1, 2, (args) => {
+      if (!truthy(args[0])) {
+        throw assertErr(args, 1, "Assertion failed.");
+      }
+
+      return null;
+    });
+
+    define("assertEqual", 2, 3, (args) => {
+      const [actual, expected] = args;
+      if (!deepEqual(actual, expected)) {
+        throw assertErr(args, 2, `Expected ${quote(expected)} but got ${quote(actual)}.`);
+      }
+
+      return null;
+    });
+
+    // assertThrows calls a function taking no arguments and returns what
+    // it threw, as a catch clause would bind it.
+    define("assertThrows", 1, 2, (args) => {
+      const f = args[0];
+      if (!(f instanceof LoxFunction || f instanceof Native)) {
+        throw argErr("assertThrows", "argument 1 must be a function.");
+      }
+
+      if (f.arity()[0] > 0) {
+        throw argErr("assertThrows", "argument 1 must take no arguments.");
+      }
+
+      try {
+        callValue(f, []);
+      } catch (e) {
+        return caught(e);
+      }
+
+      throw assertErr(args, 1, "Expected an exception but none was thrown.");
+    });
+  }
+
+  // deepEqual is like ==, but compares lists and maps by their contents.
+  function deepEqual(a, b) {
+    if (a instanceof List) {
+      return (
+        b instanceof List &&
+        a.elements.length === b.elements.length &&
+        a.elements.every((e, idx) => deepEqual(e, b.elements[idx]))
+      );
+    }
+
+    if (a instanceof Dict) {
+      return (
+        b instanceof Dict &&
+        a.entries.size === b.entries.size &&
+        [...a.entries].every(([k, e]) => b.entries.has(k) && deepEqual(e, b.entries.get(k)))
+      );
+    }
+
+    return equal(a, b);
+  }
+
+  // quote shows a value in a failure message, quoting strings so that they
+  // can be told apart from other values.
+  function quote(v) {
+    return typeof v === "string" ? JSON.stringify(v) : stringify(v);
+  }
+
+  return {
+    tok,
+    fail,
+    main,
+    print,
+    truthy,
+    equal,
+    binary,
+    unary,
+    get,
+    thrown,
+    caught,
+    fn,
+    unset,
+    missingArg,
+    call,
+    callNamed,
+    tailCall,
+    tailCallNamed,
+    import: importModule,
+    member,
+  };
+})();
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"glox/lerr"
 	"glox/token"
+	"sort"
 )
 
 type Environment struct {
@@ -43,13 +44,54 @@ func (e *Environment) Assign(name token.Token, value interface{}) error {
 	}
 
 	if e.enclosing != nil {
-		e.enclosing.Assign(name, value)
+		return e.enclosing.Assign(name, value)
 	}
 
 	return lerr.NewRuntimeErr(name,
 		fmt.Sprintf("Undefined variable %s", name.GetLexeme()))
 }
 
+// Lookup returns the value bound to name in this environment only, without
+// consulting enclosing environments.
+func (e *Environment) Lookup(name string) (interface{}, bool) {
+	val, ok := e.values[name]
+	return val, ok
+}
+
+// Enclosing returns the environment enclosing this one, or nil.
+func (e *Environment) Enclosing() *Environment {
+	return e.enclosing
+}
+
+// Values returns a copy of the bindings defined in this environment only.
+func (e *Environment) Values() map[string]interface{} {
+	values := make(map[string]interface{}, len(e.values))
+	for name, value := range e.values {
+		values[name] = value
+	}
+
+	return values
+}
+
+// Names returns the names defined in this environment and the ones
+// enclosing it, sorted.
+func (e *Environment) Names() []string {
+	seen := map[string]bool{}
+	for env := e; env != nil; env = env.enclosing {
+		for name := range env.values {
+			seen[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
+
 func (e *Environment) GetAt(distance int, name string) (interface{}, error) {
 	env := e.ancestor(distance)
 	val, ok := env.values[name]
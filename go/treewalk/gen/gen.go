@@ -1,6 +1,8 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"html/template"
 	"log"
 	"os"
@@ -11,6 +13,30 @@ import (
 
 var templateFuncMap = template.FuncMap{
 	"ToLower": strings.ToLower,
+	"Codec":   codec,
+	"Inc":     func(i int) int { return i + 1 },
+}
+
+// codecs names the encoder and decoder methods, in codec.go, for each
+// attribute type.
+var codecs = map[string]string{
+	"Expr":          "expr",
+	"Stmt":          "stmt",
+	"token.Token":   "token",
+	"[]Expr":        "exprs",
+	"[]Stmt":        "stmts",
+	"[]token.Token": "tokens",
+	"[]*MatchCase":  "matchCases",
+	"interface{}":   "value",
+}
+
+func codec(typ string) string {
+	name, ok := codecs[typ]
+	if !ok {
+		log.Fatalf("no codec for attribute type %s", typ)
+	}
+
+	return name
 }
 
 var iVisitorTemplate = template.Must(template.New("iVisitor").Funcs(templateFuncMap).Parse(`
@@ -47,7 +73,7 @@ type Stmt interface {
 }
 `))
 
-var exprTemplate = template.Must(template.New("exprDef").Parse(`
+var exprTemplate = template.Must(template.New("exprDef").Funcs(templateFuncMap).Parse(`
 // generated code - DO NOT EDIT
 package generated
 
@@ -78,9 +104,21 @@ func New{{ .Name }}(
 func (x *{{ .Name }}) Accept(visitor VisitorExpr) (interface{}, error) {
 	return visitor.Visit{{ .Name }}(x)
 }
+
+func (x *{{ .Name }}) encode(e *encoder) {
+	{{- range .Attributes }}
+	e.{{ Codec .Type }}(x.{{ .Name }})
+	{{- end }}
+}
+
+func (x *{{ .Name }}) decode(d *decoder) {
+	{{- range .Attributes }}
+	x.{{ .Name }} = d.{{ Codec .Type }}()
+	{{- end }}
+}
 `))
 
-var stmtTemplate = template.Must(template.New("stmtDef").Parse(`
+var stmtTemplate = template.Must(template.New("stmtDef").Funcs(templateFuncMap).Parse(`
 // generated code - DO NOT EDIT
 package generated
 
@@ -111,8 +149,78 @@ func New{{ .Name }}(
 func (x *{{ .Name }}) Accept(visitor VisitorStmt) (interface{}, error) {
 	return visitor.Visit{{ .Name }}(x)
 }
+
+func (x *{{ .Name }}) encode(e *encoder) {
+	{{- range .Attributes }}
+	e.{{ Codec .Type }}(x.{{ .Name }})
+	{{- end }}
+}
+
+func (x *{{ .Name }}) decode(d *decoder) {
+	{{- range .Attributes }}
+	x.{{ .Name }} = d.{{ Codec .Type }}()
+	{{- end }}
+}
+`))
+
+var codecTemplate = template.Must(template.New("codec").Funcs(templateFuncMap).Parse(`
+// generated code - DO NOT EDIT
+package generated
+
+// schema fingerprints the node definitions the codec was generated from.
+const schema = "{{ .Schema }}"
+
+func exprTag(x Expr) uint64 {
+	switch x.(type) {
+	{{- range $i, $n := .Exprs }}
+	case *{{ $n }}:
+		return {{ Inc $i }}
+	{{- end }}
+	}
+
+	return 0
+}
+
+func newExpr(tag uint64) Expr {
+	switch tag {
+	{{- range $i, $n := .Exprs }}
+	case {{ Inc $i }}:
+		return &{{ $n }}{}
+	{{- end }}
+	}
+
+	return nil
+}
+
+func stmtTag(x Stmt) uint64 {
+	switch x.(type) {
+	{{- range $i, $n := .Stmts }}
+	case *{{ $n }}:
+		return {{ Inc $i }}
+	{{- end }}
+	}
+
+	return 0
+}
+
+func newStmt(tag uint64) Stmt {
+	switch tag {
+	{{- range $i, $n := .Stmts }}
+	case {{ Inc $i }}:
+		return &{{ $n }}{}
+	{{- end }}
+	}
+
+	return nil
+}
 `))
 
+type codecDef struct {
+	Schema string
+	Exprs  []string
+	Stmts  []string
+}
+
 type ExprDefs struct {
 	Name  string    `yml:"name"`
 	Exprs []ExprDef `yml:"exprs"`
@@ -192,11 +300,15 @@ func main() {
 		log.Fatalf("error while executing expr interface template: %v", err)
 	}
 
+	schema := sha256.New()
+	schema.Write(yamlData)
+
 	// Load YAML file into memory
 	yamlData, err = os.ReadFile("stmt_defs.yml")
 	if err != nil {
 		log.Fatalf("error reading yaml file: %v", err)
 	}
+	schema.Write(yamlData)
 
 	// Unmarshal YAML data into Go struct
 	var sdata StmtDefs
@@ -259,4 +371,21 @@ func main() {
 			log.Fatalf("error while executing template: %v", err)
 		}
 	}
+
+	// node codec
+	out, err = os.Create("../generated/" +
+		"codec" + ".gen.go")
+	if err != nil {
+		log.Fatalf("error creating codec file: %v", err)
+	}
+	defer out.Close()
+
+	err = codecTemplate.Execute(out, codecDef{
+		Schema: hex.EncodeToString(schema.Sum(nil)),
+		Exprs:  enames,
+		Stmts:  snames,
+	})
+	if err != nil {
+		log.Fatalf("error while executing codec template: %v", err)
+	}
 }
@@ -24,3 +24,13 @@ func NewAssign(
 func (x *Assign) Accept(visitor VisitorExpr) (interface{}, error) {
 	return visitor.VisitAssign(x)
 }
+
+func (x *Assign) encode(e *encoder) {
+	e.token(x.Name)
+	e.expr(x.Value)
+}
+
+func (x *Assign) decode(d *decoder) {
+	x.Name = d.token()
+	x.Value = d.expr()
+}
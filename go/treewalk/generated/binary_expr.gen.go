@@ -27,3 +27,15 @@ func NewBinary(
 func (x *Binary) Accept(visitor VisitorExpr) (interface{}, error) {
 	return visitor.VisitBinary(x)
 }
+
+func (x *Binary) encode(e *encoder) {
+	e.expr(x.Left)
+	e.token(x.Operator)
+	e.expr(x.Right)
+}
+
+func (x *Binary) decode(d *decoder) {
+	x.Left = d.expr()
+	x.Operator = d.token()
+	x.Right = d.expr()
+}
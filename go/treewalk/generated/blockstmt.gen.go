@@ -20,3 +20,11 @@ func NewBlockStmt(
 func (x *BlockStmt) Accept(visitor VisitorStmt) (interface{}, error) {
 	return visitor.VisitBlockStmt(x)
 }
+
+func (x *BlockStmt) encode(e *encoder) {
+	e.stmts(x.Statements)
+}
+
+func (x *BlockStmt) decode(d *decoder) {
+	x.Statements = d.stmts()
+}
@@ -10,20 +10,37 @@ type Call struct {
 	Callee Expr
 	Paren token.Token
 	Arguments []Expr
+	Names []token.Token
 }
 
 func NewCall(
 	Callee Expr,
 	Paren token.Token,
 	Arguments []Expr,
+	Names []token.Token,
 ) *Call {
 	return &Call {
 		Callee: Callee,
 		Paren: Paren,
 		Arguments: Arguments,
+		Names: Names,
 	}
 }
 
 func (x *Call) Accept(visitor VisitorExpr) (interface{}, error) {
 	return visitor.VisitCall(x)
 }
+
+func (x *Call) encode(e *encoder) {
+	e.expr(x.Callee)
+	e.token(x.Paren)
+	e.exprs(x.Arguments)
+	e.tokens(x.Names)
+}
+
+func (x *Call) decode(d *decoder) {
+	x.Callee = d.expr()
+	x.Paren = d.token()
+	x.Arguments = d.exprs()
+	x.Names = d.tokens()
+}
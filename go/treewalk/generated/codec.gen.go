@@ -0,0 +1,130 @@
+
+// generated code - DO NOT EDIT
+package generated
+
+// schema fingerprints the node definitions the codec was generated from.
+const schema = "66912c3788859d909a08a63d492c1fd8c3949a2afd7b7c89283b67d14a5f937f"
+
+func exprTag(x Expr) uint64 {
+	switch x.(type) {
+	case *Assign:
+		return 1
+	case *Logical:
+		return 2
+	case *Binary:
+		return 3
+	case *Ternary:
+		return 4
+	case *Grouping:
+		return 5
+	case *Literal:
+		return 6
+	case *Unary:
+		return 7
+	case *Call:
+		return 8
+	case *VarExpr:
+		return 9
+	case *Get:
+		return 10
+	case *MatchExpr:
+		return 11
+	}
+
+	return 0
+}
+
+func newExpr(tag uint64) Expr {
+	switch tag {
+	case 1:
+		return &Assign{}
+	case 2:
+		return &Logical{}
+	case 3:
+		return &Binary{}
+	case 4:
+		return &Ternary{}
+	case 5:
+		return &Grouping{}
+	case 6:
+		return &Literal{}
+	case 7:
+		return &Unary{}
+	case 8:
+		return &Call{}
+	case 9:
+		return &VarExpr{}
+	case 10:
+		return &Get{}
+	case 11:
+		return &MatchExpr{}
+	}
+
+	return nil
+}
+
+func stmtTag(x Stmt) uint64 {
+	switch x.(type) {
+	case *BlockStmt:
+		return 1
+	case *IfStmt:
+		return 2
+	case *WhileStmt:
+		return 3
+	case *ExprStmt:
+		return 4
+	case *PrintStmt:
+		return 5
+	case *VarStmt:
+		return 6
+	case *FunctionStmt:
+		return 7
+	case *ReturnStmt:
+		return 8
+	case *ImportStmt:
+		return 9
+	case *ThrowStmt:
+		return 10
+	case *TryStmt:
+		return 11
+	case *MatchStmt:
+		return 12
+	case *TestStmt:
+		return 13
+	}
+
+	return 0
+}
+
+func newStmt(tag uint64) Stmt {
+	switch tag {
+	case 1:
+		return &BlockStmt{}
+	case 2:
+		return &IfStmt{}
+	case 3:
+		return &WhileStmt{}
+	case 4:
+		return &ExprStmt{}
+	case 5:
+		return &PrintStmt{}
+	case 6:
+		return &VarStmt{}
+	case 7:
+		return &FunctionStmt{}
+	case 8:
+		return &ReturnStmt{}
+	case 9:
+		return &ImportStmt{}
+	case 10:
+		return &ThrowStmt{}
+	case 11:
+		return &TryStmt{}
+	case 12:
+		return &MatchStmt{}
+	case 13:
+		return &TestStmt{}
+	}
+
+	return nil
+}
@@ -0,0 +1,461 @@
+package generated
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"glox/token"
+	"math"
+)
+
+// version is bumped whenever the encoding changes in a way the node
+// definitions do not show, such as a change to MatchCase or to the
+// primitives below.
+const version = 1
+
+// node is implemented by every generated node. It encodes and decodes the
+// attributes of the node in the order they are declared.
+type node interface {
+	encode(e *encoder)
+	decode(d *decoder)
+}
+
+// EncodeStmts writes statements in a compact binary form, which
+// DecodeStmts reads back. The data is only readable by a build with the
+// same node definitions.
+//
+// Pointers do not survive encoding, so it also returns every expression
+// written, in order and once for each place it occurs: an expression is
+// identified by the same index in the list returned by DecodeStmts.
+func EncodeStmts(stmts []Stmt) ([]byte, []Expr, error) {
+	e := &encoder{}
+	e.uint(version)
+	e.string(schema)
+	e.stmts(stmts)
+
+	return e.buf, e.list, e.err
+}
+
+// DecodeStmts reads statements written by EncodeStmts, and the list of
+// their expressions.
+func DecodeStmts(data []byte) ([]Stmt, []Expr, error) {
+	d := &decoder{data: data}
+	if d.uint() != version || d.string() != schema {
+		return nil, nil, errors.New("syntax tree encoded for other node definitions")
+	}
+
+	stmts := d.stmts()
+	if d.err == nil && len(d.data) != 0 {
+		d.err = errors.New("trailing data after syntax tree")
+	}
+	if d.err != nil {
+		return nil, nil, d.err
+	}
+
+	return stmts, d.list, nil
+}
+
+// Tags of the values held by literals.
+const (
+	valueNil byte = iota
+	valueFalse
+	valueTrue
+	valueString
+	valueFloat
+	valueInt
+)
+
+// encoder appends to buf, remembering the first error. Nil nodes, tokens
+// and slices are written as 0, and the others are prefixed with their tag
+// or their length plus one. The strings of tokens are interned: each is
+// written once, and then referred to by the order it was first written
+// in.
+type encoder struct {
+	buf     []byte
+	list    []Expr
+	err     error
+	strings map[string]uint64
+}
+
+func (e *encoder) uint(v uint64) {
+	e.buf = binary.AppendUvarint(e.buf, v)
+}
+
+func (e *encoder) int(v int64) {
+	e.buf = binary.AppendVarint(e.buf, v)
+}
+
+func (e *encoder) string(s string) {
+	e.uint(uint64(len(s)))
+	e.buf = append(e.buf, s...)
+}
+
+func (e *encoder) intern(s string) {
+	if idx, ok := e.strings[s]; ok {
+		e.uint(idx + 1)
+		return
+	}
+
+	if e.strings == nil {
+		e.strings = make(map[string]uint64)
+	}
+	e.strings[s] = uint64(len(e.strings))
+
+	e.uint(0)
+	e.string(s)
+}
+
+func (e *encoder) value(v interface{}) {
+	switch v := v.(type) {
+	case nil:
+		e.buf = append(e.buf, valueNil)
+	case bool:
+		if v {
+			e.buf = append(e.buf, valueTrue)
+		} else {
+			e.buf = append(e.buf, valueFalse)
+		}
+	case string:
+		e.buf = append(e.buf, valueString)
+		e.intern(v)
+	case float64:
+		e.buf = append(e.buf, valueFloat)
+		e.buf = binary.LittleEndian.AppendUint64(e.buf, math.Float64bits(v))
+	case int64:
+		e.buf = append(e.buf, valueInt)
+		e.int(v)
+	default:
+		if e.err == nil {
+			e.err = fmt.Errorf("cannot encode a value of type %T", v)
+		}
+	}
+}
+
+func (e *encoder) token(t token.Token) {
+	if t == nil {
+		e.uint(0)
+		return
+	}
+
+	e.uint(1)
+	e.intern(string(t.GetType()))
+	e.intern(t.GetLexeme())
+	e.value(t.GetLiteral())
+	e.int(int64(t.GetLine()))
+	e.int(int64(t.GetColumn()))
+}
+
+func (e *encoder) expr(x Expr) {
+	if x == nil {
+		e.uint(0)
+		return
+	}
+
+	tag := exprTag(x)
+	if tag == 0 && e.err == nil {
+		e.err = fmt.Errorf("cannot encode an expression of type %T", x)
+	}
+
+	e.uint(tag)
+	e.list = append(e.list, x)
+	x.(node).encode(e)
+}
+
+func (e *encoder) stmt(x Stmt) {
+	if x == nil {
+		e.uint(0)
+		return
+	}
+
+	tag := stmtTag(x)
+	if tag == 0 && e.err == nil {
+		e.err = fmt.Errorf("cannot encode a statement of type %T", x)
+	}
+
+	e.uint(tag)
+	x.(node).encode(e)
+}
+
+func (e *encoder) length(n int, isNil bool) {
+	if isNil {
+		e.uint(0)
+	} else {
+		e.uint(uint64(n) + 1)
+	}
+}
+
+func (e *encoder) exprs(xs []Expr) {
+	e.length(len(xs), xs == nil)
+	for _, x := range xs {
+		e.expr(x)
+	}
+}
+
+func (e *encoder) stmts(xs []Stmt) {
+	e.length(len(xs), xs == nil)
+	for _, x := range xs {
+		e.stmt(x)
+	}
+}
+
+func (e *encoder) tokens(ts []token.Token) {
+	e.length(len(ts), ts == nil)
+	for _, t := range ts {
+		e.token(t)
+	}
+}
+
+func (e *encoder) matchCases(cases []*MatchCase) {
+	e.length(len(cases), cases == nil)
+	for _, mc := range cases {
+		if mc == nil {
+			e.uint(0)
+			continue
+		}
+
+		e.uint(1)
+		e.token(mc.Keyword)
+		e.exprs(mc.Patterns)
+		e.token(mc.Binding)
+		e.expr(mc.Guard)
+		e.stmt(mc.Body)
+		e.expr(mc.Value)
+	}
+}
+
+var errTruncated = errors.New("truncated syntax tree")
+
+// decoder reads from data, remembering the first error. Once it fails it
+// only returns zero values, so nodes can be decoded without checking each
+// attribute.
+type decoder struct {
+	data    []byte
+	list    []Expr
+	err     error
+	strings []string
+}
+
+func (d *decoder) fail(err error) {
+	if d.err == nil {
+		d.err = err
+	}
+	d.data = nil
+}
+
+func (d *decoder) uint() uint64 {
+	v, n := binary.Uvarint(d.data)
+	if n <= 0 {
+		d.fail(errTruncated)
+		return 0
+	}
+	d.data = d.data[n:]
+
+	return v
+}
+
+func (d *decoder) int() int64 {
+	v, n := binary.Varint(d.data)
+	if n <= 0 {
+		d.fail(errTruncated)
+		return 0
+	}
+	d.data = d.data[n:]
+
+	return v
+}
+
+func (d *decoder) byte() byte {
+	if len(d.data) == 0 {
+		d.fail(errTruncated)
+		return 0
+	}
+	b := d.data[0]
+	d.data = d.data[1:]
+
+	return b
+}
+
+func (d *decoder) string() string {
+	n := d.uint()
+	if n > uint64(len(d.data)) {
+		d.fail(errTruncated)
+		return ""
+	}
+	s := string(d.data[:n])
+	d.data = d.data[n:]
+
+	return s
+}
+
+func (d *decoder) intern() string {
+	idx := d.uint()
+	if idx == 0 {
+		s := d.string()
+		d.strings = append(d.strings, s)
+		return s
+	}
+
+	if idx > uint64(len(d.strings)) {
+		d.fail(fmt.Errorf("unknown string %d", idx))
+		return ""
+	}
+
+	return d.strings[idx-1]
+}
+
+func (d *decoder) value() interface{} {
+	switch tag := d.byte(); tag {
+	case valueNil:
+		return nil
+	case valueFalse:
+		return false
+	case valueTrue:
+		return true
+	case valueString:
+		return d.intern()
+	case valueFloat:
+		if len(d.data) < 8 {
+			d.fail(errTruncated)
+			return nil
+		}
+		v := math.Float64frombits(binary.LittleEndian.Uint64(d.data))
+		d.data = d.data[8:]
+		return v
+	case valueInt:
+		return d.int()
+	default:
+		d.fail(fmt.Errorf("unknown value tag %d", tag))
+		return nil
+	}
+}
+
+func (d *decoder) token() token.Token {
+	if d.uint() == 0 {
+		return nil
+	}
+
+	tokenType := token.TokenType(d.intern())
+	lexeme := d.intern()
+	literal := d.value()
+	line := d.int()
+	column := d.int()
+
+	return token.NewToken(tokenType, lexeme, literal, int(line), int(column))
+}
+
+func (d *decoder) expr() Expr {
+	tag := d.uint()
+	if tag == 0 {
+		return nil
+	}
+
+	x := newExpr(tag)
+	if x == nil {
+		d.fail(fmt.Errorf("unknown expression tag %d", tag))
+		return nil
+	}
+
+	d.list = append(d.list, x)
+	x.(node).decode(d)
+
+	return x
+}
+
+func (d *decoder) stmt() Stmt {
+	tag := d.uint()
+	if tag == 0 {
+		return nil
+	}
+
+	x := newStmt(tag)
+	if x == nil {
+		d.fail(fmt.Errorf("unknown statement tag %d", tag))
+		return nil
+	}
+
+	x.(node).decode(d)
+
+	return x
+}
+
+// length reads the length of a slice, and whether it is nil. Every
+// element takes at least a byte, which bounds lengths read from bad data.
+func (d *decoder) length() (int, bool) {
+	n := d.uint()
+	if n == 0 {
+		return 0, true
+	}
+	if n-1 > uint64(len(d.data)) {
+		d.fail(errTruncated)
+		return 0, true
+	}
+
+	return int(n - 1), false
+}
+
+func (d *decoder) exprs() []Expr {
+	n, isNil := d.length()
+	if isNil {
+		return nil
+	}
+
+	xs := make([]Expr, n)
+	for idx := range xs {
+		xs[idx] = d.expr()
+	}
+
+	return xs
+}
+
+func (d *decoder) stmts() []Stmt {
+	n, isNil := d.length()
+	if isNil {
+		return nil
+	}
+
+	xs := make([]Stmt, n)
+	for idx := range xs {
+		xs[idx] = d.stmt()
+	}
+
+	return xs
+}
+
+func (d *decoder) tokens() []token.Token {
+	n, isNil := d.length()
+	if isNil {
+		return nil
+	}
+
+	ts := make([]token.Token, n)
+	for idx := range ts {
+		ts[idx] = d.token()
+	}
+
+	return ts
+}
+
+func (d *decoder) matchCases() []*MatchCase {
+	n, isNil := d.length()
+	if isNil {
+		return nil
+	}
+
+	cases := make([]*MatchCase, n)
+	for idx := range cases {
+		if d.uint() == 0 {
+			continue
+		}
+
+		cases[idx] = &MatchCase{
+			Keyword:  d.token(),
+			Patterns: d.exprs(),
+			Binding:  d.token(),
+			Guard:    d.expr(),
+			Body:     d.stmt(),
+			Value:    d.expr(),
+		}
+	}
+
+	return cases
+}
@@ -20,3 +20,11 @@ func NewExprStmt(
 func (x *ExprStmt) Accept(visitor VisitorStmt) (interface{}, error) {
 	return visitor.VisitExprStmt(x)
 }
+
+func (x *ExprStmt) encode(e *encoder) {
+	e.expr(x.Expr)
+}
+
+func (x *ExprStmt) decode(d *decoder) {
+	x.Expr = d.expr()
+}
@@ -9,17 +9,29 @@ import (
 type FunctionStmt struct {
 	Name token.Token
 	Params []token.Token
+	ParamTypes []token.Token
+	Defaults []Expr
+	Rest token.Token
+	ReturnType token.Token
 	Body []Stmt
 }
 
 func NewFunctionStmt(
 	Name token.Token,
 	Params []token.Token,
+	ParamTypes []token.Token,
+	Defaults []Expr,
+	Rest token.Token,
+	ReturnType token.Token,
 	Body []Stmt,
 ) *FunctionStmt {
 	return &FunctionStmt {
 		Name: Name,
 		Params: Params,
+		ParamTypes: ParamTypes,
+		Defaults: Defaults,
+		Rest: Rest,
+		ReturnType: ReturnType,
 		Body: Body,
 	}
 }
@@ -27,3 +39,23 @@ func NewFunctionStmt(
 func (x *FunctionStmt) Accept(visitor VisitorStmt) (interface{}, error) {
 	return visitor.VisitFunctionStmt(x)
 }
+
+func (x *FunctionStmt) encode(e *encoder) {
+	e.token(x.Name)
+	e.tokens(x.Params)
+	e.tokens(x.ParamTypes)
+	e.exprs(x.Defaults)
+	e.token(x.Rest)
+	e.token(x.ReturnType)
+	e.stmts(x.Body)
+}
+
+func (x *FunctionStmt) decode(d *decoder) {
+	x.Name = d.token()
+	x.Params = d.tokens()
+	x.ParamTypes = d.tokens()
+	x.Defaults = d.exprs()
+	x.Rest = d.token()
+	x.ReturnType = d.token()
+	x.Body = d.stmts()
+}
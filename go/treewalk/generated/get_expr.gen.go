@@ -0,0 +1,36 @@
+
+// generated code - DO NOT EDIT
+package generated
+
+import (
+	"glox/token"
+)
+
+type Get struct {
+	Object Expr
+	Name token.Token
+}
+
+func NewGet(
+	Object Expr,
+	Name token.Token,
+) *Get {
+	return &Get {
+		Object: Object,
+		Name: Name,
+	}
+}
+
+func (x *Get) Accept(visitor VisitorExpr) (interface{}, error) {
+	return visitor.VisitGet(x)
+}
+
+func (x *Get) encode(e *encoder) {
+	e.expr(x.Object)
+	e.token(x.Name)
+}
+
+func (x *Get) decode(d *decoder) {
+	x.Object = d.expr()
+	x.Name = d.token()
+}
@@ -20,3 +20,11 @@ func NewGrouping(
 func (x *Grouping) Accept(visitor VisitorExpr) (interface{}, error) {
 	return visitor.VisitGrouping(x)
 }
+
+func (x *Grouping) encode(e *encoder) {
+	e.expr(x.Expression)
+}
+
+func (x *Grouping) decode(d *decoder) {
+	x.Expression = d.expr()
+}
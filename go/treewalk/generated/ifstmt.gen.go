@@ -3,20 +3,24 @@
 package generated
 
 import (
+	"glox/token"
 )
 
 type IfStmt struct {
+	Keyword token.Token
 	Condition Expr
 	IfBranch Stmt
 	ElseBranch Stmt
 }
 
 func NewIfStmt(
+	Keyword token.Token,
 	Condition Expr,
 	IfBranch Stmt,
 	ElseBranch Stmt,
 ) *IfStmt {
 	return &IfStmt {
+		Keyword: Keyword,
 		Condition: Condition,
 		IfBranch: IfBranch,
 		ElseBranch: ElseBranch,
@@ -26,3 +30,17 @@ func NewIfStmt(
 func (x *IfStmt) Accept(visitor VisitorStmt) (interface{}, error) {
 	return visitor.VisitIfStmt(x)
 }
+
+func (x *IfStmt) encode(e *encoder) {
+	e.token(x.Keyword)
+	e.expr(x.Condition)
+	e.stmt(x.IfBranch)
+	e.stmt(x.ElseBranch)
+}
+
+func (x *IfStmt) decode(d *decoder) {
+	x.Keyword = d.token()
+	x.Condition = d.expr()
+	x.IfBranch = d.stmt()
+	x.ElseBranch = d.stmt()
+}
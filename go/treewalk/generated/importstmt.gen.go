@@ -0,0 +1,46 @@
+
+// generated code - DO NOT EDIT
+package generated
+
+import (
+	"glox/token"
+)
+
+type ImportStmt struct {
+	Keyword token.Token
+	Path token.Token
+	Alias token.Token
+	Names []token.Token
+}
+
+func NewImportStmt(
+	Keyword token.Token,
+	Path token.Token,
+	Alias token.Token,
+	Names []token.Token,
+) *ImportStmt {
+	return &ImportStmt {
+		Keyword: Keyword,
+		Path: Path,
+		Alias: Alias,
+		Names: Names,
+	}
+}
+
+func (x *ImportStmt) Accept(visitor VisitorStmt) (interface{}, error) {
+	return visitor.VisitImportStmt(x)
+}
+
+func (x *ImportStmt) encode(e *encoder) {
+	e.token(x.Keyword)
+	e.token(x.Path)
+	e.token(x.Alias)
+	e.tokens(x.Names)
+}
+
+func (x *ImportStmt) decode(d *decoder) {
+	x.Keyword = d.token()
+	x.Path = d.token()
+	x.Alias = d.token()
+	x.Names = d.tokens()
+}
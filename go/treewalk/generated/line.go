@@ -0,0 +1,75 @@
+package generated
+
+// StmtLine returns the line a statement starts on, or 0 if it cannot be
+// told, as for an empty block or an expression statement made of a lone
+// literal. It is written by hand since nodes only record their tokens.
+func StmtLine(stmt Stmt) int {
+	switch s := stmt.(type) {
+	case *BlockStmt:
+		if len(s.Statements) > 0 {
+			return StmtLine(s.Statements[0])
+		}
+	case *IfStmt:
+		return s.Keyword.GetLine()
+	case *WhileStmt:
+		return s.Keyword.GetLine()
+	case *ExprStmt:
+		return ExprLine(s.Expr)
+	case *PrintStmt:
+		return s.Keyword.GetLine()
+	case *VarStmt:
+		return s.Name.GetLine()
+	case *FunctionStmt:
+		return s.Name.GetLine()
+	case *ReturnStmt:
+		return s.Keyword.GetLine()
+	case *ImportStmt:
+		return s.Keyword.GetLine()
+	case *ThrowStmt:
+		return s.Keyword.GetLine()
+	case *TryStmt:
+		return s.Keyword.GetLine()
+	case *MatchStmt:
+		return s.Keyword.GetLine()
+	case *TestStmt:
+		return s.Keyword.GetLine()
+	}
+
+	return 0
+}
+
+// ExprLine returns the line an expression starts on, or 0 for literals.
+func ExprLine(expr Expr) int {
+	switch e := expr.(type) {
+	case *Assign:
+		return e.Name.GetLine()
+	case *Logical:
+		return lineOr(ExprLine(e.Left), e.Operator.GetLine())
+	case *Binary:
+		return lineOr(ExprLine(e.Left), e.Operator.GetLine())
+	case *Ternary:
+		return lineOr(ExprLine(e.Condition), e.Question.GetLine())
+	case *Grouping:
+		return ExprLine(e.Expression)
+	case *Unary:
+		return e.Operator.GetLine()
+	case *Call:
+		return lineOr(ExprLine(e.Callee), e.Paren.GetLine())
+	case *VarExpr:
+		return e.Name.GetLine()
+	case *Get:
+		return lineOr(ExprLine(e.Object), e.Name.GetLine())
+	case *MatchExpr:
+		return e.Keyword.GetLine()
+	}
+
+	return 0
+}
+
+func lineOr(line int, fallback int) int {
+	if line == 0 {
+		return fallback
+	}
+
+	return line
+}
@@ -20,3 +20,11 @@ func NewLiteral(
 func (x *Literal) Accept(visitor VisitorExpr) (interface{}, error) {
 	return visitor.VisitLiteral(x)
 }
+
+func (x *Literal) encode(e *encoder) {
+	e.value(x.Value)
+}
+
+func (x *Literal) decode(d *decoder) {
+	x.Value = d.value()
+}
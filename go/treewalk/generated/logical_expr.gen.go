@@ -27,3 +27,15 @@ func NewLogical(
 func (x *Logical) Accept(visitor VisitorExpr) (interface{}, error) {
 	return visitor.VisitLogical(x)
 }
+
+func (x *Logical) encode(e *encoder) {
+	e.expr(x.Left)
+	e.token(x.Operator)
+	e.expr(x.Right)
+}
+
+func (x *Logical) decode(d *decoder) {
+	x.Left = d.expr()
+	x.Operator = d.token()
+	x.Right = d.expr()
+}
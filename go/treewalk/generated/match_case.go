@@ -0,0 +1,20 @@
+package generated
+
+import "glox/token"
+
+// MatchCase is one arm of a MatchStmt or MatchExpr. It is not a node of its
+// own, so it is written by hand rather than generated.
+//
+// An arm matches when the subject equals one of Patterns, or unconditionally
+// when Patterns is empty (a binding or default arm), and Guard is nil or
+// truthy. Binding, if set, names the subject inside Guard and the arm body.
+type MatchCase struct {
+	Keyword  token.Token
+	Patterns []Expr
+	Binding  token.Token
+	Guard    Expr
+
+	// Body is set for arms of a MatchStmt and Value for arms of a MatchExpr.
+	Body  Stmt
+	Value Expr
+}
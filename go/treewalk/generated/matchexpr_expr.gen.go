@@ -0,0 +1,41 @@
+
+// generated code - DO NOT EDIT
+package generated
+
+import (
+	"glox/token"
+)
+
+type MatchExpr struct {
+	Keyword token.Token
+	Subject Expr
+	Cases []*MatchCase
+}
+
+func NewMatchExpr(
+	Keyword token.Token,
+	Subject Expr,
+	Cases []*MatchCase,
+) *MatchExpr {
+	return &MatchExpr {
+		Keyword: Keyword,
+		Subject: Subject,
+		Cases: Cases,
+	}
+}
+
+func (x *MatchExpr) Accept(visitor VisitorExpr) (interface{}, error) {
+	return visitor.VisitMatchExpr(x)
+}
+
+func (x *MatchExpr) encode(e *encoder) {
+	e.token(x.Keyword)
+	e.expr(x.Subject)
+	e.matchCases(x.Cases)
+}
+
+func (x *MatchExpr) decode(d *decoder) {
+	x.Keyword = d.token()
+	x.Subject = d.expr()
+	x.Cases = d.matchCases()
+}
@@ -0,0 +1,41 @@
+
+// generated code - DO NOT EDIT
+package generated
+
+import (
+	"glox/token"
+)
+
+type MatchStmt struct {
+	Keyword token.Token
+	Subject Expr
+	Cases []*MatchCase
+}
+
+func NewMatchStmt(
+	Keyword token.Token,
+	Subject Expr,
+	Cases []*MatchCase,
+) *MatchStmt {
+	return &MatchStmt {
+		Keyword: Keyword,
+		Subject: Subject,
+		Cases: Cases,
+	}
+}
+
+func (x *MatchStmt) Accept(visitor VisitorStmt) (interface{}, error) {
+	return visitor.VisitMatchStmt(x)
+}
+
+func (x *MatchStmt) encode(e *encoder) {
+	e.token(x.Keyword)
+	e.expr(x.Subject)
+	e.matchCases(x.Cases)
+}
+
+func (x *MatchStmt) decode(d *decoder) {
+	x.Keyword = d.token()
+	x.Subject = d.expr()
+	x.Cases = d.matchCases()
+}
@@ -3,16 +3,20 @@
 package generated
 
 import (
+	"glox/token"
 )
 
 type PrintStmt struct {
+	Keyword token.Token
 	Expr Expr
 }
 
 func NewPrintStmt(
+	Keyword token.Token,
 	Expr Expr,
 ) *PrintStmt {
 	return &PrintStmt {
+		Keyword: Keyword,
 		Expr: Expr,
 	}
 }
@@ -20,3 +24,13 @@ func NewPrintStmt(
 func (x *PrintStmt) Accept(visitor VisitorStmt) (interface{}, error) {
 	return visitor.VisitPrintStmt(x)
 }
+
+func (x *PrintStmt) encode(e *encoder) {
+	e.token(x.Keyword)
+	e.expr(x.Expr)
+}
+
+func (x *PrintStmt) decode(d *decoder) {
+	x.Keyword = d.token()
+	x.Expr = d.expr()
+}
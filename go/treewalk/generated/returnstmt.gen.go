@@ -24,3 +24,13 @@ func NewReturnStmt(
 func (x *ReturnStmt) Accept(visitor VisitorStmt) (interface{}, error) {
 	return visitor.VisitReturnStmt(x)
 }
+
+func (x *ReturnStmt) encode(e *encoder) {
+	e.token(x.Keyword)
+	e.expr(x.Value)
+}
+
+func (x *ReturnStmt) decode(d *decoder) {
+	x.Keyword = d.token()
+	x.Value = d.expr()
+}
@@ -3,20 +3,24 @@
 package generated
 
 import (
+	"glox/token"
 )
 
 type Ternary struct {
+	Question token.Token
 	Condition Expr
 	ValueTrue Expr
 	ValueFalse Expr
 }
 
 func NewTernary(
+	Question token.Token,
 	Condition Expr,
 	ValueTrue Expr,
 	ValueFalse Expr,
 ) *Ternary {
 	return &Ternary {
+		Question: Question,
 		Condition: Condition,
 		ValueTrue: ValueTrue,
 		ValueFalse: ValueFalse,
@@ -26,3 +30,17 @@ func NewTernary(
 func (x *Ternary) Accept(visitor VisitorExpr) (interface{}, error) {
 	return visitor.VisitTernary(x)
 }
+
+func (x *Ternary) encode(e *encoder) {
+	e.token(x.Question)
+	e.expr(x.Condition)
+	e.expr(x.ValueTrue)
+	e.expr(x.ValueFalse)
+}
+
+func (x *Ternary) decode(d *decoder) {
+	x.Question = d.token()
+	x.Condition = d.expr()
+	x.ValueTrue = d.expr()
+	x.ValueFalse = d.expr()
+}
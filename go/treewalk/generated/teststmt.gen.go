@@ -0,0 +1,41 @@
+
+// generated code - DO NOT EDIT
+package generated
+
+import (
+	"glox/token"
+)
+
+type TestStmt struct {
+	Keyword token.Token
+	Name token.Token
+	Body []Stmt
+}
+
+func NewTestStmt(
+	Keyword token.Token,
+	Name token.Token,
+	Body []Stmt,
+) *TestStmt {
+	return &TestStmt {
+		Keyword: Keyword,
+		Name: Name,
+		Body: Body,
+	}
+}
+
+func (x *TestStmt) Accept(visitor VisitorStmt) (interface{}, error) {
+	return visitor.VisitTestStmt(x)
+}
+
+func (x *TestStmt) encode(e *encoder) {
+	e.token(x.Keyword)
+	e.token(x.Name)
+	e.stmts(x.Body)
+}
+
+func (x *TestStmt) decode(d *decoder) {
+	x.Keyword = d.token()
+	x.Name = d.token()
+	x.Body = d.stmts()
+}
@@ -0,0 +1,36 @@
+
+// generated code - DO NOT EDIT
+package generated
+
+import (
+	"glox/token"
+)
+
+type ThrowStmt struct {
+	Keyword token.Token
+	Value Expr
+}
+
+func NewThrowStmt(
+	Keyword token.Token,
+	Value Expr,
+) *ThrowStmt {
+	return &ThrowStmt {
+		Keyword: Keyword,
+		Value: Value,
+	}
+}
+
+func (x *ThrowStmt) Accept(visitor VisitorStmt) (interface{}, error) {
+	return visitor.VisitThrowStmt(x)
+}
+
+func (x *ThrowStmt) encode(e *encoder) {
+	e.token(x.Keyword)
+	e.expr(x.Value)
+}
+
+func (x *ThrowStmt) decode(d *decoder) {
+	x.Keyword = d.token()
+	x.Value = d.expr()
+}
@@ -0,0 +1,51 @@
+
+// generated code - DO NOT EDIT
+package generated
+
+import (
+	"glox/token"
+)
+
+type TryStmt struct {
+	Keyword token.Token
+	Body []Stmt
+	CatchName token.Token
+	CatchBody []Stmt
+	FinallyBody []Stmt
+}
+
+func NewTryStmt(
+	Keyword token.Token,
+	Body []Stmt,
+	CatchName token.Token,
+	CatchBody []Stmt,
+	FinallyBody []Stmt,
+) *TryStmt {
+	return &TryStmt {
+		Keyword: Keyword,
+		Body: Body,
+		CatchName: CatchName,
+		CatchBody: CatchBody,
+		FinallyBody: FinallyBody,
+	}
+}
+
+func (x *TryStmt) Accept(visitor VisitorStmt) (interface{}, error) {
+	return visitor.VisitTryStmt(x)
+}
+
+func (x *TryStmt) encode(e *encoder) {
+	e.token(x.Keyword)
+	e.stmts(x.Body)
+	e.token(x.CatchName)
+	e.stmts(x.CatchBody)
+	e.stmts(x.FinallyBody)
+}
+
+func (x *TryStmt) decode(d *decoder) {
+	x.Keyword = d.token()
+	x.Body = d.stmts()
+	x.CatchName = d.token()
+	x.CatchBody = d.stmts()
+	x.FinallyBody = d.stmts()
+}
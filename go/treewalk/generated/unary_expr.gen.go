@@ -24,3 +24,13 @@ func NewUnary(
 func (x *Unary) Accept(visitor VisitorExpr) (interface{}, error) {
 	return visitor.VisitUnary(x)
 }
+
+func (x *Unary) encode(e *encoder) {
+	e.token(x.Operator)
+	e.expr(x.Right)
+}
+
+func (x *Unary) decode(d *decoder) {
+	x.Operator = d.token()
+	x.Right = d.expr()
+}
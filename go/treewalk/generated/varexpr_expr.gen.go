@@ -21,3 +21,11 @@ func NewVarExpr(
 func (x *VarExpr) Accept(visitor VisitorExpr) (interface{}, error) {
 	return visitor.VisitVarExpr(x)
 }
+
+func (x *VarExpr) encode(e *encoder) {
+	e.token(x.Name)
+}
+
+func (x *VarExpr) decode(d *decoder) {
+	x.Name = d.token()
+}
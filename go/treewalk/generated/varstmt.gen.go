@@ -8,15 +8,18 @@ import (
 
 type VarStmt struct {
 	Name token.Token
+	Type token.Token
 	Initializer Expr
 }
 
 func NewVarStmt(
 	Name token.Token,
+	Type token.Token,
 	Initializer Expr,
 ) *VarStmt {
 	return &VarStmt {
 		Name: Name,
+		Type: Type,
 		Initializer: Initializer,
 	}
 }
@@ -24,3 +27,15 @@ func NewVarStmt(
 func (x *VarStmt) Accept(visitor VisitorStmt) (interface{}, error) {
 	return visitor.VisitVarStmt(x)
 }
+
+func (x *VarStmt) encode(e *encoder) {
+	e.token(x.Name)
+	e.token(x.Type)
+	e.expr(x.Initializer)
+}
+
+func (x *VarStmt) decode(d *decoder) {
+	x.Name = d.token()
+	x.Type = d.token()
+	x.Initializer = d.expr()
+}
@@ -12,4 +12,6 @@ type VisitorExpr interface {
 	VisitUnary (unary *Unary) (interface{}, error)
 	VisitCall (call *Call) (interface{}, error)
 	VisitVarExpr (varexpr *VarExpr) (interface{}, error)
+	VisitGet (get *Get) (interface{}, error)
+	VisitMatchExpr (matchexpr *MatchExpr) (interface{}, error)
 }
@@ -11,4 +11,9 @@ type VisitorStmt interface {
 	VisitVarStmt (varstmt *VarStmt) (interface{}, error)
 	VisitFunctionStmt (functionstmt *FunctionStmt) (interface{}, error)
 	VisitReturnStmt (returnstmt *ReturnStmt) (interface{}, error)
+	VisitImportStmt (importstmt *ImportStmt) (interface{}, error)
+	VisitThrowStmt (throwstmt *ThrowStmt) (interface{}, error)
+	VisitTryStmt (trystmt *TryStmt) (interface{}, error)
+	VisitMatchStmt (matchstmt *MatchStmt) (interface{}, error)
+	VisitTestStmt (teststmt *TestStmt) (interface{}, error)
 }
@@ -3,18 +3,22 @@
 package generated
 
 import (
+	"glox/token"
 )
 
 type WhileStmt struct {
+	Keyword token.Token
 	Condition Expr
 	Stmt Stmt
 }
 
 func NewWhileStmt(
+	Keyword token.Token,
 	Condition Expr,
 	Stmt Stmt,
 ) *WhileStmt {
 	return &WhileStmt {
+		Keyword: Keyword,
 		Condition: Condition,
 		Stmt: Stmt,
 	}
@@ -23,3 +27,15 @@ func NewWhileStmt(
 func (x *WhileStmt) Accept(visitor VisitorStmt) (interface{}, error) {
 	return visitor.VisitWhileStmt(x)
 }
+
+func (x *WhileStmt) encode(e *encoder) {
+	e.token(x.Keyword)
+	e.expr(x.Condition)
+	e.stmt(x.Stmt)
+}
+
+func (x *WhileStmt) decode(d *decoder) {
+	x.Keyword = d.token()
+	x.Condition = d.expr()
+	x.Stmt = d.stmt()
+}
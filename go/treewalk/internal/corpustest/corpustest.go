@@ -0,0 +1,107 @@
+// Package corpustest checks that compiled scripts of the corpus in
+// testdata/corpus behave as the interpreter does.
+package corpustest
+
+import (
+	"bytes"
+	"glox/interpreter"
+	"glox/parser"
+	"glox/resolver"
+	"glox/scanner"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+// args are the command line arguments every script is run with.
+var args = []string{"a", "b"}
+
+// Scripts returns the paths of the scripts in the corpus, leaving out the
+// modules they import.
+func Scripts(t *testing.T) []string {
+	t.Helper()
+
+	_, file, _, ok := runtime.Caller(0)
+	if !ok {
+		t.Fatal("cannot locate the corpus")
+	}
+
+	scripts, err := filepath.Glob(filepath.Join(filepath.Dir(file), "..", "..", "testdata", "corpus", "*.lox"))
+	if err != nil || len(scripts) == 0 {
+		t.Fatalf("no scripts in the corpus: %v", err)
+	}
+
+	return scripts
+}
+
+// Name returns the name of a script, without its directory or extension.
+func Name(script string) string {
+	return strings.TrimSuffix(filepath.Base(script), ".lox")
+}
+
+// Compare runs each script in a subtest named after it, expecting the
+// command returned by compiled to print what the interpreter prints and
+// exit as it does. The command is given the same arguments as the
+// interpreted script, and an empty stdin.
+func Compare(t *testing.T, scripts []string, compiled func(t *testing.T, script string) *exec.Cmd) {
+	for _, script := range scripts {
+		t.Run(Name(script), func(t *testing.T) {
+			want, wantStatus := interpret(t, script)
+
+			cmd := compiled(t, script)
+			cmd.Args = append(cmd.Args, args...)
+			out, err := cmd.Output()
+			status := 0
+			if exit, ok := err.(*exec.ExitError); ok {
+				status = exit.ExitCode()
+			} else if err != nil {
+				t.Fatal(err)
+			}
+
+			if string(out) != want {
+				t.Errorf("output differs:\n--- compiled\n%s--- interpreted\n%s", out, want)
+			}
+			if status != wantStatus {
+				t.Errorf("exit status %d, want %d", status, wantStatus)
+			}
+		})
+	}
+}
+
+// interpret runs a script as glox would with args and an empty stdin,
+// returning what it printed and its exit status.
+func interpret(t *testing.T, script string) (string, int) {
+	t.Helper()
+
+	source, err := os.ReadFile(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tokens, err := scanner.NewScanner(string(source)).ScanTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts, err := parser.NewParser(tokens).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out bytes.Buffer
+	in := interpreter.NewInterpreter(
+		interpreter.WithScriptPath(script),
+		interpreter.WithArgs(args),
+		interpreter.WithStdin(strings.NewReader("")),
+		interpreter.WithStdout(&out),
+	)
+	if err := resolver.NewResolver(in).Resolve(stmts); err != nil {
+		t.Fatal(err)
+	}
+
+	status := in.Interpret(stmts)
+
+	return out.String(), status
+}
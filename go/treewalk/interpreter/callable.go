@@ -1,7 +1,40 @@
 package interpreter
 
+import (
+	"fmt"
+	"glox/lerr"
+	"glox/token"
+)
+
+// variadic is the maximum arity of a callable accepting any number of
+// arguments.
+const variadic = -1
+
 type LoxCallable interface {
-	Arity() int
+	// Arity returns the minimum and maximum number of arguments accepted.
+	// A maximum of variadic means there is no upper limit.
+	Arity() (int, int)
 	Call(Interpreter, []interface{}) (interface{}, error)
 	String() string
 }
+
+// CheckArity reports a call to function, made at paren, with a number of
+// arguments it does not accept.
+func CheckArity(function LoxCallable, paren token.Token, got int) error {
+	min, max := function.Arity()
+	if got >= min && (max == variadic || got <= max) {
+		return nil
+	}
+
+	var msg string
+	switch {
+	case min == max:
+		msg = fmt.Sprintf("Expected %d arguments but got %d.", min, got)
+	case got < min:
+		msg = fmt.Sprintf("Expected at least %d arguments but got %d.", min, got)
+	default:
+		msg = fmt.Sprintf("Expected at most %d arguments but got %d.", max, got)
+	}
+
+	return lerr.NewRuntimeErr(paren, msg)
+}
@@ -6,8 +6,8 @@ var _ LoxCallable = (*clock)(nil)
 
 type clock struct{}
 
-func (c clock) Arity() int {
-	return 0
+func (c clock) Arity() (int, int) {
+	return 0, 0
 }
 
 func (c clock) Call(_ Interpreter, _ []interface{}) (interface{}, error) {
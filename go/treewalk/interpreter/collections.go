@@ -0,0 +1,170 @@
+package interpreter
+
+import (
+	"fmt"
+	"glox/environment"
+)
+
+// defineCollections registers the natives used to build and inspect lists
+// and maps in the global environment.
+func defineCollections(g *environment.Environment) {
+	for _, n := range []native{
+		{name: "list", minArity: 0, maxArity: variadic, fn: collList},
+		{name: "map", minArity: 0, maxArity: 0, fn: collMap},
+		{name: "push", minArity: 2, maxArity: 2, fn: collPush},
+		{name: "pop", minArity: 1, maxArity: 1, fn: collPop},
+		{name: "get", minArity: 2, maxArity: 2, fn: collGet},
+		{name: "set", minArity: 3, maxArity: 3, fn: collSet},
+		{name: "has", minArity: 2, maxArity: 2, fn: collHas},
+		{name: "remove", minArity: 2, maxArity: 2, fn: collRemove},
+		{name: "keys", minArity: 1, maxArity: 1, fn: collKeys},
+	} {
+		g.Define(n.name, &n)
+	}
+}
+
+// collList returns a new list holding its arguments.
+func collList(_ Interpreter, args []interface{}) (interface{}, error) {
+	return newList(append([]interface{}{}, args...)), nil
+}
+
+func collMap(_ Interpreter, _ []interface{}) (interface{}, error) {
+	return newDict(), nil
+}
+
+func collPush(_ Interpreter, args []interface{}) (interface{}, error) {
+	l, err := listArg("push", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	l.Elements = append(l.Elements, args[1])
+
+	return nil, nil
+}
+
+func collPop(_ Interpreter, args []interface{}) (interface{}, error) {
+	l, err := listArg("pop", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(l.Elements) == 0 {
+		return nil, argErr("pop", "list is empty.")
+	}
+
+	last := l.Elements[len(l.Elements)-1]
+	l.Elements = l.Elements[:len(l.Elements)-1]
+
+	return last, nil
+}
+
+// collGet returns the element at an index of a list or the value for a key
+// of a map. Missing map keys yield nil.
+func collGet(_ Interpreter, args []interface{}) (interface{}, error) {
+	switch c := args[0].(type) {
+	case *list:
+		idx, err := listIndex("get", c, args, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		return c.Elements[idx], nil
+	case *dict:
+		key, err := stringArg("get", args, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		v, _ := c.Get(key)
+		return v, nil
+	}
+
+	return nil, argErr("get", "argument 1 must be a list or a map.")
+}
+
+func collSet(_ Interpreter, args []interface{}) (interface{}, error) {
+	switch c := args[0].(type) {
+	case *list:
+		idx, err := listIndex("set", c, args, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		c.Elements[idx] = args[2]
+		return nil, nil
+	case *dict:
+		key, err := stringArg("set", args, 1)
+		if err != nil {
+			return nil, err
+		}
+
+		c.Set(key, args[2])
+		return nil, nil
+	}
+
+	return nil, argErr("set", "argument 1 must be a list or a map.")
+}
+
+func collHas(_ Interpreter, args []interface{}) (interface{}, error) {
+	d, err := dictArg("has", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := stringArg("has", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	_, ok := d.Get(key)
+
+	return ok, nil
+}
+
+func collRemove(_ Interpreter, args []interface{}) (interface{}, error) {
+	d, err := dictArg("remove", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	key, err := stringArg("remove", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	d.Delete(key)
+
+	return nil, nil
+}
+
+func collKeys(_ Interpreter, args []interface{}) (interface{}, error) {
+	d, err := dictArg("keys", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]interface{}, 0, len(d.Keys()))
+	for _, k := range d.Keys() {
+		keys = append(keys, k)
+	}
+
+	return newList(keys), nil
+}
+
+func listIndex(name string, l *list, args []interface{}, idx int) (int, error) {
+	i, err := intArg(name, args, idx)
+	if err != nil {
+		return 0, err
+	}
+
+	if i < 0 {
+		i += len(l.Elements)
+	}
+
+	if i < 0 || i >= len(l.Elements) {
+		return 0, argErr(name, fmt.Sprintf("index %d out of bounds for length %d.", i, len(l.Elements)))
+	}
+
+	return i, nil
+}
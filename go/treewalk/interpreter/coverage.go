@@ -0,0 +1,46 @@
+package interpreter
+
+import "glox/generated"
+
+// Coverage is told which parts of a script run.
+//
+// Branches are numbered within their node. An IfStmt takes branch 0 when
+// its condition holds and branch 1 otherwise, whether or not it has an
+// else clause. A Logical takes branch 0 when its left operand decides the
+// result and branch 1 when its right operand is evaluated. A Ternary takes
+// branch 0 when its condition holds and branch 1 otherwise.
+type Coverage interface {
+	// Load is called with the statements of the main script and of every
+	// imported module before they run. path is the canonical path of the
+	// module, or "" when the script was not run from a file.
+	Load(path string, stmts []generated.Stmt)
+
+	// Statement is called before every statement runs.
+	Statement(stmt generated.Stmt)
+
+	// Call is called whenever a Lox function is called.
+	Call(decl *generated.FunctionStmt)
+
+	// Branch is called with an IfStmt, Logical or Ternary and the branch
+	// it takes.
+	Branch(node interface{}, branch int)
+}
+
+// WithCoverage installs a coverage collector.
+func WithCoverage(c Coverage) Option {
+	return func(i *interpreter) {
+		i.coverage = c
+	}
+}
+
+func (i *interpreter) branch(node interface{}, taken bool) {
+	if i.coverage == nil {
+		return
+	}
+
+	if taken {
+		i.coverage.Branch(node, 0)
+	} else {
+		i.coverage.Branch(node, 1)
+	}
+}
@@ -0,0 +1,83 @@
+package interpreter
+
+import (
+	"errors"
+	"glox/environment"
+	"glox/generated"
+)
+
+// Frame is an active call as seen by a Debugger.
+type Frame struct {
+	// Name is the name of the function, or "script" for top-level code.
+	Name string
+
+	// Path is the canonical path of the module the statement being
+	// executed belongs to, or "" when the script was not run from a file.
+	Path string
+
+	// Line is the line of the statement being executed in the frame.
+	Line int
+
+	// Env is the innermost environment of the frame. It is enclosed by the
+	// environments of outer blocks, then the function's closure, and
+	// finally the module's globals and the builtins.
+	Env *environment.Environment
+}
+
+// Debugger is told about every statement before it runs, except blocks,
+// which only group the statements they contain. stack holds the active
+// calls, innermost first. Statements run on the caller's goroutine, so a
+// Debugger pauses the script by blocking in Break, and stops it by
+// returning an error, usually ErrStopped, which ends the script like a
+// runtime error that try cannot catch.
+type Debugger interface {
+	Break(stack []Frame) error
+}
+
+// ErrStopped is returned by a Debugger whose user stopped the script.
+var ErrStopped = errors.New("Script stopped by the debugger.")
+
+// WithDebugger installs a debugger. Without one the interpreter does not
+// keep track of frames.
+func WithDebugger(d Debugger) Option {
+	return func(i *interpreter) {
+		i.debugger = d
+	}
+}
+
+func (i *interpreter) debug(stmt generated.Stmt) error {
+	if _, ok := stmt.(*generated.BlockStmt); ok {
+		return nil
+	}
+
+	top := &i.frames[len(i.frames)-1]
+	top.Path = i.module.path
+	top.Line = generated.StmtLine(stmt)
+	top.Env = i.Env
+
+	stack := make([]Frame, 0, len(i.frames))
+	for idx := len(i.frames) - 1; idx >= 0; idx-- {
+		stack = append(stack, i.frames[idx])
+	}
+
+	return i.debugger.Break(stack)
+}
+
+// pushFrame records a call to the function named name for the debugger and
+// returns a func popping it again.
+func (i *interpreter) pushFrame(name string) func() {
+	if i.debugger == nil {
+		return func() {}
+	}
+
+	i.frames = append(i.frames, Frame{Name: name})
+
+	return func() {
+		i.frames = i.frames[:len(i.frames)-1]
+	}
+}
+
+// Stringify returns the string print shows for a value.
+func Stringify(v interface{}) string {
+	return (&interpreter{}).stringify(v)
+}
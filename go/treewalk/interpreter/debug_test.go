@@ -0,0 +1,62 @@
+package interpreter_test
+
+import (
+	"errors"
+	"glox/interpreter"
+	"glox/parser"
+	"glox/resolver"
+	"glox/scanner"
+	"strings"
+	"testing"
+)
+
+// stopAt stops the script when it reaches line.
+type stopAt int
+
+func (line stopAt) Break(stack []interpreter.Frame) error {
+	if stack[0].Line == int(line) {
+		return interpreter.ErrStopped
+	}
+
+	return nil
+}
+
+// TestStop checks that a debugger stopping the script ends it at once,
+// past any catch, without reporting an error.
+func TestStop(t *testing.T) {
+	source := `var a = 1;
+fun f() {
+  a = 2;
+}
+try { f(); } catch (e) { a = 3; }
+a = 4;
+`
+	tokens, err := scanner.NewScanner(source).ScanTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts, err := parser.NewParser(tokens).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var out strings.Builder
+	in := interpreter.NewInterpreter(interpreter.WithDebugger(stopAt(3)), interpreter.WithStdout(&out))
+	if err := resolver.NewResolver(in).Resolve(stmts); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := in.Run(stmts); !errors.Is(err, interpreter.ErrStopped) {
+		t.Fatalf("got %v, want %v", err, interpreter.ErrStopped)
+	}
+	if got := global(t, in.GetGlobalEnv(), "a"); got != int64(1) {
+		t.Errorf("a = %v, want 1", got)
+	}
+
+	if status := in.Interpret(stmts); status != 70 {
+		t.Errorf("got status %d, want 70", status)
+	}
+	if out.Len() != 0 {
+		t.Errorf("got output %q, want none", out.String())
+	}
+}
@@ -0,0 +1,60 @@
+package interpreter
+
+import "strings"
+
+// dict is the runtime value for a string-keyed Lox map. Keys remember their
+// insertion order so that printing and serialization are deterministic.
+type dict struct {
+	keys    []string
+	Entries map[string]interface{}
+}
+
+func newDict() *dict {
+	return &dict{Entries: make(map[string]interface{})}
+}
+
+func (d *dict) Get(key string) (interface{}, bool) {
+	v, ok := d.Entries[key]
+	return v, ok
+}
+
+func (d *dict) Set(key string, value interface{}) {
+	if _, ok := d.Entries[key]; !ok {
+		d.keys = append(d.keys, key)
+	}
+
+	d.Entries[key] = value
+}
+
+func (d *dict) Delete(key string) {
+	if _, ok := d.Entries[key]; !ok {
+		return
+	}
+
+	delete(d.Entries, key)
+	for idx, k := range d.keys {
+		if k == key {
+			d.keys = append(d.keys[:idx], d.keys[idx+1:]...)
+			break
+		}
+	}
+}
+
+func (d *dict) Keys() []string {
+	return d.keys
+}
+
+func (d *dict) String(stringify func(interface{}) string) string {
+	parts := make([]string, 0, len(d.keys))
+	for _, k := range d.keys {
+		v := d.Entries[k]
+		if s, ok := v.(string); ok {
+			parts = append(parts, "\""+k+"\": \""+s+"\"")
+			continue
+		}
+
+		parts = append(parts, "\""+k+"\": "+stringify(v))
+	}
+
+	return "{" + strings.Join(parts, ", ") + "}"
+}
@@ -0,0 +1,105 @@
+package interpreter
+
+import (
+	"math"
+	"testing"
+)
+
+func TestFormatFloat(t *testing.T) {
+	tests := []struct {
+		f    float64
+		want string
+	}{
+		// Special values.
+		{math.NaN(), "nan"},
+		{math.Inf(1), "inf"},
+		{math.Inf(-1), "-inf"},
+		{0, "0"},
+		{math.Copysign(0, -1), "-0"},
+
+		// Integral floats print without a decimal point, the same as
+		// integers do.
+		{2, "2"},
+		{-2, "-2"},
+		{1e20, "100000000000000000000"},
+		{9223372036854775807, "9223372036854776000"},
+
+		// From 1e21 up, exponent notation with an unpadded exponent.
+		{1e21, "1e+21"},
+		{1.5e21, "1.5e+21"},
+		{-1e21, "-1e+21"},
+		{1e100, "1e+100"},
+		{math.MaxFloat64, "1.7976931348623157e+308"},
+
+		// Down to 1e-6 fractions are written out, below it in exponent
+		// notation.
+		{0.5, "0.5"},
+		{1e-6, "0.000001"},
+		{1e-7, "1e-7"},
+		{-1.25e-7, "-1.25e-7"},
+		{5e-324, "5e-324"},
+
+		// The shortest spelling that parses back to the same float.
+		{0.1, "0.1"},
+		{math.Nextafter(0.3, 1), "0.30000000000000004"},
+		{1.0 / 3, "0.3333333333333333"},
+		{123456.789, "123456.789"},
+		{9007199254740993, "9007199254740992"},
+	}
+
+	for _, tt := range tests {
+		if got := formatFloat(tt.f); got != tt.want {
+			t.Errorf("formatFloat(%v) = %q, want %q", tt.f, got, tt.want)
+		}
+	}
+}
+
+func TestStringify(t *testing.T) {
+	tests := []struct {
+		name string
+		v    interface{}
+		want string
+	}{
+		{"nil", nil, "nil"},
+		{"true", true, "true"},
+		{"string", "a b", "a b"},
+		{"int", int64(-42), "-42"},
+		{"negative zero", math.Copysign(0, -1), "-0"},
+		{"nan", math.NaN(), "nan"},
+
+		// 4 / 2 is the float 2.0, which prints like the integer 2 although
+		// only the integer is accepted by the bitwise operators. Lox
+		// spells numbers by value, as JavaScript does, rather than by type.
+		{"integral float", 2.0, "2"},
+		{"integer", int64(2), "2"},
+
+		{"list", newList([]interface{}{int64(1), 2.5, "s", nil, newList(nil)}), `[1, 2.5, "s", nil, []]`},
+		{"map", func() interface{} {
+			d := newDict()
+			d.Set("b", 1e21)
+			d.Set("a", "x")
+			return d
+		}(), `{"b": 1e+21, "a": "x"}`},
+		{"list containing itself", func() interface{} {
+			l := newList([]interface{}{int64(1)})
+			l.Elements = append(l.Elements, l)
+			return l
+		}(), "[1, [...]]"},
+		{"map containing itself", func() interface{} {
+			d := newDict()
+			d.Set("self", d)
+			d.Set("l", newList([]interface{}{d}))
+			return d
+		}(), `{"self": {...}, "l": [{...}]}`},
+		{"list shared twice", func() interface{} {
+			inner := newList([]interface{}{int64(1)})
+			return newList([]interface{}{inner, inner})
+		}(), "[[1], [1]]"},
+	}
+
+	for _, tt := range tests {
+		if got := Stringify(tt.v); got != tt.want {
+			t.Errorf("%s: Stringify = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
@@ -1,8 +1,11 @@
 package interpreter
 
 import (
+	"fmt"
 	"glox/environment"
 	"glox/generated"
+	"glox/lerr"
+	"glox/token"
 )
 
 var _ LoxCallable = (*fun)(nil)
@@ -10,23 +13,124 @@ var _ LoxCallable = (*fun)(nil)
 type fun struct {
 	Declaration *generated.FunctionStmt
 	Closure     *environment.Environment
+	Module      *module
 }
 
-func (f fun) Arity() int {
-	return len(f.Declaration.Params)
+func (f fun) Arity() (int, int) {
+	min := 0
+	for _, def := range f.Declaration.Defaults {
+		if def == nil {
+			min++
+		}
+	}
+
+	if f.Declaration.Rest != nil {
+		return min, variadic
+	}
+
+	return min, len(f.Declaration.Params)
 }
 
 func (f fun) Call(in Interpreter, args []interface{}) (interface{}, error) {
+	return f.call(in.(*interpreter), args, nil, nil)
+}
+
+// call calls the function, then any function it tail calls, and so on,
+// until one returns a value. Tail calls made this way are not in the
+// trace of an error, except for the one the error was raised in.
+func (f fun) call(in *interpreter, args []interface{}, names []token.Token, named []interface{}) (interface{}, error) {
+	var paren token.Token
+	for {
+		value, err := f.run(in, args, names, named)
+
+		ret, ok := err.(*Return)
+		if !ok || ret.tail == nil {
+			if err != nil && paren != nil {
+				AddFrame(err, &f, paren)
+			}
+
+			return value, err
+		}
+
+		next := ret.tail
+		if in.profiler != nil {
+			in.profiler.Exit()
+			in.profileCall(next.callee)
+		}
+
+		f, paren = *next.callee.(*fun), next.paren
+		args, names, named = next.args, next.names, next.named
+	}
+}
+
+// run binds positional and named arguments to the function's parameters
+// and runs its body. Parameters given neither take their default, which is
+// evaluated in the new call environment so that it can see the parameters
+// bound before it. Surplus positional arguments are collected into the rest
+// parameter. A tail call the body returns is passed on as a *Return.
+func (f fun) run(in *interpreter, args []interface{}, names []token.Token, named []interface{}) (interface{}, error) {
 	env := environment.NewEnvironment(f.Closure)
+	defer in.enterModule(f.Module)()
+	defer in.pushFrame(f.Declaration.Name.GetLexeme())()
+
+	if in.coverage != nil {
+		in.coverage.Call(f.Declaration)
+	}
 
-	for i, param := range f.Declaration.Params {
-		env.Define(param.GetLexeme(), args[i])
+	if f.Declaration.Rest == nil && len(args) > len(f.Declaration.Params) {
+		return nil, lerr.NewRuntimeErr(f.Declaration.Name, fmt.Sprintf("Expected at most %d arguments but got %d.", len(f.Declaration.Params), len(args)))
+	}
+
+	bound := make(map[string]bool)
+	for idx, name := range names {
+		if !f.hasParam(name.GetLexeme()) {
+			return nil, lerr.NewRuntimeErr(name, fmt.Sprintf("%s has no parameter '%s'.", f.String(), name.GetLexeme()))
+		}
+
+		if bound[name.GetLexeme()] || f.paramIndex(name.GetLexeme()) < len(args) {
+			return nil, lerr.NewRuntimeErr(name, fmt.Sprintf("Argument '%s' given more than once.", name.GetLexeme()))
+		}
+
+		bound[name.GetLexeme()] = true
+		env.Define(name.GetLexeme(), named[idx])
+	}
+
+	for idx, param := range f.Declaration.Params {
+		if idx < len(args) {
+			env.Define(param.GetLexeme(), args[idx])
+			continue
+		}
+
+		if bound[param.GetLexeme()] {
+			continue
+		}
+
+		def := f.Declaration.Defaults[idx]
+		if def == nil {
+			return nil, lerr.NewRuntimeErr(param, fmt.Sprintf("Missing argument '%s' to %s.", param.GetLexeme(), f.String()))
+		}
+
+		value, err := in.evaluateIn(def, env)
+		if err != nil {
+			return nil, err
+		}
+
+		env.Define(param.GetLexeme(), value)
+	}
+
+	if f.Declaration.Rest != nil {
+		rest := []interface{}{}
+		if len(args) > len(f.Declaration.Params) {
+			rest = append(rest, args[len(f.Declaration.Params):]...)
+		}
+
+		env.Define(f.Declaration.Rest.GetLexeme(), newList(rest))
 	}
 
 	_, err := in.ExecuteBlock(f.Declaration.Body, env)
 	if err != nil {
-		if _, ok := err.(*Return); ok {
-			return err.(*Return).Value, nil
+		if ret, ok := err.(*Return); ok && ret.tail == nil {
+			return ret.Value, nil
 		}
 
 		return nil, err
@@ -35,6 +139,20 @@ func (f fun) Call(in Interpreter, args []interface{}) (interface{}, error) {
 	return nil, nil
 }
 
+func (f fun) hasParam(name string) bool {
+	return f.paramIndex(name) >= 0
+}
+
+func (f fun) paramIndex(name string) int {
+	for idx, param := range f.Declaration.Params {
+		if param.GetLexeme() == name {
+			return idx
+		}
+	}
+
+	return -1
+}
+
 func (f fun) String() string {
-	return "<native fn " + f.Declaration.Name.GetLexeme() + ">"
+	return "<fn " + f.Declaration.Name.GetLexeme() + ">"
 }
@@ -1,17 +1,24 @@
 package interpreter
 
 import (
+	"bufio"
+	"errors"
 	"fmt"
 	"glox/environment"
 	"glox/generated"
 	"glox/lerr"
 	"glox/token"
+	"io"
+	"math/rand"
 	"os"
 	"reflect"
+	"strconv"
+	"time"
 )
 
 type Interpreter interface {
-	Interpret([]generated.Stmt)
+	Interpret([]generated.Stmt) int
+	Run([]generated.Stmt) error
 	GetGlobalEnv() *environment.Environment
 	generated.VisitorExpr
 	generated.VisitorStmt
@@ -23,18 +30,78 @@ type interpreter struct {
 	GlobalEnv *environment.Environment
 	Env       *environment.Environment
 	Locals    map[generated.Expr]int
+
+	builtins  *environment.Environment
+	module    *module
+	modules   map[string]*module
+	importing []string
+	path      string
+
+	rand   *rand.Rand
+	stdin  *bufio.Reader
+	stdout io.Writer
+	args   []string
+
+	debugger Debugger
+	frames   []Frame
+	profiler Profiler
+	coverage Coverage
+	tests    Tests
+
+	optimizer Optimizer
+	tails     map[*generated.Call]bool
+	cache     bool
 }
 
-func NewInterpreter() Interpreter {
-	g := environment.NewEnvironment(nil)
+func NewInterpreter(opts ...Option) Interpreter {
+	b := environment.NewEnvironment(nil)
 
-	g.Define("clock", &clock{})
+	b.Define("clock", &clock{})
+	defineStrings(b)
+	defineMath(b)
+	defineRandom(b)
+	defineIO(b)
+	defineCollections(b)
+	defineJSON(b)
+	defineAssert(b)
 
 	in := &interpreter{
-		GlobalEnv: g,
+		Locals:   make(map[generated.Expr]int),
+		tails:    make(map[*generated.Call]bool),
+		builtins: b,
+		modules:  make(map[string]*module),
+		rand:     rand.New(rand.NewSource(time.Now().UnixNano())),
+		stdin:    newStdin(os.Stdin),
+		stdout:   os.Stdout,
+	}
+
+	for _, opt := range opts {
+		opt(in)
+	}
+
+	args := make([]interface{}, 0, len(in.args))
+	for _, arg := range in.args {
+		args = append(args, arg)
 	}
+	b.Define("args", newList(args))
 
+	// The main script is registered as a module that never finishes
+	// loading, so importing it back is reported as a cycle.
+	in.module = &module{
+		Env:     environment.NewEnvironment(b),
+		loading: true,
+	}
+	if in.path != "" {
+		if path, err := canonicalPath(in.path); err == nil {
+			in.module.path = path
+			in.modules[path] = in.module
+			in.importing = []string{path}
+		}
+	}
+
+	in.GlobalEnv = in.module.Env
 	in.Env = in.GlobalEnv
+	in.frames = []Frame{{Name: "script", Env: in.Env}}
 
 	return in
 }
@@ -51,17 +118,56 @@ func (i *interpreter) Resolve(expr generated.Expr, depth int) {
 	i.Locals[expr] = depth
 }
 
-func (i *interpreter) Interpret(stmts []generated.Stmt) {
+// Interpret executes a script, reporting the runtime error that stopped it
+// on the interpreter's output. It returns the exit status for the script:
+// 70 if it failed and 0 otherwise. A script stopped by its debugger is not
+// reported, but still fails.
+func (i *interpreter) Interpret(stmts []generated.Stmt) int {
+	err := i.Run(stmts)
+	if errors.Is(err, ErrStopped) {
+		return 70
+	}
+	if err != nil {
+		fmt.Fprintf(i.stdout, "Error while interpreting : %v\n", err)
+		return 70
+	}
+
+	return 0
+}
+
+// Run executes a script like Interpret, but returns the runtime error that
+// stopped it rather than reporting it.
+func (i *interpreter) Run(stmts []generated.Stmt) error {
+	if i.optimizer != nil {
+		stmts = i.optimizer.Optimize(stmts)
+	}
+	if i.coverage != nil {
+		i.coverage.Load(i.module.path, stmts)
+	}
+
 	for _, stmt := range stmts {
 		_, err := i.execute(stmt)
 		if err != nil {
-			fmt.Printf("Error while interpreting : %v\n", err)
-			os.Exit(70)
+			return err
 		}
 	}
+
+	return nil
 }
 
 func (i *interpreter) execute(stmt generated.Stmt) (interface{}, error) {
+	if i.debugger != nil {
+		if err := i.debug(stmt); err != nil {
+			return nil, err
+		}
+	}
+	if i.profiler != nil {
+		i.profileLine(stmt)
+	}
+	if i.coverage != nil {
+		i.coverage.Statement(stmt)
+	}
+
 	return stmt.Accept(i)
 }
 
@@ -87,6 +193,10 @@ func (i *interpreter) VisitReturnStmt(returnstmt *generated.ReturnStmt) (interfa
 	var value interface{}
 	var err error
 
+	if call, ok := returnstmt.Value.(*generated.Call); ok && i.tails[call] {
+		return i.returnCall(call)
+	}
+
 	if returnstmt.Value != nil {
 		value, err = i.evaluate(returnstmt.Value)
 		if err != nil {
@@ -97,6 +207,48 @@ func (i *interpreter) VisitReturnStmt(returnstmt *generated.ReturnStmt) (interfa
 	return value, &Return{Value: value}
 }
 
+func (i *interpreter) VisitThrowStmt(throwstmt *generated.ThrowStmt) (interface{}, error) {
+	value, err := i.evaluate(throwstmt.Value)
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, &Throw{Value: value, Keyword: throwstmt.Keyword}
+}
+
+func (i *interpreter) VisitTryStmt(trystmt *generated.TryStmt) (interface{}, error) {
+	_, err := i.executeBlock(trystmt.Body, environment.NewEnvironment(i.Env))
+
+	if err != nil && trystmt.CatchName != nil {
+		if value, ok := Caught(err); ok {
+			env := environment.NewEnvironment(i.Env)
+			env.Define(trystmt.CatchName.GetLexeme(), value)
+
+			_, err = i.executeBlock(trystmt.CatchBody, environment.NewEnvironment(env))
+		}
+	}
+
+	// finally runs however the try and catch blocks were left, including
+	// by return. An error or return from finally itself takes precedence.
+	if trystmt.FinallyBody != nil {
+		_, ferr := i.executeBlock(trystmt.FinallyBody, environment.NewEnvironment(i.Env))
+		if ferr != nil {
+			return nil, ferr
+		}
+	}
+
+	return nil, err
+}
+
+func (i *interpreter) VisitMatchStmt(matchstmt *generated.MatchStmt) (interface{}, error) {
+	mc, env, err := i.selectCase(matchstmt.Subject, matchstmt.Cases)
+	if err != nil || mc == nil {
+		return nil, err
+	}
+
+	return i.executeBlock([]generated.Stmt{mc.Body}, env)
+}
+
 func (i *interpreter) VisitExprStmt(exprstmt *generated.ExprStmt) (interface{}, error) {
 	return i.evaluate(exprstmt.Expr)
 }
@@ -107,7 +259,10 @@ func (i *interpreter) VisitIfStmt(ifstmt *generated.IfStmt) (interface{}, error)
 		return nil, err
 	}
 
-	if i.isTruthy(val) {
+	taken := i.isTruthy(val)
+	i.branch(ifstmt, taken)
+
+	if taken {
 		return i.execute(ifstmt.IfBranch)
 	} else if ifstmt.ElseBranch != nil {
 		return i.execute(ifstmt.ElseBranch)
@@ -122,7 +277,7 @@ func (i *interpreter) VisitPrintStmt(printstmt *generated.PrintStmt) (interface{
 		return nil, err
 	}
 
-	fmt.Printf("%s\n", i.stringify(value))
+	fmt.Fprintf(i.stdout, "%s\n", i.stringify(value))
 
 	return nil, nil
 }
@@ -137,24 +292,21 @@ func (i *interpreter) VisitBlockStmt(blockstmt *generated.BlockStmt) (interface{
 }
 
 func (i *interpreter) VisitWhileStmt(whilestmt *generated.WhileStmt) (interface{}, error) {
-	c, err := i.evaluate(whilestmt.Condition)
-	if err != nil {
-		return nil, err
-	}
-
-	for i.isTruthy(c) {
-		c, err = i.evaluate(whilestmt.Condition)
+	for {
+		c, err := i.evaluate(whilestmt.Condition)
 		if err != nil {
 			return nil, err
 		}
 
-		_, err := i.execute(whilestmt.Stmt)
+		if !i.isTruthy(c) {
+			return nil, nil
+		}
+
+		_, err = i.execute(whilestmt.Stmt)
 		if err != nil {
 			return nil, err
 		}
 	}
-
-	return nil, nil
 }
 
 func (i *interpreter) VisitVarStmt(varstmt *generated.VarStmt) (interface{}, error) {
@@ -173,7 +325,7 @@ func (i *interpreter) VisitVarStmt(varstmt *generated.VarStmt) (interface{}, err
 }
 
 func (i *interpreter) VisitFunctionStmt(funstmt *generated.FunctionStmt) (interface{}, error) {
-	function := &fun{Declaration: funstmt, Closure: i.Env}
+	function := &fun{Declaration: funstmt, Closure: i.Env, Module: i.module}
 	i.Env.Define(funstmt.Name.GetLexeme(), function)
 	return nil, nil
 }
@@ -186,10 +338,14 @@ func (i *interpreter) VisitAssign(assign *generated.Assign) (interface{}, error)
 
 	dist, ok := i.Locals[assign]
 	if ok {
-		i.Env.AssignAt(dist, assign.Name, value)
+		err = i.Env.AssignAt(dist, assign.Name, value)
 	} else {
-		i.GlobalEnv.Assign(assign.Name, value)
+		err = i.GlobalEnv.Assign(assign.Name, value)
+	}
+	if err != nil {
+		return nil, err
 	}
+
 	return value, nil
 }
 
@@ -199,45 +355,82 @@ func (i *interpreter) VisitLogical(logical *generated.Logical) (interface{}, err
 		return nil, err
 	}
 
-	if logical.Operator.GetType() == token.OR {
-		if i.isTruthy(left) {
-			return left, nil
-		}
-	} else {
-		if !i.isTruthy(left) {
-			return left, nil
-		}
+	decided := i.isTruthy(left)
+	if logical.Operator.GetType() != token.OR {
+		decided = !decided
+	}
+
+	i.branch(logical, decided)
+	if decided {
+		return left, nil
 	}
 
 	return i.evaluate(logical.Right)
 }
 
 func (i *interpreter) VisitCall(call *generated.Call) (interface{}, error) {
+	pending, err := i.evaluateCall(call)
+	if err != nil {
+		return nil, err
+	}
+
+	return i.invoke(pending)
+}
+
+// evaluateCall evaluates the callee and arguments of a call.
+func (i *interpreter) evaluateCall(call *generated.Call) (*pendingCall, error) {
 	callee, err := i.evaluate(call.Callee)
 	if err != nil {
 		return nil, err
 	}
 
-	args := make([]interface{}, 0)
-	for _, arg := range call.Arguments {
+	pending := &pendingCall{paren: call.Paren, args: make([]interface{}, 0)}
+	for idx, arg := range call.Arguments {
 		value, err := i.evaluate(arg)
 		if err != nil {
 			return nil, err
 		}
 
-		args = append(args, value)
+		if call.Names[idx] != nil {
+			pending.names = append(pending.names, call.Names[idx])
+			pending.named = append(pending.named, value)
+			continue
+		}
+
+		pending.args = append(pending.args, value)
 	}
 
 	function, ok := (callee).(LoxCallable)
 	if !ok {
 		return nil, lerr.NewRuntimeErr(call.Paren, "Can only call functions and classes.")
 	}
+	pending.callee = function
+
+	return pending, nil
+}
+
+// invoke makes an evaluated call.
+func (i *interpreter) invoke(pending *pendingCall) (interface{}, error) {
+	done := i.profileCall(pending.callee)
+	defer done()
+
+	err := pending.check()
+	if err != nil {
+		return nil, err
+	}
 
-	if len(args) != function.Arity() {
-		return nil, lerr.NewRuntimeErr(call.Paren, fmt.Sprintf("Expected %d arguments but got %d.", function.Arity(), len(args)))
+	var value interface{}
+	if f, ok := pending.callee.(*fun); ok && pending.names != nil {
+		value, err = f.call(i, pending.args, pending.names, pending.named)
+	} else {
+		value, err = pending.callee.Call(i, pending.args)
+	}
+	if err != nil {
+		AddFrame(err, pending.callee, pending.paren)
+		return nil, err
 	}
 
-	return function.Call(i, args)
+	return value, nil
 }
 
 func (i *interpreter) VisitBinary(binary *generated.Binary) (interface{}, error) {
@@ -251,69 +444,24 @@ func (i *interpreter) VisitBinary(binary *generated.Binary) (interface{}, error)
 		return nil, err
 	}
 
-	switch binary.Operator.GetType() {
-
-	case token.MINUS:
-		err := i.checkNumberOperands(binary.Operator, left, right)
-		if err != nil {
-			return nil, err
-		}
-
-		return left.(float64) - right.(float64), nil
-
-	case token.SLASH:
-		err := i.checkNumberOperands(binary.Operator, left, right)
-		if err != nil {
-			return nil, err
-		}
-		return left.(float64) / right.(float64), nil
-
-	case token.STAR:
-		err := i.checkNumberOperands(binary.Operator, left, right)
-		if err != nil {
-			return nil, err
-		}
-		return left.(float64) * right.(float64), nil
+	return Binary(binary.Operator, left, right)
+}
 
+// Binary applies a binary operator to the values of its operands.
+func Binary(operator token.Token, left, right interface{}) (interface{}, error) {
+	switch operator.GetType() {
 	case token.PLUS:
-		if reflect.TypeOf(right).Kind() == reflect.Float64 &&
-			reflect.TypeOf(left).Kind() == reflect.Float64 {
-			return left.(float64) + right.(float64), nil
-		}
-
-		if reflect.TypeOf(right).Kind() == reflect.String &&
-			reflect.TypeOf(left).Kind() == reflect.String {
-			return left.(string) + right.(string), nil
-		}
-
-		return nil, lerr.NewRuntimeErr(binary.Operator, "Operands must be two numbers or two strings.")
-	case token.GREATER:
-		err := i.checkNumberOperands(binary.Operator, left, right)
-		if err != nil {
-			return nil, err
+		if l, ok := left.(string); ok {
+			if r, ok := right.(string); ok {
+				return l + r, nil
+			}
 		}
-		return left.(float64) > right.(float64), nil
 
-	case token.GREATER_EQUAL:
-		err := i.checkNumberOperands(binary.Operator, left, right)
-		if err != nil {
-			return nil, err
-		}
-		return left.(float64) >= right.(float64), nil
-
-	case token.LESS:
-		err := i.checkNumberOperands(binary.Operator, left, right)
-		if err != nil {
-			return nil, err
+		if !isNumber(left) || !isNumber(right) {
+			return nil, lerr.NewRuntimeErr(operator, "Operands must be two numbers or two strings.")
 		}
-		return left.(float64) < right.(float64), nil
 
-	case token.LESS_EQUAL:
-		err := i.checkNumberOperands(binary.Operator, left, right)
-		if err != nil {
-			return nil, err
-		}
-		return left.(float64) <= right.(float64), nil
+		return arithmetic(operator, left, right)
 
 	case token.BANG_EQUAL:
 		return !isEqual(left, right), nil
@@ -321,7 +469,7 @@ func (i *interpreter) VisitBinary(binary *generated.Binary) (interface{}, error)
 		return isEqual(left, right), nil
 	}
 
-	return nil, nil
+	return arithmetic(operator, left, right)
 }
 
 func (i *interpreter) VisitTernary(ternary *generated.Ternary) (interface{}, error) {
@@ -329,24 +477,19 @@ func (i *interpreter) VisitTernary(ternary *generated.Ternary) (interface{}, err
 	if err != nil {
 		return nil, err
 	}
-	valueTrue, err := i.evaluate(ternary.ValueTrue)
-	if err != nil {
-		return nil, err
-	}
-	valueFalse, err := i.evaluate(ternary.ValueFalse)
-	if err != nil {
-		return nil, err
-	}
 
-	if i.isTruthy(cond) {
-		return valueTrue, nil
+	taken := i.isTruthy(cond)
+	i.branch(ternary, taken)
+
+	if taken {
+		return i.evaluate(ternary.ValueTrue)
 	}
 
-	return valueFalse, nil
+	return i.evaluate(ternary.ValueFalse)
 }
 
 func (i *interpreter) VisitGrouping(grouping *generated.Grouping) (interface{}, error) {
-	return i.evaluate(grouping)
+	return i.evaluate(grouping.Expression)
 }
 
 func (i *interpreter) VisitLiteral(literal *generated.Literal) (interface{}, error) {
@@ -359,20 +502,146 @@ func (i *interpreter) VisitUnary(unary *generated.Unary) (interface{}, error) {
 		return nil, err
 	}
 
-	switch unary.Operator.GetType() {
+	return Unary(unary.Operator, right)
+}
+
+// Unary applies a unary operator to the value of its operand.
+func Unary(operator token.Token, right interface{}) (interface{}, error) {
+	switch operator.GetType() {
 	case token.MINUS:
-		err := i.checkNumberOperands(unary.Operator, right)
-		if err != nil {
-			return nil, err
+		switch n := right.(type) {
+		case int64:
+			return -n, nil
+		case float64:
+			return -n, nil
+		}
+
+		return nil, lerr.NewRuntimeErr(operator, "Operand(s) must be a number(s).")
+	case token.TILDE:
+		n, ok := right.(int64)
+		if !ok {
+			return nil, lerr.NewRuntimeErr(operator, "Operand must be an integer.")
 		}
-		return -right.(float64), nil
+
+		return ^n, nil
 	case token.BANG:
-		return !i.isTruthy(right.(float64)), nil
+		return !Truthy(right), nil
+	}
+
+	return nil, nil
+}
+
+func (i *interpreter) VisitGet(get *generated.Get) (interface{}, error) {
+	object, err := i.evaluate(get.Object)
+	if err != nil {
+		return nil, err
+	}
+
+	return Property(object, get.Name)
+}
+
+// Property returns the property called name of a module or map.
+func Property(object interface{}, name token.Token) (interface{}, error) {
+	switch o := object.(type) {
+	case *module:
+		value, ok := o.Env.Lookup(name.GetLexeme())
+		if !ok {
+			return nil, lerr.NewRuntimeErr(name, fmt.Sprintf("Undefined property '%s' on %s.", name.GetLexeme(), o))
+		}
+
+		return value, nil
+	case *dict:
+		value, _ := o.Get(name.GetLexeme())
+		return value, nil
+	}
+
+	return nil, lerr.NewRuntimeErr(name, "Only modules and maps have properties.")
+}
+
+func (i *interpreter) VisitImportStmt(importstmt *generated.ImportStmt) (interface{}, error) {
+	m, err := i.importModule(importstmt.Path)
+	if err != nil {
+		return nil, err
+	}
+
+	if importstmt.Alias != nil {
+		i.Env.Define(importstmt.Alias.GetLexeme(), m)
+		return nil, nil
+	}
+
+	for _, name := range importstmt.Names {
+		value, ok := m.Env.Lookup(name.GetLexeme())
+		if !ok {
+			return nil, lerr.NewRuntimeErr(name, fmt.Sprintf("Module %s has no definition '%s'.", m, name.GetLexeme()))
+		}
+
+		i.Env.Define(name.GetLexeme(), value)
 	}
 
 	return nil, nil
 }
 
+func (i *interpreter) VisitMatchExpr(matchexpr *generated.MatchExpr) (interface{}, error) {
+	mc, env, err := i.selectCase(matchexpr.Subject, matchexpr.Cases)
+	if err != nil {
+		return nil, err
+	}
+
+	if mc == nil {
+		return nil, lerr.NewRuntimeErr(matchexpr.Keyword, "No match case for value.")
+	}
+
+	return i.evaluateIn(mc.Value, env)
+}
+
+// selectCase returns the first arm matching the value of subject together
+// with the environment its body runs in, or a nil arm if none matches.
+func (i *interpreter) selectCase(subject generated.Expr, cases []*generated.MatchCase) (*generated.MatchCase, *environment.Environment, error) {
+	value, err := i.evaluate(subject)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, mc := range cases {
+		matched := len(mc.Patterns) == 0
+		for _, pattern := range mc.Patterns {
+			p, err := i.evaluate(pattern)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if isEqual(value, p) {
+				matched = true
+				break
+			}
+		}
+
+		if !matched {
+			continue
+		}
+
+		env := environment.NewEnvironment(i.Env)
+		if mc.Binding != nil {
+			env.Define(mc.Binding.GetLexeme(), value)
+		}
+
+		if mc.Guard != nil {
+			guard, err := i.evaluateIn(mc.Guard, env)
+			if err != nil {
+				return nil, nil, err
+			}
+
+			if !i.isTruthy(guard) {
+				continue
+			}
+		}
+
+		return mc, env, nil
+	}
+
+	return nil, nil, nil
+}
+
 func (i *interpreter) VisitVarExpr(varexpr *generated.VarExpr) (interface{}, error) {
 	return i.lookUpVariable(varexpr.Name, varexpr)
 }
@@ -390,6 +659,18 @@ func (i *interpreter) evaluate(expr generated.Expr) (interface{}, error) {
 	return expr.Accept(i)
 }
 
+// evaluateIn evaluates expr with env as the current environment.
+func (i *interpreter) evaluateIn(expr generated.Expr, env *environment.Environment) (interface{}, error) {
+	previousEnv := i.Env
+	defer func() {
+		i.Env = previousEnv
+	}()
+
+	i.Env = env
+
+	return i.evaluate(expr)
+}
+
 func (i *interpreter) isTruthy(obj interface{}) bool {
 	if obj == nil {
 		return false
@@ -402,6 +683,11 @@ func (i *interpreter) isTruthy(obj interface{}) bool {
 	return true
 }
 
+// Equal reports whether two values are equal, as == does.
+func Equal(a, b interface{}) bool {
+	return isEqual(a, b)
+}
+
 func isEqual(a, b interface{}) bool {
 	if a == nil && b == nil {
 		return true
@@ -410,28 +696,49 @@ func isEqual(a, b interface{}) bool {
 		return false
 	}
 
-	return reflect.ValueOf(a).Equal(reflect.ValueOf(b))
-}
-
-func (i *interpreter) checkNumberOperands(operator token.Token, operands ...interface{}) error {
-	for _, operand := range operands {
-		if reflect.TypeOf(operand).Kind() != reflect.Float64 {
-			return lerr.NewRuntimeErr(operator, "Operand(s) must be a number(s).")
-
-		}
+	if isNumber(a) && isNumber(b) {
+		return numbersEqual(a, b)
 	}
 
-	return nil
+	return reflect.ValueOf(a).Equal(reflect.ValueOf(b))
 }
 
 func (i *interpreter) stringify(obj interface{}) string {
-	if obj == nil {
+	return stringifySeen(obj, nil)
+}
+
+// stringifySeen spells values for stringify. seen holds the collections
+// being spelled, which spell as [...] or {...} when they are reached again,
+// so that a collection containing itself can be printed.
+func stringifySeen(obj interface{}, seen map[interface{}]bool) string {
+	switch v := obj.(type) {
+	case nil:
 		return "nil"
-	}
+	case float64:
+		return formatFloat(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case *list, *dict:
+		if seen[v] {
+			if _, ok := v.(*list); ok {
+				return "[...]"
+			}
+			return "{...}"
+		}
+
+		if seen == nil {
+			seen = map[interface{}]bool{}
+		}
+		seen[v] = true
+		defer delete(seen, v)
 
-	if reflect.TypeOf(obj).Kind() == reflect.Float64 {
-		// could remove .0 from end
-		return fmt.Sprintf("%f", obj.(float64))
+		inner := func(e interface{}) string {
+			return stringifySeen(e, seen)
+		}
+		if l, ok := v.(*list); ok {
+			return l.String(inner)
+		}
+		return v.(*dict).String(inner)
 	}
 
 	return fmt.Sprintf("%v", obj)
@@ -0,0 +1,89 @@
+package interpreter_test
+
+import (
+	"glox/environment"
+	"glox/interpreter"
+	"glox/parser"
+	"glox/resolver"
+	"glox/scanner"
+	"testing"
+)
+
+func TestGrouping(t *testing.T) {
+	env := run(t, `var ok = (1 + 2) * 3 == 9 and ((true));`)
+
+	if got := global(t, env, "ok"); got != true {
+		t.Errorf("got %v, want true", got)
+	}
+}
+
+func TestLocals(t *testing.T) {
+	env := run(t, `var ok = false; { var a = 1; { var b = a + 1; ok = b == 2; } }`)
+
+	if got := global(t, env, "ok"); got != true {
+		t.Errorf("got %v, want true", got)
+	}
+}
+
+func TestWhile(t *testing.T) {
+	env := run(t, `var i = 0; var n = 0; while (i < 3) { n = n + 1; i = i + 1; } var ok = n == 3;`)
+
+	if got := global(t, env, "ok"); got != true {
+		t.Errorf("got %v, want true", got)
+	}
+}
+
+// TestTernary checks that only the chosen branch of a ternary is
+// evaluated.
+func TestTernary(t *testing.T) {
+	env := run(t, `var a = 0; var b = true ? 1 : (a = 1); var c = false ? (a = 2) : 2; var ok = a == 0 and b == 1 and c == 2;`)
+
+	if got := global(t, env, "ok"); got != true {
+		t.Errorf("got %v, want true", got)
+	}
+}
+
+// TestNot checks that ! negates the truthiness of every kind of value:
+// only nil and false are falsy.
+func TestNot(t *testing.T) {
+	env := run(t, `var ok = !nil and !false and !!true and !!0 and !!0.0 and !!"" and !!list() and !!map() and !!clock;`)
+
+	if got := global(t, env, "ok"); got != true {
+		t.Errorf("got %v, want true", got)
+	}
+}
+
+// run resolves and runs source, returning the global environment it
+// leaves behind.
+func run(t *testing.T, source string) *environment.Environment {
+	t.Helper()
+
+	tokens, err := scanner.NewScanner(source).ScanTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stmts, err := parser.NewParser(tokens).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	in := interpreter.NewInterpreter()
+	if err := resolver.NewResolver(in).Resolve(stmts); err != nil {
+		t.Fatal(err)
+	}
+	in.Interpret(stmts)
+
+	return in.GetGlobalEnv()
+}
+
+// global returns the value of the global variable called name.
+func global(t *testing.T, env *environment.Environment, name string) interface{} {
+	t.Helper()
+
+	value, err := env.GetAt(0, name)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return value
+}
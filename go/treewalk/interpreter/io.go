@@ -0,0 +1,119 @@
+package interpreter
+
+import (
+	"bufio"
+	"errors"
+	"glox/environment"
+	"io"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// defineIO registers the file and stdin natives in the global environment.
+// Failures from the underlying Go calls are reported as runtime errors.
+func defineIO(g *environment.Environment) {
+	for _, n := range []native{
+		{name: "readFile", minArity: 1, maxArity: 1, fn: ioReadFile},
+		{name: "writeFile", minArity: 2, maxArity: 2, fn: ioWriteFile},
+		{name: "appendFile", minArity: 2, maxArity: 2, fn: ioAppendFile},
+		{name: "exists", minArity: 1, maxArity: 1, fn: ioExists},
+		{name: "readLine", minArity: 0, maxArity: 0, fn: ioReadLine},
+	} {
+		g.Define(n.name, &n)
+	}
+}
+
+func ioReadFile(_ Interpreter, args []interface{}) (interface{}, error) {
+	path, err := stringArg("readFile", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, argErr("readFile", err.Error())
+	}
+
+	return string(b), nil
+}
+
+func ioWriteFile(_ Interpreter, args []interface{}) (interface{}, error) {
+	path, err := stringArg("writeFile", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := stringArg("writeFile", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	err = os.WriteFile(path, []byte(s), 0644)
+	if err != nil {
+		return nil, argErr("writeFile", err.Error())
+	}
+
+	return nil, nil
+}
+
+func ioAppendFile(_ Interpreter, args []interface{}) (interface{}, error) {
+	path, err := stringArg("appendFile", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	s, err := stringArg("appendFile", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, argErr("appendFile", err.Error())
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(s)
+	if err != nil {
+		return nil, argErr("appendFile", err.Error())
+	}
+
+	return nil, nil
+}
+
+func ioExists(_ Interpreter, args []interface{}) (interface{}, error) {
+	path, err := stringArg("exists", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = os.Stat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return false, nil
+	} else if err != nil {
+		return nil, argErr("exists", err.Error())
+	}
+
+	return true, nil
+}
+
+// ioReadLine reads one line from the interpreter's input without its line
+// terminator, returning nil once the input is exhausted.
+func ioReadLine(in Interpreter, _ []interface{}) (interface{}, error) {
+	line, err := in.(*interpreter).stdin.ReadString('\n')
+	if errors.Is(err, io.EOF) && line == "" {
+		return nil, nil
+	} else if err != nil && !errors.Is(err, io.EOF) {
+		return nil, argErr("readLine", err.Error())
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+
+	return line, nil
+}
+
+func newStdin(r io.Reader) *bufio.Reader {
+	return bufio.NewReader(r)
+}
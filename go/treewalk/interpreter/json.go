@@ -0,0 +1,224 @@
+package interpreter
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"glox/environment"
+	"io"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// defineJSON registers the JSON natives in the global environment. JSON
+// objects map onto Lox maps, arrays onto lists and null onto nil.
+func defineJSON(g *environment.Environment) {
+	for _, n := range []native{
+		{name: "jsonParse", minArity: 1, maxArity: 1, fn: jsonParse},
+		{name: "jsonStringify", minArity: 1, maxArity: 2, fn: jsonStringify},
+	} {
+		g.Define(n.name, &n)
+	}
+}
+
+func jsonParse(_ Interpreter, args []interface{}) (interface{}, error) {
+	s, err := stringArg("jsonParse", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(strings.NewReader(s))
+	dec.UseNumber()
+
+	value, err := decodeJSON(dec)
+	if err != nil {
+		return nil, argErr("jsonParse", err.Error())
+	}
+
+	if _, err := dec.Token(); !errors.Is(err, io.EOF) {
+		return nil, argErr("jsonParse", "unexpected data after top-level value.")
+	}
+
+	return value, nil
+}
+
+// decodeJSON reads one value from dec. It walks the token stream rather
+// than unmarshalling into map[string]interface{} so that object keys keep
+// their source order.
+func decodeJSON(dec *json.Decoder) (interface{}, error) {
+	tok, err := dec.Token()
+	if err != nil {
+		return nil, err
+	}
+
+	switch t := tok.(type) {
+	case json.Delim:
+		switch t {
+		case '[':
+			elements := []interface{}{}
+			for dec.More() {
+				e, err := decodeJSON(dec)
+				if err != nil {
+					return nil, err
+				}
+				elements = append(elements, e)
+			}
+
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+
+			return newList(elements), nil
+		case '{':
+			d := newDict()
+			for dec.More() {
+				key, err := dec.Token()
+				if err != nil {
+					return nil, err
+				}
+
+				value, err := decodeJSON(dec)
+				if err != nil {
+					return nil, err
+				}
+
+				d.Set(key.(string), value)
+			}
+
+			if _, err := dec.Token(); err != nil {
+				return nil, err
+			}
+
+			return d, nil
+		}
+
+		return nil, fmt.Errorf("unexpected %v", t)
+	case json.Number:
+		if n, err := t.Int64(); err == nil {
+			return n, nil
+		}
+
+		return t.Float64()
+	}
+
+	return tok, nil
+}
+
+const maxIndent = 10
+
+// jsonStringify encodes a Lox value as JSON. The optional indent is either
+// the number of spaces to indent nested values by or the indent string
+// itself; omitting it, 0, "" and nil produce compact output. Like
+// JSON.stringify, numbers indent by at most maxIndent spaces.
+func jsonStringify(_ Interpreter, args []interface{}) (interface{}, error) {
+	var indent string
+	if len(args) < 2 {
+		args = append(args, nil)
+	}
+
+	switch v := args[1].(type) {
+	case nil:
+	case string:
+		indent = v
+	case int64, float64:
+		n, err := intArg("jsonStringify", args, 1)
+		if err != nil {
+			return nil, err
+		}
+		if n < 0 {
+			return nil, argErr("jsonStringify", "indent must not be negative.")
+		}
+		indent = strings.Repeat(" ", min(n, maxIndent))
+	default:
+		return nil, argErr("jsonStringify", "indent must be a number or a string.")
+	}
+
+	var buf bytes.Buffer
+	err := encodeJSON(&buf, args[0], map[interface{}]bool{})
+	if err != nil {
+		return nil, argErr("jsonStringify", err.Error())
+	}
+
+	if indent == "" {
+		return buf.String(), nil
+	}
+
+	var out bytes.Buffer
+	err = json.Indent(&out, buf.Bytes(), "", indent)
+	if err != nil {
+		return nil, argErr("jsonStringify", err.Error())
+	}
+
+	return out.String(), nil
+}
+
+// encodeJSON writes the compact encoding of value to buf. seen holds the
+// lists and maps currently being encoded so that cycles are reported
+// instead of recursing forever.
+func encodeJSON(buf *bytes.Buffer, value interface{}, seen map[interface{}]bool) error {
+	switch v := value.(type) {
+	case nil:
+		buf.WriteString("null")
+	case bool:
+		buf.WriteString(strconv.FormatBool(v))
+	case float64:
+		if math.IsNaN(v) || math.IsInf(v, 0) {
+			return fmt.Errorf("cannot encode %s.", formatFloat(v))
+		}
+		buf.WriteString(strconv.FormatFloat(v, 'g', -1, 64))
+	case int64:
+		buf.WriteString(strconv.FormatInt(v, 10))
+	case string:
+		b, err := json.Marshal(v)
+		if err != nil {
+			return err
+		}
+		buf.Write(b)
+	case *list:
+		if seen[v] {
+			return errors.New("cannot encode cyclic list.")
+		}
+		seen[v] = true
+		defer delete(seen, v)
+
+		buf.WriteByte('[')
+		for idx, e := range v.Elements {
+			if idx > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeJSON(buf, e, seen); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+	case *dict:
+		if seen[v] {
+			return errors.New("cannot encode cyclic map.")
+		}
+		seen[v] = true
+		defer delete(seen, v)
+
+		buf.WriteByte('{')
+		for idx, k := range v.Keys() {
+			if idx > 0 {
+				buf.WriteByte(',')
+			}
+			if err := encodeJSON(buf, k, seen); err != nil {
+				return err
+			}
+			buf.WriteByte(':')
+			if err := encodeJSON(buf, v.Entries[k], seen); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+	case LoxCallable:
+		return fmt.Errorf("cannot encode function %s.", v.String())
+	default:
+		return fmt.Errorf("cannot encode %v.", v)
+	}
+
+	return nil
+}
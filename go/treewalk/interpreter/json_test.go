@@ -0,0 +1,106 @@
+package interpreter_test
+
+import (
+	"glox/interpreter"
+	"glox/playground"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// quote lets the sources below spell JSON's double quotes as single ones,
+// since Lox strings have no escapes.
+const quote = `fun q(s) { return replace(s, "'", chr(34)); }` + "\n"
+
+func TestJSONParse(t *testing.T) {
+	tests := []struct {
+		json string
+		want string
+	}{
+		{`{'b': 1, 'a': [true, null, 2.5], 'c': {}}`, `{"b": 1, "a": [true, nil, 2.5], "c": {}}`},
+		{`'café'`, `café`},
+		{`12345678901234567890`, `12345678901234567000`},
+		{`-0.5e-3`, `-0.0005`},
+		{`2.0`, `2`},
+		{` [] `, `[]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.json, func(t *testing.T) {
+			result := playground.Eval(quote + "print jsonParse(q(\"" + tt.json + "\"));")
+			if result.Status != 0 {
+				t.Fatalf("failed: %+v", result.Diagnostics)
+			}
+
+			if got := strings.TrimSuffix(result.Stdout, "\n"); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestJSONStringify(t *testing.T) {
+	tests := []struct {
+		args string
+		want string
+	}{
+		{`jsonParse(q("{'b': [1, 2.5], 'a': null}"))`, `{"b":[1,2.5],"a":null}`},
+		{`q("'<&>")`, `"\"\u003c\u0026\u003e"`},
+		{`list(1, list()), 1`, "[\n 1,\n []\n]"},
+		{`list(1), "--"`, "[\n--1\n]"},
+		{`list(1), 20`, "[\n          1\n]"},
+		{`list(1), 0`, `[1]`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.args, func(t *testing.T) {
+			result := playground.Eval(quote + "print jsonStringify(" + tt.args + ");")
+			if result.Status != 0 {
+				t.Fatalf("failed: %+v", result.Diagnostics)
+			}
+
+			if got := strings.TrimSuffix(result.Stdout, "\n"); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestJSONErrors checks that the values JSON cannot hold are reported in
+// the terms Lox prints them in.
+func TestJSONErrors(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"main.lox", "lib.lox"} {
+		if err := os.WriteFile(filepath.Join(dir, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		call string
+		want string
+	}{
+		{`jsonStringify(0 / 0)`, "jsonStringify: cannot encode nan."},
+		{`jsonStringify(list(-1 / 0))`, "jsonStringify: cannot encode -inf."},
+		{`jsonStringify(clock)`, "jsonStringify: cannot encode function <native fn>."},
+		{`jsonStringify(lib)`, "jsonStringify: cannot encode <module lib>."},
+		{`jsonStringify(1, -1)`, "jsonStringify: indent must not be negative."},
+		{`jsonParse("[1,")`, "jsonParse: unexpected end of JSON input"},
+		{`jsonParse("[1] 2")`, "jsonParse: unexpected data after top-level value."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.call, func(t *testing.T) {
+			result := playground.Eval(`import "lib.lox" as lib; `+tt.call+";",
+				interpreter.WithScriptPath(filepath.Join(dir, "main.lox")))
+			if len(result.Diagnostics) != 1 {
+				t.Fatalf("got %+v, want one error", result)
+			}
+
+			if got := result.Diagnostics[0].Message; got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,32 @@
+package interpreter
+
+import "strings"
+
+// list is the runtime value for an ordered sequence of Lox values.
+type list struct {
+	Elements []interface{}
+}
+
+func newList(elements []interface{}) *list {
+	return &list{Elements: elements}
+}
+
+// NewList returns a list holding elements, for programs that build Lox
+// values without an interpreter, such as compiled ones.
+func NewList(elements []interface{}) interface{} {
+	return newList(elements)
+}
+
+func (l *list) String(stringify func(interface{}) string) string {
+	parts := make([]string, 0, len(l.Elements))
+	for _, e := range l.Elements {
+		if s, ok := e.(string); ok {
+			parts = append(parts, "\""+s+"\"")
+			continue
+		}
+
+		parts = append(parts, stringify(e))
+	}
+
+	return "[" + strings.Join(parts, ", ") + "]"
+}
@@ -0,0 +1,155 @@
+package interpreter
+
+import (
+	"glox/environment"
+	"math"
+)
+
+// defineMath registers the math standard library in the global environment.
+func defineMath(g *environment.Environment) {
+	g.Define("pi", math.Pi)
+	g.Define("inf", math.Inf(1))
+	g.Define("nan", math.NaN())
+
+	for _, n := range []native{
+		mathRounding("floor", math.Floor),
+		mathRounding("ceil", math.Ceil),
+		mathRounding("round", math.Round),
+		mathUnary("sqrt", math.Sqrt),
+		{name: "abs", minArity: 1, maxArity: 1, fn: mathAbs},
+		mathUnary("sin", math.Sin),
+		mathUnary("cos", math.Cos),
+		mathUnary("log", math.Log),
+		mathUnary("exp", math.Exp),
+		mathBinary("pow", math.Pow),
+		mathBinary("atan2", math.Atan2),
+		mathExtreme("min", false),
+		mathExtreme("max", true),
+		{name: "isNaN", minArity: 1, maxArity: 1, fn: mathIsNaN},
+	} {
+		g.Define(n.name, &n)
+	}
+}
+
+func mathUnary(name string, f func(float64) float64) native {
+	return native{
+		name:     name,
+		minArity: 1, maxArity: 1,
+		fn: func(_ Interpreter, args []interface{}) (interface{}, error) {
+			x, err := numberArg(name, args, 0)
+			if err != nil {
+				return nil, err
+			}
+
+			return f(x), nil
+		},
+	}
+}
+
+func mathBinary(name string, f func(float64, float64) float64) native {
+	return native{
+		name:     name,
+		minArity: 2, maxArity: 2,
+		fn: func(_ Interpreter, args []interface{}) (interface{}, error) {
+			x, err := numberArg(name, args, 0)
+			if err != nil {
+				return nil, err
+			}
+
+			y, err := numberArg(name, args, 1)
+			if err != nil {
+				return nil, err
+			}
+
+			return f(x, y), nil
+		},
+	}
+}
+
+// mathRounding wraps a rounding function so that it returns an integer
+// whenever the result fits in one.
+func mathRounding(name string, f func(float64) float64) native {
+	return native{
+		name:     name,
+		minArity: 1,
+		maxArity: 1,
+		fn: func(_ Interpreter, args []interface{}) (interface{}, error) {
+			if n, ok := args[0].(int64); ok {
+				return n, nil
+			}
+
+			x, err := numberArg(name, args, 0)
+			if err != nil {
+				return nil, err
+			}
+
+			return floatToInt(f(x)), nil
+		},
+	}
+}
+
+func mathAbs(_ Interpreter, args []interface{}) (interface{}, error) {
+	if n, ok := args[0].(int64); ok {
+		if n < 0 {
+			return -n, nil
+		}
+
+		return n, nil
+	}
+
+	x, err := numberArg("abs", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return math.Abs(x), nil
+}
+
+// mathExtreme returns a native picking the smallest (or, with largest,
+// the largest) of one or more numbers. Integers are compared exactly and
+// returned as integers; a nan argument makes the result nan.
+func mathExtreme(name string, largest bool) native {
+	return native{
+		name:     name,
+		minArity: 1,
+		maxArity: variadic,
+		fn: func(_ Interpreter, args []interface{}) (interface{}, error) {
+			var best interface{}
+			for idx, arg := range args {
+				x, err := numberArg(name, args, idx)
+				if err != nil {
+					return nil, err
+				}
+
+				if math.IsNaN(x) {
+					return math.NaN(), nil
+				}
+
+				if best == nil || numberLess(arg, best) != largest {
+					best = arg
+				}
+			}
+
+			return best, nil
+		},
+	}
+}
+
+func numberLess(a, b interface{}) bool {
+	ai, aok := a.(int64)
+	bi, bok := b.(int64)
+	if aok && bok {
+		return ai < bi
+	}
+
+	af, _ := toFloat(a)
+	bf, _ := toFloat(b)
+
+	return af < bf
+}
+
+func mathIsNaN(_ Interpreter, args []interface{}) (interface{}, error) {
+	x, ok := args[0].(float64)
+
+	return ok && math.IsNaN(x), nil
+}
@@ -0,0 +1,166 @@
+package interpreter
+
+import (
+	"fmt"
+	"glox/cache"
+	"glox/environment"
+	"glox/generated"
+	"glox/lerr"
+	"glox/parser"
+	"glox/resolver"
+	"glox/scanner"
+	"glox/token"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// module is a single Lox source file and the environment holding its
+// top-level definitions. The main script is a module too, so that
+// functions always see the globals of the file they were declared in.
+type module struct {
+	path    string
+	Env     *environment.Environment
+	loading bool
+}
+
+func (m *module) String() string {
+	if m.path == "" {
+		return "<module>"
+	}
+
+	return "<module " + strings.TrimSuffix(filepath.Base(m.path), filepath.Ext(m.path)) + ">"
+}
+
+// enterModule makes m the current module, so that unresolved (global)
+// variables are looked up in its environment, and returns a function that
+// restores the previous one.
+func (i *interpreter) enterModule(m *module) func() {
+	prevModule, prevGlobals := i.module, i.GlobalEnv
+	i.module, i.GlobalEnv = m, m.Env
+
+	return func() {
+		i.module, i.GlobalEnv = prevModule, prevGlobals
+	}
+}
+
+// importModule loads the module at the path named by the given string
+// token, relative to the directory of the importing module. Each module is
+// executed at most once per interpreter; later imports share its
+// environment. A module that fails to load is run again by the next import
+// of it.
+func (i *interpreter) importModule(pathTok token.Token) (*module, error) {
+	rel := pathTok.GetLiteral().(string)
+
+	p := rel
+	if !filepath.IsAbs(p) {
+		dir := "."
+		if i.module.path != "" {
+			dir = filepath.Dir(i.module.path)
+		}
+		p = filepath.Join(dir, p)
+	}
+
+	path, err := canonicalPath(p)
+	if err != nil {
+		return nil, lerr.NewRuntimeErr(pathTok, fmt.Sprintf("Cannot import '%s': %v.", rel, err))
+	}
+
+	if m, ok := i.modules[path]; ok {
+		if m.loading {
+			chain := append(append([]string{}, i.importing...), path)
+			return nil, lerr.NewRuntimeErr(pathTok, "Import cycle: "+strings.Join(chain, " -> ")+".")
+		}
+
+		return m, nil
+	}
+
+	source, err := os.ReadFile(path)
+	if err != nil {
+		return nil, lerr.NewRuntimeErr(pathTok, fmt.Sprintf("Cannot import '%s': %v.", rel, err))
+	}
+
+	stmts, err := i.load(path, string(source))
+	if err != nil {
+		return nil, lerr.NewRuntimeErr(pathTok, fmt.Sprintf("Cannot import '%s': %v", rel, err))
+	}
+
+	m := &module{
+		path:    path,
+		Env:     environment.NewEnvironment(i.builtins),
+		loading: true,
+	}
+	i.modules[path] = m
+
+	i.importing = append(i.importing, path)
+	defer func() {
+		i.importing = i.importing[:len(i.importing)-1]
+	}()
+
+	defer i.enterModule(m)()
+
+	if i.coverage != nil {
+		i.coverage.Load(path, stmts)
+	}
+
+	_, err = i.executeBlock(stmts, m.Env)
+	if err != nil {
+		// Forget the module, so that importing it again runs it again
+		// rather than finding it still loading.
+		delete(i.modules, path)
+		return nil, err
+	}
+
+	m.loading = false
+
+	return m, nil
+}
+
+// load runs the front end over a module's source, and the optimizer if
+// there is one.
+func (i *interpreter) load(path, source string) ([]generated.Stmt, error) {
+	stmts, err := i.resolve(path, source)
+	if err != nil {
+		return nil, err
+	}
+
+	if i.optimizer != nil {
+		stmts = i.optimizer.Optimize(stmts)
+	}
+
+	return stmts, nil
+}
+
+// resolve scans, parses and resolves the source of the module at path, or
+// reads the result from the AST cache.
+func (i *interpreter) resolve(path, source string) ([]generated.Stmt, error) {
+	if i.cache {
+		return cache.Load(path, source, i)
+	}
+
+	tokens, err := scanner.NewScanner(source).ScanTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	stmts, err := parser.NewParser(tokens).Parse()
+	if err != nil {
+		return nil, err
+	}
+
+	err = resolver.NewResolver(i).Resolve(stmts)
+	if err != nil {
+		return nil, err
+	}
+
+	return stmts, nil
+}
+
+func canonicalPath(p string) (string, error) {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return "", err
+	}
+
+	return filepath.EvalSymlinks(abs)
+}
@@ -0,0 +1,86 @@
+package interpreter_test
+
+import (
+	"glox/interpreter"
+	"glox/playground"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestImports(t *testing.T) {
+	tests := []struct {
+		name    string
+		files   map[string]string
+		want    string
+		wantErr string
+	}{
+		{
+			name: "cycle",
+			files: map[string]string{
+				"main.lox": `import "a.lox" as a;`,
+				"a.lox":    `import "b.lox" as b;`,
+				"b.lox":    `import "a.lox" as a;`,
+			},
+			wantErr: "Import cycle: main.lox -> a.lox -> b.lox -> a.lox.",
+		},
+		{
+			name: "import of the main script",
+			files: map[string]string{
+				"main.lox": `import "a.lox" as a;`,
+				"a.lox":    `import "main.lox" as main;`,
+			},
+			wantErr: "Import cycle: main.lox -> a.lox -> main.lox.",
+		},
+		{
+			name: "retry after a failure",
+			files: map[string]string{
+				"main.lox": `
+					try { import "bad.lox" as b; } catch (e) { print e; }
+					try { import "bad.lox" as b; } catch (e) { print e; }
+					import "bad.lox" as b;`,
+				"bad.lox": `print "loading"; throw "broken";`,
+			},
+			want:    "loading\nbroken\nloading\nbroken\nloading\n",
+			wantErr: "Uncaught exception: broken",
+		},
+		{
+			name: "loaded once",
+			files: map[string]string{
+				"main.lox": `import "lib.lox" as a; import "lib.lox" as b; print a == b;`,
+				"lib.lox":  `print "loading";`,
+			},
+			want: "loading\ntrue\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			dir := t.TempDir()
+			for name, source := range tt.files {
+				if err := os.WriteFile(filepath.Join(dir, name), []byte(source), 0o644); err != nil {
+					t.Fatal(err)
+				}
+			}
+			dir, err := filepath.EvalSymlinks(dir)
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			result := playground.Eval(tt.files["main.lox"], interpreter.WithScriptPath(filepath.Join(dir, "main.lox")))
+
+			if result.Stdout != tt.want {
+				t.Errorf("printed %q, want %q", result.Stdout, tt.want)
+			}
+
+			var gotErr string
+			if len(result.Diagnostics) > 0 {
+				gotErr = strings.ReplaceAll(result.Diagnostics[0].Message, dir+string(filepath.Separator), "")
+			}
+			if gotErr != tt.wantErr {
+				t.Errorf("got error %q, want %q", gotErr, tt.wantErr)
+			}
+		})
+	}
+}
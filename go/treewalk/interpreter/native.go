@@ -0,0 +1,87 @@
+package interpreter
+
+import (
+	"fmt"
+	"glox/lerr"
+)
+
+var _ LoxCallable = (*native)(nil)
+
+// native adapts a plain Go function into a LoxCallable so that standard
+// library functions don't each need their own type like clock.
+type native struct {
+	name     string
+	minArity int
+	maxArity int
+	fn       func(Interpreter, []interface{}) (interface{}, error)
+}
+
+func (n native) Arity() (int, int) {
+	return n.minArity, n.maxArity
+}
+
+func (n native) Call(in Interpreter, args []interface{}) (interface{}, error) {
+	return n.fn(in, args)
+}
+
+func (n native) String() string {
+	return "<native fn " + n.name + ">"
+}
+
+func argErr(name string, msg string) error {
+	return lerr.NewRuntimeErr(nil, fmt.Sprintf("%s: %s", name, msg))
+}
+
+func stringArg(name string, args []interface{}, idx int) (string, error) {
+	s, ok := args[idx].(string)
+	if !ok {
+		return "", argErr(name, fmt.Sprintf("argument %d must be a string.", idx+1))
+	}
+
+	return s, nil
+}
+
+func numberArg(name string, args []interface{}, idx int) (float64, error) {
+	n, ok := toFloat(args[idx])
+	if !ok {
+		return 0, argErr(name, fmt.Sprintf("argument %d must be a number.", idx+1))
+	}
+
+	return n, nil
+}
+
+// intArg accepts an integer, or a float with an integral value.
+func intArg(name string, args []interface{}, idx int) (int, error) {
+	if n, ok := args[idx].(int64); ok {
+		return int(n), nil
+	}
+
+	n, err := numberArg(name, args, idx)
+	if err != nil {
+		return 0, err
+	}
+
+	if n != float64(int(n)) {
+		return 0, argErr(name, fmt.Sprintf("argument %d must be an integer.", idx+1))
+	}
+
+	return int(n), nil
+}
+
+func listArg(name string, args []interface{}, idx int) (*list, error) {
+	l, ok := args[idx].(*list)
+	if !ok {
+		return nil, argErr(name, fmt.Sprintf("argument %d must be a list.", idx+1))
+	}
+
+	return l, nil
+}
+
+func dictArg(name string, args []interface{}, idx int) (*dict, error) {
+	d, ok := args[idx].(*dict)
+	if !ok {
+		return nil, argErr(name, fmt.Sprintf("argument %d must be a map.", idx+1))
+	}
+
+	return d, nil
+}
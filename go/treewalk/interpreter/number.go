@@ -0,0 +1,208 @@
+package interpreter
+
+import (
+	"glox/lerr"
+	"glox/token"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// Lox has two number types: int64 for literals without a fractional part
+// and float64 for everything else. Arithmetic on two integers stays an
+// integer and wraps around on overflow, as two's complement arithmetic in
+// Go does, so 9223372036854775807 + 1 is -9223372036854775808. As soon as
+// either operand is a float the operation is carried out on floats.
+//
+// The exceptions are '/', which always divides as floats, and '~/', which
+// divides truncating toward zero, so that for integers
+// a == (a ~/ b) * b + a % b. Integer division or remainder by zero is a
+// runtime error; for floats it yields inf or nan.
+//
+// The bitwise operators & | ^ << >> and ~ only accept integers. '>>' is an
+// arithmetic shift, and shifting by 64 or more bits yields 0, or -1 when
+// shifting a negative number right.
+//
+// Negating -9223372036854775808 wraps around to itself, as do abs of it
+// and dividing it by -1 with '~/'. Comparing an integer with a float
+// converts the integer to the nearest float, so integers beyond 2^53 can
+// compare equal to floats they differ from: 9007199254740993 ==
+// 9007199254740992.0 is true.
+
+func isNumber(v interface{}) bool {
+	switch v.(type) {
+	case int64, float64:
+		return true
+	}
+
+	return false
+}
+
+// toFloat converts a number to float64, reporting whether v was a number.
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+
+	return 0, false
+}
+
+// arithmetic evaluates a binary operator on two numbers.
+func arithmetic(operator token.Token, left, right interface{}) (interface{}, error) {
+	if !isNumber(left) || !isNumber(right) {
+		return nil, lerr.NewRuntimeErr(operator, "Operand(s) must be a number(s).")
+	}
+
+	l, lok := left.(int64)
+	r, rok := right.(int64)
+	if lok && rok {
+		return intArithmetic(operator, l, r)
+	}
+
+	switch operator.GetType() {
+	case token.AMPERSAND, token.PIPE, token.CARET, token.LESS_LESS, token.GREATER_GREATER:
+		return nil, lerr.NewRuntimeErr(operator, "Operands must be integers.")
+	}
+
+	lf, _ := toFloat(left)
+	rf, _ := toFloat(right)
+
+	return floatArithmetic(operator, lf, rf)
+}
+
+func intArithmetic(operator token.Token, l, r int64) (interface{}, error) {
+	switch operator.GetType() {
+	case token.PLUS:
+		return l + r, nil
+	case token.MINUS:
+		return l - r, nil
+	case token.STAR:
+		return l * r, nil
+	case token.SLASH:
+		return float64(l) / float64(r), nil
+	case token.TILDE_SLASH:
+		if r == 0 {
+			return nil, lerr.NewRuntimeErr(operator, "Division by zero.")
+		}
+		return l / r, nil
+	case token.PERCENT:
+		if r == 0 {
+			return nil, lerr.NewRuntimeErr(operator, "Division by zero.")
+		}
+		return l % r, nil
+	case token.AMPERSAND:
+		return l & r, nil
+	case token.PIPE:
+		return l | r, nil
+	case token.CARET:
+		return l ^ r, nil
+	case token.LESS_LESS:
+		if r < 0 {
+			return nil, lerr.NewRuntimeErr(operator, "Negative shift count.")
+		}
+		return l << uint64(r), nil
+	case token.GREATER_GREATER:
+		if r < 0 {
+			return nil, lerr.NewRuntimeErr(operator, "Negative shift count.")
+		}
+		return l >> uint64(r), nil
+	case token.GREATER:
+		return l > r, nil
+	case token.GREATER_EQUAL:
+		return l >= r, nil
+	case token.LESS:
+		return l < r, nil
+	case token.LESS_EQUAL:
+		return l <= r, nil
+	}
+
+	return nil, lerr.NewRuntimeErr(operator, "Unknown operator.")
+}
+
+func floatArithmetic(operator token.Token, l, r float64) (interface{}, error) {
+	switch operator.GetType() {
+	case token.PLUS:
+		return l + r, nil
+	case token.MINUS:
+		return l - r, nil
+	case token.STAR:
+		return l * r, nil
+	case token.SLASH:
+		return l / r, nil
+	case token.TILDE_SLASH:
+		return math.Trunc(l / r), nil
+	case token.PERCENT:
+		return math.Mod(l, r), nil
+	case token.GREATER:
+		return l > r, nil
+	case token.GREATER_EQUAL:
+		return l >= r, nil
+	case token.LESS:
+		return l < r, nil
+	case token.LESS_EQUAL:
+		return l <= r, nil
+	}
+
+	return nil, lerr.NewRuntimeErr(operator, "Unknown operator.")
+}
+
+// numbersEqual compares two numbers by value, so that 1 == 1.0.
+func numbersEqual(a, b interface{}) bool {
+	ai, aok := a.(int64)
+	bi, bok := b.(int64)
+	if aok && bok {
+		return ai == bi
+	}
+
+	af, _ := toFloat(a)
+	bf, _ := toFloat(b)
+
+	return af == bf
+}
+
+// floatToInt converts f to an integer when it is integral and in range, so
+// that natives like floor can return integers, and returns f otherwise.
+func floatToInt(f float64) interface{} {
+	if f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return f
+	}
+
+	return int64(f)
+}
+
+// formatFloat returns the canonical spelling of a float: integral values
+// print without a decimal point, other values use the shortest
+// representation that parses back to the same float, and very large or
+// small magnitudes switch to exponent notation, as in JavaScript. The
+// special values print as nan, inf and -inf, and negative zero as -0.
+//
+// Numbers are spelled by value rather than by type, so the float 4 / 2
+// prints as 2, like the integer 2, although the bitwise operators only
+// accept the integer.
+func formatFloat(f float64) string {
+	switch {
+	case math.IsNaN(f):
+		return "nan"
+	case math.IsInf(f, 1):
+		return "inf"
+	case math.IsInf(f, -1):
+		return "-inf"
+	case f == 0 && math.Signbit(f):
+		return "-0"
+	}
+
+	abs := math.Abs(f)
+	if f == 0 || (abs >= 1e-6 && abs < 1e21) {
+		return strconv.FormatFloat(f, 'f', -1, 64)
+	}
+
+	// Go pads exponents to two digits; drop the padding.
+	s := strconv.FormatFloat(f, 'e', -1, 64)
+	mantissa, exp, _ := strings.Cut(s, "e")
+	sign, digits := exp[:1], strings.TrimLeft(exp[1:], "0")
+
+	return mantissa + "e" + sign + digits
+}
@@ -0,0 +1,98 @@
+package interpreter_test
+
+import (
+	"glox/playground"
+	"strings"
+	"testing"
+)
+
+// TestIntegerSemantics pins down how integers overflow, shift, divide and
+// compare with floats, as documented in number.go.
+func TestIntegerSemantics(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		// Arithmetic wraps around.
+		{"9223372036854775807 + 1", "-9223372036854775808"},
+		{"-9223372036854775807 - 1 - 1", "9223372036854775807"},
+		{"9223372036854775807 * 2", "-2"},
+		{"-(-9223372036854775807 - 1)", "-9223372036854775808"},
+		{"abs(-9223372036854775807 - 1)", "-9223372036854775808"},
+		{"(-9223372036854775807 - 1) ~/ -1", "-9223372036854775808"},
+		{"(-9223372036854775807 - 1) % -1", "0"},
+
+		// Shifts of 64 bits or more shift everything out.
+		{"1 << 63", "-9223372036854775808"},
+		{"1 << 64", "0"},
+		{"1 << 65", "0"},
+		{"-8 >> 1", "-4"},
+		{"-1 >> 70", "-1"},
+		{"8 >> 64", "0"},
+
+		// '~/' truncates toward zero and '%' takes the sign of the dividend.
+		{"7 ~/ 2", "3"},
+		{"-7 ~/ 2", "-3"},
+		{"-7 % 2", "-1"},
+		{"7 % -2", "1"},
+
+		// '/' always divides as floats, and floats print without a
+		// fraction when they have none.
+		{"7 / 2", "3.5"},
+		{"4 / 2", "2"},
+		{"1 / 0", "inf"},
+
+		// Mixing in a float makes the operation a float one.
+		{"1 + 0.5", "1.5"},
+		{"9223372036854775807 + 0.0", "9223372036854776000"},
+
+		// Integers are converted to the nearest float to compare them with
+		// floats, which loses precision beyond 2^53.
+		{"1 == 1.0", "true"},
+		{"9007199254740993 == 9007199254740992.0", "true"},
+		{"9007199254740993 == 9007199254740992", "false"},
+		{"9223372036854775807 == 9223372036854775808.0", "true"},
+		{"9007199254740993 > 9007199254740992.0", "false"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			result := playground.Eval("print " + tt.expr + ";")
+			if result.Status != 0 {
+				t.Fatalf("failed: %+v", result.Diagnostics)
+			}
+
+			if got := strings.TrimSuffix(result.Stdout, "\n"); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+// TestIntegerErrors pins down the integer operations that fail.
+func TestIntegerErrors(t *testing.T) {
+	tests := []struct {
+		expr string
+		want string
+	}{
+		{"1 ~/ 0", "Division by zero."},
+		{"1 % 0", "Division by zero."},
+		{"1 << -1", "Negative shift count."},
+		{"1.5 & 1", "Operands must be integers."},
+		{"(4 / 2) & 1", "Operands must be integers."},
+		{"9223372036854775808", "Integer literal out of range."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.expr, func(t *testing.T) {
+			result := playground.Eval("print " + tt.expr + ";")
+			if len(result.Diagnostics) != 1 {
+				t.Fatalf("got %+v, want one error", result)
+			}
+
+			if got := result.Diagnostics[0].Message; got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,23 @@
+package interpreter
+
+import "glox/generated"
+
+// Optimizer rewrites the statements of the main script and of every
+// imported module after they are resolved and before they run. Expressions
+// the resolver bound to a scope must be kept as they are, since they are
+// looked up by identity.
+type Optimizer interface {
+	Optimize(stmts []generated.Stmt) []generated.Stmt
+}
+
+// WithOptimizer installs an optimizer.
+func WithOptimizer(o Optimizer) Option {
+	return func(i *interpreter) {
+		i.optimizer = o
+	}
+}
+
+// Truthy reports whether a value counts as true in a condition.
+func Truthy(v interface{}) bool {
+	return (&interpreter{}).isTruthy(v)
+}
@@ -0,0 +1,57 @@
+package interpreter
+
+import (
+	"io"
+	"math/rand"
+)
+
+// Option configures an interpreter created by NewInterpreter.
+type Option func(*interpreter)
+
+// WithSeed seeds the interpreter's random number source so that runs are
+// reproducible. Without it the source is seeded from the current time.
+func WithSeed(seed int64) Option {
+	return func(i *interpreter) {
+		i.rand = rand.New(rand.NewSource(seed))
+	}
+}
+
+// WithArgs exposes the given command line arguments to scripts as the
+// global list args.
+func WithArgs(args []string) Option {
+	return func(i *interpreter) {
+		i.args = args
+	}
+}
+
+// WithStdin sets the reader used by readLine. It defaults to os.Stdin.
+func WithStdin(r io.Reader) Option {
+	return func(i *interpreter) {
+		i.stdin = newStdin(r)
+	}
+}
+
+// WithStdout sets the writer that print statements and uncaught errors
+// are written to. It defaults to os.Stdout.
+func WithStdout(w io.Writer) Option {
+	return func(i *interpreter) {
+		i.stdout = w
+	}
+}
+
+// WithScriptPath sets the path of the main script, against which relative
+// imports are resolved. Without it imports are relative to the working
+// directory.
+func WithScriptPath(path string) Option {
+	return func(i *interpreter) {
+		i.path = path
+	}
+}
+
+// WithCache makes imported modules go through the AST cache, which keeps
+// the resolved tree of each module next to its source.
+func WithCache() Option {
+	return func(i *interpreter) {
+		i.cache = true
+	}
+}
@@ -0,0 +1,66 @@
+package interpreter
+
+import "glox/generated"
+
+// Function identifies a function called while profiling.
+type Function struct {
+	// Name is the name the function was declared with.
+	Name string
+
+	// Path is the canonical path of the module declaring the function, or
+	// "" for natives and scripts not run from a file.
+	Path string
+
+	// Line is the line of the declaration, or 0 for natives.
+	Line int
+}
+
+// Profiler is told about every call, including calls to natives, and about
+// every statement before it runs except blocks. Each Enter is matched by an
+// Exit once the call returns, whether or not it failed.
+type Profiler interface {
+	Enter(f Function)
+	Exit()
+	Line(path string, line int)
+}
+
+// WithProfiler installs a profiler.
+func WithProfiler(p Profiler) Option {
+	return func(i *interpreter) {
+		i.profiler = p
+	}
+}
+
+func (i *interpreter) profileLine(stmt generated.Stmt) {
+	if _, ok := stmt.(*generated.BlockStmt); ok {
+		return
+	}
+
+	i.profiler.Line(i.module.path, generated.StmtLine(stmt))
+}
+
+// profileCall reports a call to callee and returns a func reporting its
+// return.
+func (i *interpreter) profileCall(callee LoxCallable) func() {
+	if i.profiler == nil {
+		return func() {}
+	}
+
+	f := Function{Name: callee.String()}
+	switch c := callee.(type) {
+	case *fun:
+		f = Function{
+			Name: c.Declaration.Name.GetLexeme(),
+			Path: c.Module.path,
+			Line: c.Declaration.Name.GetLine(),
+		}
+	case *native:
+		f.Name = c.name
+	case *clock:
+		f.Name = "clock"
+	}
+
+	i.profiler.Enter(f)
+
+	return i.profiler.Exit
+}
@@ -0,0 +1,82 @@
+package interpreter
+
+import (
+	"glox/environment"
+	"math"
+)
+
+// defineRandom registers the random number natives in the global
+// environment. They all draw from the interpreter's own source so that a
+// seeded interpreter is reproducible regardless of other interpreters.
+func defineRandom(g *environment.Environment) {
+	for _, n := range []native{
+		{name: "random", minArity: 0, maxArity: 0, fn: randRandom},
+		{name: "randomInt", minArity: 2, maxArity: 2, fn: randRandomInt},
+		{name: "shuffle", minArity: 1, maxArity: 1, fn: randShuffle},
+		{name: "seed", minArity: 1, maxArity: 1, fn: randSeed},
+	} {
+		g.Define(n.name, &n)
+	}
+}
+
+func randRandom(in Interpreter, _ []interface{}) (interface{}, error) {
+	return in.(*interpreter).rand.Float64(), nil
+}
+
+// randRandomInt returns an integer in the inclusive range [lo, hi].
+func randRandomInt(in Interpreter, args []interface{}) (interface{}, error) {
+	lo, err := intArg("randomInt", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	hi, err := intArg("randomInt", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	if lo > hi {
+		return nil, argErr("randomInt", "lower bound must not be greater than upper bound.")
+	}
+
+	r := in.(*interpreter).rand
+
+	// The width of the range less one always fits in a uint64. Ranges
+	// wider than an int are drawn by rejection, which takes less than two
+	// draws on average.
+	span := uint64(hi) - uint64(lo)
+	if span < math.MaxInt64 {
+		return int64(lo + r.Intn(int(span+1))), nil
+	}
+
+	for {
+		if n := r.Uint64(); n <= span {
+			return int64(lo) + int64(n), nil
+		}
+	}
+}
+
+// randShuffle shuffles a list in place.
+func randShuffle(in Interpreter, args []interface{}) (interface{}, error) {
+	l, err := listArg("shuffle", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	in.(*interpreter).rand.Shuffle(len(l.Elements), func(a, b int) {
+		l.Elements[a], l.Elements[b] = l.Elements[b], l.Elements[a]
+	})
+
+	return nil, nil
+}
+
+func randSeed(in Interpreter, args []interface{}) (interface{}, error) {
+	n, err := intArg("seed", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	in.(*interpreter).rand.Seed(int64(n))
+
+	return nil, nil
+}
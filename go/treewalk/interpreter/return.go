@@ -2,6 +2,11 @@ package interpreter
 
 type Return struct {
 	Value interface{}
+
+	// tail is set instead of Value when a function returns a call in tail
+	// position to another Lox function. The call is left for the function
+	// returning to make, in place of itself.
+	tail *pendingCall
 }
 
 func (r Return) Error() string {
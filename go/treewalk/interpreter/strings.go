@@ -0,0 +1,333 @@
+package interpreter
+
+import (
+	"fmt"
+	"glox/environment"
+	"math"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// defineStrings registers the string standard library in the global
+// environment. All indices and lengths count runes, not bytes, so that
+// scripts behave the same on ASCII and non-ASCII text.
+func defineStrings(g *environment.Environment) {
+	for _, n := range []native{
+		{name: "len", minArity: 1, maxArity: 1, fn: strLen},
+		{name: "substr", minArity: 3, maxArity: 3, fn: strSubstr},
+		{name: "indexOf", minArity: 2, maxArity: 2, fn: strIndexOf},
+		{name: "contains", minArity: 2, maxArity: 2, fn: strContains},
+		{name: "split", minArity: 2, maxArity: 2, fn: strSplit},
+		{name: "join", minArity: 2, maxArity: 2, fn: strJoin},
+		{name: "upper", minArity: 1, maxArity: 1, fn: strUpper},
+		{name: "lower", minArity: 1, maxArity: 1, fn: strLower},
+		{name: "trim", minArity: 1, maxArity: 1, fn: strTrim},
+		{name: "replace", minArity: 3, maxArity: 3, fn: strReplace},
+		{name: "startsWith", minArity: 2, maxArity: 2, fn: strStartsWith},
+		{name: "endsWith", minArity: 2, maxArity: 2, fn: strEndsWith},
+		{name: "charAt", minArity: 2, maxArity: 2, fn: strCharAt},
+		{name: "ord", minArity: 1, maxArity: 1, fn: strOrd},
+		{name: "chr", minArity: 1, maxArity: 1, fn: strChr},
+		{name: "str", minArity: 1, maxArity: 1, fn: strStr},
+		{name: "num", minArity: 1, maxArity: 1, fn: strNum},
+	} {
+		g.Define(n.name, &n)
+	}
+}
+
+func strLen(_ Interpreter, args []interface{}) (interface{}, error) {
+	switch v := args[0].(type) {
+	case string:
+		return int64(utf8.RuneCountInString(v)), nil
+	case *list:
+		return int64(len(v.Elements)), nil
+	case *dict:
+		return int64(len(v.Keys())), nil
+	}
+
+	return nil, argErr("len", "argument must be a string, a list or a map.")
+}
+
+// strSubstr returns the runes of s in [start, end). Negative indices count
+// back from the end of the string.
+func strSubstr(_ Interpreter, args []interface{}) (interface{}, error) {
+	s, err := stringArg("substr", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	runes := []rune(s)
+	start, end, err := sliceBounds("substr", args, 1, len(runes))
+	if err != nil {
+		return nil, err
+	}
+
+	return string(runes[start:end]), nil
+}
+
+func sliceBounds(name string, args []interface{}, idx int, length int) (int, int, error) {
+	start, err := intArg(name, args, idx)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	end, err := intArg(name, args, idx+1)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	if start < 0 {
+		start += length
+	}
+	if end < 0 {
+		end += length
+	}
+
+	if start < 0 || end > length || start > end {
+		return 0, 0, argErr(name, fmt.Sprintf("range [%d, %d) out of bounds for length %d.", start, end, length))
+	}
+
+	return start, end, nil
+}
+
+func strIndexOf(_ Interpreter, args []interface{}) (interface{}, error) {
+	if l, ok := args[0].(*list); ok {
+		for idx, e := range l.Elements {
+			if isEqual(e, args[1]) {
+				return int64(idx), nil
+			}
+		}
+
+		return int64(-1), nil
+	}
+
+	s, err := stringArg("indexOf", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sub, err := stringArg("indexOf", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	b := strings.Index(s, sub)
+	if b < 0 {
+		return int64(-1), nil
+	}
+
+	return int64(utf8.RuneCountInString(s[:b])), nil
+}
+
+func strContains(in Interpreter, args []interface{}) (interface{}, error) {
+	idx, err := strIndexOf(in, args)
+	if err != nil {
+		return nil, argErr("contains", "arguments must be a string and a substring, or a list and a value.")
+	}
+
+	return idx.(int64) >= 0, nil
+}
+
+// strSplit splits s around every occurrence of sep. An empty separator
+// splits s into its individual characters.
+func strSplit(_ Interpreter, args []interface{}) (interface{}, error) {
+	s, err := stringArg("split", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sep, err := stringArg("split", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	parts := strings.Split(s, sep)
+	elements := make([]interface{}, 0, len(parts))
+	for _, p := range parts {
+		elements = append(elements, p)
+	}
+
+	return newList(elements), nil
+}
+
+func strJoin(in Interpreter, args []interface{}) (interface{}, error) {
+	l, err := listArg("join", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	sep, err := stringArg("join", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	stringify := in.(*interpreter).stringify
+
+	parts := make([]string, 0, len(l.Elements))
+	for _, e := range l.Elements {
+		parts = append(parts, stringify(e))
+	}
+
+	return strings.Join(parts, sep), nil
+}
+
+func strUpper(_ Interpreter, args []interface{}) (interface{}, error) {
+	s, err := stringArg("upper", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.ToUpper(s), nil
+}
+
+func strLower(_ Interpreter, args []interface{}) (interface{}, error) {
+	s, err := stringArg("lower", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.ToLower(s), nil
+}
+
+func strTrim(_ Interpreter, args []interface{}) (interface{}, error) {
+	s, err := stringArg("trim", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.TrimSpace(s), nil
+}
+
+func strReplace(_ Interpreter, args []interface{}) (interface{}, error) {
+	s, err := stringArg("replace", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	old, err := stringArg("replace", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	repl, err := stringArg("replace", args, 2)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.ReplaceAll(s, old, repl), nil
+}
+
+func strStartsWith(_ Interpreter, args []interface{}) (interface{}, error) {
+	s, err := stringArg("startsWith", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	prefix, err := stringArg("startsWith", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.HasPrefix(s, prefix), nil
+}
+
+func strEndsWith(_ Interpreter, args []interface{}) (interface{}, error) {
+	s, err := stringArg("endsWith", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	suffix, err := stringArg("endsWith", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	return strings.HasSuffix(s, suffix), nil
+}
+
+func strCharAt(_ Interpreter, args []interface{}) (interface{}, error) {
+	s, err := stringArg("charAt", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	idx, err := intArg("charAt", args, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	runes := []rune(s)
+	if idx < 0 {
+		idx += len(runes)
+	}
+
+	if idx < 0 || idx >= len(runes) {
+		return nil, argErr("charAt", fmt.Sprintf("index %d out of bounds for length %d.", idx, len(runes)))
+	}
+
+	return string(runes[idx]), nil
+}
+
+func strOrd(_ Interpreter, args []interface{}) (interface{}, error) {
+	s, err := stringArg("ord", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if utf8.RuneCountInString(s) != 1 {
+		return nil, argErr("ord", "argument must be a single character.")
+	}
+
+	r, _ := utf8.DecodeRuneInString(s)
+
+	return int64(r), nil
+}
+
+func strChr(_ Interpreter, args []interface{}) (interface{}, error) {
+	n, err := intArg("chr", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if n < 0 || n > utf8.MaxRune || !utf8.ValidRune(rune(n)) {
+		return nil, argErr("chr", fmt.Sprintf("%d is not a valid code point.", n))
+	}
+
+	return string(rune(n)), nil
+}
+
+// strStr converts any value to the string print would show for it.
+func strStr(in Interpreter, args []interface{}) (interface{}, error) {
+	return in.(*interpreter).stringify(args[0]), nil
+}
+
+// strNum parses a number, yielding an integer when s has no fractional
+// part or exponent, and nil when s is not a number.
+func strNum(_ Interpreter, args []interface{}) (interface{}, error) {
+	s, err := stringArg("num", args, 0)
+	if err != nil {
+		return nil, err
+	}
+
+	s = strings.TrimSpace(s)
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		return n, nil
+	}
+
+	switch s {
+	case "nan":
+		return math.NaN(), nil
+	case "inf":
+		return math.Inf(1), nil
+	case "-inf":
+		return math.Inf(-1), nil
+	}
+
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil || strings.ContainsAny(strings.ToLower(s), "inx") {
+		return nil, nil
+	}
+
+	return f, nil
+}
@@ -0,0 +1,64 @@
+package interpreter
+
+import (
+	"fmt"
+	"glox/generated"
+	"glox/lerr"
+	"glox/token"
+)
+
+// pendingCall is a call whose callee and arguments have been evaluated.
+type pendingCall struct {
+	callee LoxCallable
+	paren  token.Token
+	args   []interface{}
+	names  []token.Token
+	named  []interface{}
+}
+
+// check reports calls that cannot be made: named arguments to anything
+// but a Lox function, and positional arguments the callee does not
+// accept. Lox functions check named arguments as they bind them.
+func (c *pendingCall) check() error {
+	if c.names == nil {
+		return CheckArity(c.callee, c.paren, len(c.args))
+	}
+
+	if _, ok := c.callee.(*fun); !ok {
+		return lerr.NewRuntimeErr(c.paren, fmt.Sprintf("%s does not take named arguments.", c.callee.String()))
+	}
+
+	return nil
+}
+
+// TailCall implements resolver.TailCaller.
+func (i *interpreter) TailCall(call *generated.Call) {
+	i.tails[call] = true
+}
+
+// returnCall returns the value of a call in tail position. Calls to Lox
+// functions are not made here but handed back to fun.call, which makes
+// them once the function returning has finished. Deep tail recursion thus
+// runs in constant Go stack space.
+func (i *interpreter) returnCall(call *generated.Call) (interface{}, error) {
+	pending, err := i.evaluateCall(call)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := pending.callee.(*fun); !ok {
+		value, err := i.invoke(pending)
+		if err != nil {
+			return nil, err
+		}
+
+		return value, &Return{Value: value}
+	}
+
+	err = pending.check()
+	if err != nil {
+		return nil, err
+	}
+
+	return nil, &Return{tail: pending}
+}
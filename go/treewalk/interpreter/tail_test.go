@@ -0,0 +1,77 @@
+package interpreter_test
+
+import (
+	"glox/playground"
+	"runtime/debug"
+	"testing"
+)
+
+// TestTailCallStack checks that tail calls run in constant Go stack space.
+// With a 4MB stack, 20000 calls that are not in tail position already
+// overflow it.
+func TestTailCallStack(t *testing.T) {
+	defer debug.SetMaxStack(debug.SetMaxStack(4 << 20))
+
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"self", `fun loop(n) { if (n == 0) return "done"; return loop(n - 1); } print loop(200000);`, "done\n"},
+		{"mutual", `fun isEven(n) { if (n == 0) return true; return isOdd(n - 1); }
+fun isOdd(n) { if (n == 0) return false; return isEven(n - 1); }
+print isEven(200000);`, "true\n"},
+		{"named", `fun sum(n, acc = 0) { if (n == 0) return acc; return sum(n - 1, acc: acc + n); }
+print sum(200000);`, "20000100000\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := playground.Eval(tt.source)
+			if result.Status != 0 {
+				t.Fatalf("failed: %+v", result.Diagnostics)
+			}
+
+			if result.Stdout != tt.want {
+				t.Errorf("got %q, want %q", result.Stdout, tt.want)
+			}
+		})
+	}
+}
+
+// TestTailCallInTry checks that calls returned inside try are made before
+// the try statement is left, so that its catch and finally clauses still
+// see them.
+func TestTailCallInTry(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   string
+	}{
+		{"catch", `fun boom() { throw "boom"; }
+fun f() { try { return boom(); } catch (e) { return "caught " + e; } }
+print f();`, "caught boom\n"},
+		{"finally", `fun g() { print "g"; return 1; }
+fun f() { try { return g(); } finally { print "finally"; } }
+print f();`, "g\nfinally\n1\n"},
+		{"nested", `fun boom() { throw "boom"; }
+fun f() { try { { if (true) return boom(); } } catch (e) { return "caught"; } }
+print f();`, "caught\n"},
+		{"trace", `fun boom() { throw "boom"; }
+fun f() { try { return boom(); } finally { print "finally"; } }
+try { f(); } catch (e) { print e; }`, "finally\nboom\n"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result := playground.Eval(tt.source)
+			if result.Status != 0 {
+				t.Fatalf("failed: %+v", result.Diagnostics)
+			}
+
+			if result.Stdout != tt.want {
+				t.Errorf("got %q, want %q", result.Stdout, tt.want)
+			}
+		})
+	}
+}
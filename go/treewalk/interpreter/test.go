@@ -0,0 +1,149 @@
+package interpreter
+
+import (
+	"fmt"
+	"glox/environment"
+	"glox/generated"
+	"glox/lerr"
+)
+
+// Tests is told about the tests the main script declares as it runs. run
+// executes the body of a test in the environment it was declared in, and
+// is meant to be called once the script has finished.
+type Tests interface {
+	Test(name string, line int, run func() error)
+}
+
+// WithTests installs a test runner. Without one, test declarations are
+// skipped, so that a script holding tests can still be run or imported.
+func WithTests(t Tests) Option {
+	return func(i *interpreter) {
+		i.tests = t
+	}
+}
+
+func (i *interpreter) VisitTestStmt(teststmt *generated.TestStmt) (interface{}, error) {
+	// Tests in imported modules belong to those modules.
+	if i.tests == nil || len(i.importing) > 1 {
+		return nil, nil
+	}
+
+	env, m := i.Env, i.module
+	i.tests.Test(teststmt.Name.GetLiteral().(string), teststmt.Keyword.GetLine(), func() error {
+		defer i.enterModule(m)()
+
+		_, err := i.executeBlock(teststmt.Body, environment.NewEnvironment(env))
+		return err
+	})
+
+	return nil, nil
+}
+
+// defineAssert registers the assertion natives in the global environment.
+// A failed assertion is a runtime error, which fails the test it is in.
+func defineAssert(g *environment.Environment) {
+	for _, n := range []native{
+		{name: "assert", minArity: 1, maxArity: 2, fn: assert},
+		{name: "assertEqual", minArity: 2, maxArity: 3, fn: assertEqual},
+		{name: "assertThrows", minArity: 1, maxArity: 2, fn: assertThrows},
+	} {
+		g.Define(n.name, &n)
+	}
+}
+
+// assertErr returns the error for a failed assertion, prefixed by the
+// message the script passed as the argument at idx, if any.
+func assertErr(args []interface{}, idx int, msg string) error {
+	if idx < len(args) {
+		msg = fmt.Sprintf("%s: %s", Stringify(args[idx]), msg)
+	}
+
+	return lerr.NewRuntimeErr(nil, msg)
+}
+
+func assert(in Interpreter, args []interface{}) (interface{}, error) {
+	if !in.(*interpreter).isTruthy(args[0]) {
+		return nil, assertErr(args, 1, "Assertion failed.")
+	}
+
+	return nil, nil
+}
+
+func assertEqual(_ Interpreter, args []interface{}) (interface{}, error) {
+	actual, expected := args[0], args[1]
+	if !deepEqual(actual, expected) {
+		return nil, assertErr(args, 2, fmt.Sprintf("Expected %s but got %s.", quote(expected), quote(actual)))
+	}
+
+	return nil, nil
+}
+
+// assertThrows calls a function taking no arguments and returns what it
+// threw, as a catch clause would bind it.
+func assertThrows(in Interpreter, args []interface{}) (interface{}, error) {
+	fn, ok := args[0].(LoxCallable)
+	if !ok {
+		return nil, argErr("assertThrows", "argument 1 must be a function.")
+	}
+
+	if min, _ := fn.Arity(); min > 0 {
+		return nil, argErr("assertThrows", "argument 1 must take no arguments.")
+	}
+
+	_, err := fn.Call(in, nil)
+	if err == nil {
+		return nil, assertErr(args, 1, "Expected an exception but none was thrown.")
+	}
+
+	value, ok := Caught(err)
+	if !ok {
+		return nil, err
+	}
+
+	return value, nil
+}
+
+// deepEqual is like ==, but compares lists and maps by their contents.
+func deepEqual(a, b interface{}) bool {
+	switch x := a.(type) {
+	case *list:
+		y, ok := b.(*list)
+		if !ok || len(x.Elements) != len(y.Elements) {
+			return false
+		}
+
+		for idx := range x.Elements {
+			if !deepEqual(x.Elements[idx], y.Elements[idx]) {
+				return false
+			}
+		}
+
+		return true
+	case *dict:
+		y, ok := b.(*dict)
+		if !ok || len(x.Entries) != len(y.Entries) {
+			return false
+		}
+
+		for key, value := range x.Entries {
+			other, ok := y.Entries[key]
+			if !ok || !deepEqual(value, other) {
+				return false
+			}
+		}
+
+		return true
+	}
+
+	return isEqual(a, b)
+}
+
+// quote shows a value in a failure message, quoting strings so that they
+// can be told apart from other values.
+func quote(v interface{}) string {
+	if s, ok := v.(string); ok {
+		return fmt.Sprintf("%q", s)
+	}
+
+	return Stringify(v)
+}
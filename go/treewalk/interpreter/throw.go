@@ -0,0 +1,78 @@
+package interpreter
+
+import (
+	"fmt"
+	"glox/lerr"
+	"glox/token"
+)
+
+// Throw carries a value raised by a throw statement up to the nearest
+// enclosing try statement, the same way Return carries a function result.
+type Throw struct {
+	Value   interface{}
+	Keyword token.Token
+	trace   []string
+}
+
+func (t Throw) Error() string {
+	return fmt.Sprintf("[line %d] Uncaught exception: %v", t.Keyword.GetLine(), t.Value)
+}
+
+// Diagnostic describes the exception, noting the calls it propagated out
+// of.
+func (t *Throw) Diagnostic() lerr.Diagnostic {
+	span := lerr.SpanOf(t.Keyword)
+	d := lerr.Diagnostic{
+		Severity: lerr.SeverityError,
+		Code:     lerr.CodeUncaught,
+		Line:     span.Start.Line,
+		Column:   span.Start.Column,
+		Span:     &span,
+		Message:  fmt.Sprintf("Uncaught exception: %v", t.Value),
+	}
+
+	for _, frame := range t.trace {
+		d.Notes = append(d.Notes, "in "+frame)
+	}
+
+	return d
+}
+
+// Caught returns the value a catch clause binds for err, and whether
+// err can be caught at all. Runtime errors are turned into a map holding
+// their message, line and trace.
+func Caught(err error) (interface{}, bool) {
+	switch e := err.(type) {
+	case *Throw:
+		return e.Value, true
+	case *lerr.RuntimeErr:
+		trace := make([]interface{}, 0, len(e.Trace()))
+		for _, frame := range e.Trace() {
+			trace = append(trace, frame)
+		}
+
+		obj := newDict()
+		obj.Set("type", "RuntimeError")
+		obj.Set("message", e.Message())
+		obj.Set("line", int64(e.Line()))
+		obj.Set("trace", newList(trace))
+
+		return obj, true
+	}
+
+	return nil, false
+}
+
+// AddFrame records that err propagated out of a call to callee made on the
+// line of paren.
+func AddFrame(err error, callee LoxCallable, paren token.Token) {
+	frame := fmt.Sprintf("%s [line %d]", callee.String(), paren.GetLine())
+
+	switch e := err.(type) {
+	case *Throw:
+		e.trace = append(e.trace, frame)
+	case *lerr.RuntimeErr:
+		e.Locate(paren)
+		e.AddFrame(frame)
+	}
+}
@@ -0,0 +1,112 @@
+package lerr
+
+import (
+	"errors"
+	"glox/token"
+	"strings"
+	"unicode/utf8"
+)
+
+// Severity says how serious a diagnostic is.
+type Severity string
+
+const (
+	SeverityError   Severity = "error"
+	SeverityWarning Severity = "warning"
+)
+
+// Codes identify the kind of error a diagnostic describes.
+const (
+	CodeSyntax   = "syntax-error"
+	CodeType     = "type-error"
+	CodeRuntime  = "runtime-error"
+	CodeUncaught = "uncaught-exception"
+	CodeOther    = "error"
+)
+
+// Position is a place in a script. Lines and columns count from 1, and
+// columns are counted in runes.
+type Position struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+// Span is the source a diagnostic covers, from Start up to but not
+// including End.
+type Span struct {
+	Start Position `json:"start"`
+	End   Position `json:"end"`
+}
+
+// SpanOf returns the span of the source t was scanned from. The EOF token
+// covers no source, so its span is empty.
+func SpanOf(t token.Token) Span {
+	start := Position{Line: t.GetLine(), Column: t.GetColumn() + 1}
+	end := start
+
+	lexeme := t.GetLexeme()
+	if idx := strings.LastIndexByte(lexeme, '\n'); idx >= 0 {
+		end.Line += strings.Count(lexeme, "\n")
+		end.Column = utf8.RuneCountInString(lexeme[idx+1:]) + 1
+	} else {
+		end.Column += utf8.RuneCountInString(lexeme)
+	}
+
+	return Span{Start: start, End: end}
+}
+
+// Diagnostic describes an error for editors and other tools. Line and
+// Column are those of the start of Span; Span is nil and Column 0 when
+// only the line is known, and Line is 0 when that is not known either.
+// File is left empty by errors, which do not know the script they were
+// found in, for their reporter to fill in.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Code     string   `json:"code"`
+	File     string   `json:"file,omitempty"`
+	Line     int      `json:"line,omitempty"`
+	Column   int      `json:"column,omitempty"`
+	Span     *Span    `json:"span,omitempty"`
+	Message  string   `json:"message"`
+	Notes    []string `json:"notes,omitempty"`
+}
+
+// Error is implemented by the errors of every stage of glox, from the
+// scanner to the interpreter.
+type Error interface {
+	error
+	Diagnostic() Diagnostic
+}
+
+// Diagnose returns the diagnostic of err. Errors that do not implement
+// Error, like those of the file system, are described by their message.
+func Diagnose(err error) Diagnostic {
+	var e Error
+	if errors.As(err, &e) {
+		return e.Diagnostic()
+	}
+
+	return Diagnostic{
+		Severity: SeverityError,
+		Code:     CodeOther,
+		Message:  strings.TrimSpace(err.Error()),
+	}
+}
+
+// at returns a diagnostic covering span, or only line if span is nil.
+func at(code string, line int, span *Span, msg string) Diagnostic {
+	d := Diagnostic{
+		Severity: SeverityError,
+		Code:     code,
+		Line:     line,
+		Span:     span,
+		Message:  msg,
+	}
+
+	if span != nil {
+		d.Line = span.Start.Line
+		d.Column = span.Start.Column
+	}
+
+	return d
+}
@@ -1,12 +0,0 @@
-package lerr
-
-type ParseErr struct {
-}
-
-func (e ParseErr) Error() string {
-	return "Parse error"
-}
-
-func NewParseErr() error {
-	return &ParseErr{}
-}
@@ -5,6 +5,7 @@ import "glox/token"
 type RuntimeErr struct {
 	token token.Token
 	msg   string
+	trace []string
 }
 
 func (e RuntimeErr) Error() string {
@@ -17,3 +18,52 @@ func NewRuntimeErr(token token.Token, msg string) error {
 		msg:   msg,
 	}
 }
+
+// Message returns the error message without location information.
+func (e *RuntimeErr) Message() string {
+	return e.msg
+}
+
+// Line returns the source line the error was raised on, or 0 when it is
+// not known.
+func (e *RuntimeErr) Line() int {
+	if e.token == nil {
+		return 0
+	}
+
+	return e.token.GetLine()
+}
+
+// Locate attributes the error to t if it was raised without a token, as
+// happens for errors returned by native functions.
+func (e *RuntimeErr) Locate(t token.Token) {
+	if e.token == nil {
+		e.token = t
+	}
+}
+
+// Diagnostic describes the error, noting the calls it propagated out of.
+func (e *RuntimeErr) Diagnostic() Diagnostic {
+	var span *Span
+	if e.token != nil {
+		s := SpanOf(e.token)
+		span = &s
+	}
+
+	d := at(CodeRuntime, 0, span, e.msg)
+	for _, frame := range e.trace {
+		d.Notes = append(d.Notes, "in "+frame)
+	}
+
+	return d
+}
+
+// AddFrame records a call the error propagated out of, innermost first.
+func (e *RuntimeErr) AddFrame(frame string) {
+	e.trace = append(e.trace, frame)
+}
+
+// Trace returns the frames recorded with AddFrame.
+func (e *RuntimeErr) Trace() []string {
+	return e.trace
+}
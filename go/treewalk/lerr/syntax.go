@@ -4,6 +4,7 @@ import "fmt"
 
 type syntaxErr struct {
 	line  int
+	span  *Span
 	msg   string
 	where string
 }
@@ -15,3 +16,22 @@ func (e syntaxErr) Error() string {
 func NewSyntaxErr(line int, where string, msg string) error {
 	return &syntaxErr{line: line, where: where, msg: msg}
 }
+
+// NewSyntaxErrAt returns a syntax error found in the source span covers.
+func NewSyntaxErrAt(span Span, where string, msg string) error {
+	return &syntaxErr{line: span.Start.Line, span: &span, where: where, msg: msg}
+}
+
+// Line returns the source line the error was found on.
+func (e *syntaxErr) Line() int {
+	return e.line
+}
+
+// Message returns the error message without location information.
+func (e *syntaxErr) Message() string {
+	return e.msg
+}
+
+func (e *syntaxErr) Diagnostic() Diagnostic {
+	return at(CodeSyntax, e.line, e.span, e.msg)
+}
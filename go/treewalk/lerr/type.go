@@ -0,0 +1,26 @@
+package lerr
+
+import "fmt"
+
+type typeErr struct {
+	line int
+	span *Span
+	msg  string
+}
+
+func (e typeErr) Error() string {
+	return fmt.Sprintf("[line %d] Type error: %s", e.line, e.msg)
+}
+
+func NewTypeErr(line int, msg string) error {
+	return &typeErr{line: line, msg: msg}
+}
+
+// NewTypeErrAt returns a type error found in the source span covers.
+func NewTypeErrAt(span Span, msg string) error {
+	return &typeErr{line: span.Start.Line, span: &span, msg: msg}
+}
+
+func (e *typeErr) Diagnostic() Diagnostic {
+	return at(CodeType, e.line, e.span, e.msg)
+}
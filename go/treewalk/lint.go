@@ -0,0 +1,63 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"glox/lint"
+	"os"
+)
+
+// fileWarning is a lint warning together with the script it was found in.
+type fileWarning struct {
+	File string `json:"file"`
+	lint.Warning
+}
+
+// lintCmd implements `glox lint [--json] script...`. Warnings are printed
+// one per line as file:line: rule: message, or as a JSON array with
+// --json. It returns the exit status: 1 if there were warnings.
+func lintCmd(args []string) int {
+	fs := flag.NewFlagSet("lint", flag.ExitOnError)
+	asJSON := fs.Bool("json", false, "print warnings as a JSON array")
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fmt.Fprintln(os.Stderr, "Usage: glox lint [--json] script...")
+		return 64
+	}
+
+	warnings := []fileWarning{}
+	for _, file := range fs.Args() {
+		prog, err := os.ReadFile(file)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 66
+		}
+
+		stmts, err := parse(string(prog))
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 65
+		}
+
+		for _, w := range lint.NewLinter().Lint(stmts) {
+			warnings = append(warnings, fileWarning{File: file, Warning: w})
+		}
+	}
+
+	if *asJSON {
+		out, _ := json.MarshalIndent(warnings, "", "  ")
+		fmt.Println(string(out))
+	} else {
+		for _, w := range warnings {
+			fmt.Printf("%s:%d: %s: %s\n", w.File, w.Line, w.Rule, w.Message)
+		}
+	}
+
+	if len(warnings) != 0 {
+		return 1
+	}
+
+	return 0
+}
@@ -0,0 +1,487 @@
+package lint
+
+import (
+	"fmt"
+	"glox/generated"
+	"glox/token"
+	"sort"
+	"strings"
+)
+
+// Rules reported by the linter.
+const (
+	RuleUnusedVariable     = "unused-variable"
+	RuleUnusedParameter    = "unused-parameter"
+	RuleUnreachableCode    = "unreachable-code"
+	RuleShadowing          = "shadowing"
+	RuleSelfAssignment     = "self-assignment"
+	RuleConstantComparison = "constant-comparison"
+)
+
+// Warning is a problem found by the linter. Unlike errors, warnings do not
+// stop a script from running.
+type Warning struct {
+	Line    int    `json:"line"`
+	Rule    string `json:"rule"`
+	Message string `json:"message"`
+}
+
+func (w Warning) String() string {
+	return fmt.Sprintf("[line %d] Warning (%s): %s", w.Line, w.Rule, w.Message)
+}
+
+type Linter interface {
+	generated.VisitorStmt
+	generated.VisitorExpr
+	Lint([]generated.Stmt) []Warning
+}
+
+// local is a variable declared in a block or function scope. Globals are
+// never reported as unused, since another module may import them.
+type local struct {
+	name token.Token
+	rule string
+	used bool
+}
+
+type linter struct {
+	scopes   []map[string]*local
+	globals  map[string]bool
+	warnings []Warning
+}
+
+func NewLinter() Linter {
+	return &linter{
+		scopes:  []map[string]*local{},
+		globals: map[string]bool{},
+	}
+}
+
+// Lint returns the warnings for stmts ordered by line.
+func (l *linter) Lint(stmts []generated.Stmt) []Warning {
+	l.lintStmts(stmts)
+
+	sort.SliceStable(l.warnings, func(i, j int) bool {
+		return l.warnings[i].Line < l.warnings[j].Line
+	})
+
+	return l.warnings
+}
+
+func (l *linter) warn(line int, rule string, format string, args ...interface{}) {
+	l.warnings = append(l.warnings, Warning{
+		Line:    line,
+		Rule:    rule,
+		Message: fmt.Sprintf(format, args...),
+	})
+}
+
+// lintStmts lints a statement list and reports the code following the first
+// statement that never completes normally, at the first statement that
+// cannot be reached.
+func (l *linter) lintStmts(stmts []generated.Stmt) {
+	for idx, stmt := range stmts {
+		stmt.Accept(l)
+
+		if t := terminator(stmt); t != nil && idx < len(stmts)-1 {
+			line := generated.StmtLine(stmts[idx+1])
+			if line == 0 {
+				line = t.GetLine()
+			}
+
+			l.warn(line, RuleUnreachableCode, "Code after %s is unreachable.", t.GetLexeme())
+			for _, rest := range stmts[idx+1:] {
+				rest.Accept(l)
+			}
+			return
+		}
+	}
+}
+
+// terminator returns the return or throw keyword that keeps control from
+// reaching the statement after stmt, or nil if it may complete normally.
+func terminator(stmt generated.Stmt) token.Token {
+	switch s := stmt.(type) {
+	case *generated.ReturnStmt:
+		return s.Keyword
+	case *generated.ThrowStmt:
+		return s.Keyword
+	case *generated.BlockStmt:
+		for _, inner := range s.Statements {
+			if t := terminator(inner); t != nil {
+				return t
+			}
+		}
+	case *generated.IfStmt:
+		if s.ElseBranch == nil {
+			return nil
+		}
+		if t := terminator(s.IfBranch); t != nil && terminator(s.ElseBranch) != nil {
+			return t
+		}
+	}
+
+	return nil
+}
+
+func (l *linter) lintBlock(stmts []generated.Stmt) {
+	l.beginScope()
+	l.lintStmts(stmts)
+	l.endScope()
+}
+
+func (l *linter) lint(expr generated.Expr) {
+	expr.Accept(l)
+}
+
+func (l *linter) beginScope() {
+	l.scopes = append(l.scopes, map[string]*local{})
+}
+
+// endScope reports the locals of the innermost scope that were never read.
+// Names starting with an underscore are meant to be unused.
+func (l *linter) endScope() {
+	scope := l.scopes[len(l.scopes)-1]
+	l.scopes = l.scopes[:len(l.scopes)-1]
+
+	unused := []*local{}
+	for _, v := range scope {
+		if !v.used && v.rule != "" && !strings.HasPrefix(v.name.GetLexeme(), "_") {
+			unused = append(unused, v)
+		}
+	}
+
+	// Report in source order rather than map order.
+	sort.Slice(unused, func(i, j int) bool {
+		return unused[i].name.GetLine() < unused[j].name.GetLine()
+	})
+
+	for _, v := range unused {
+		kind := "Local variable"
+		if v.rule == RuleUnusedParameter {
+			kind = "Parameter"
+		}
+
+		l.warn(v.name.GetLine(), v.rule, "%s '%s' is never used.", kind, v.name.GetLexeme())
+	}
+}
+
+// declare adds name to the innermost scope. rule is the warning to report
+// if it is never read, or "" for bindings that need not be used, such as
+// the name of a caught exception.
+func (l *linter) declare(name token.Token, rule string) {
+	if len(l.scopes) == 0 {
+		l.globals[name.GetLexeme()] = true
+		return
+	}
+
+	if l.shadows(name.GetLexeme()) {
+		l.warn(name.GetLine(), RuleShadowing, "'%s' shadows a variable in an enclosing scope.", name.GetLexeme())
+	}
+
+	l.scopes[len(l.scopes)-1][name.GetLexeme()] = &local{name: name, rule: rule}
+}
+
+func (l *linter) shadows(name string) bool {
+	for _, scope := range l.scopes[:len(l.scopes)-1] {
+		if _, ok := scope[name]; ok {
+			return true
+		}
+	}
+
+	return l.globals[name]
+}
+
+func (l *linter) use(name token.Token) {
+	for i := len(l.scopes) - 1; i >= 0; i-- {
+		if v, ok := l.scopes[i][name.GetLexeme()]; ok {
+			v.used = true
+			return
+		}
+	}
+}
+
+func (l *linter) VisitBlockStmt(stmt *generated.BlockStmt) (interface{}, error) {
+	l.lintBlock(stmt.Statements)
+	return nil, nil
+}
+
+func (l *linter) VisitVarStmt(stmt *generated.VarStmt) (interface{}, error) {
+	if stmt.Initializer != nil {
+		l.lint(stmt.Initializer)
+	}
+
+	l.declare(stmt.Name, RuleUnusedVariable)
+
+	return nil, nil
+}
+
+func (l *linter) VisitFunctionStmt(stmt *generated.FunctionStmt) (interface{}, error) {
+	l.declare(stmt.Name, RuleUnusedVariable)
+
+	l.beginScope()
+	for idx, param := range stmt.Params {
+		if stmt.Defaults[idx] != nil {
+			l.lint(stmt.Defaults[idx])
+		}
+
+		l.declare(param, RuleUnusedParameter)
+	}
+
+	if stmt.Rest != nil {
+		l.declare(stmt.Rest, RuleUnusedParameter)
+	}
+
+	l.lintStmts(stmt.Body)
+	l.endScope()
+
+	return nil, nil
+}
+
+func (l *linter) VisitExprStmt(stmt *generated.ExprStmt) (interface{}, error) {
+	l.lint(stmt.Expr)
+	return nil, nil
+}
+
+func (l *linter) VisitPrintStmt(stmt *generated.PrintStmt) (interface{}, error) {
+	l.lint(stmt.Expr)
+	return nil, nil
+}
+
+func (l *linter) VisitIfStmt(stmt *generated.IfStmt) (interface{}, error) {
+	l.lint(stmt.Condition)
+	stmt.IfBranch.Accept(l)
+
+	if stmt.ElseBranch != nil {
+		stmt.ElseBranch.Accept(l)
+	}
+
+	return nil, nil
+}
+
+func (l *linter) VisitWhileStmt(stmt *generated.WhileStmt) (interface{}, error) {
+	l.lint(stmt.Condition)
+	stmt.Stmt.Accept(l)
+
+	return nil, nil
+}
+
+func (l *linter) VisitReturnStmt(stmt *generated.ReturnStmt) (interface{}, error) {
+	if stmt.Value != nil {
+		l.lint(stmt.Value)
+	}
+
+	return nil, nil
+}
+
+func (l *linter) VisitImportStmt(stmt *generated.ImportStmt) (interface{}, error) {
+	if stmt.Alias != nil {
+		l.declare(stmt.Alias, RuleUnusedVariable)
+		return nil, nil
+	}
+
+	for _, name := range stmt.Names {
+		l.declare(name, RuleUnusedVariable)
+	}
+
+	return nil, nil
+}
+
+func (l *linter) VisitThrowStmt(stmt *generated.ThrowStmt) (interface{}, error) {
+	l.lint(stmt.Value)
+	return nil, nil
+}
+
+func (l *linter) VisitTryStmt(stmt *generated.TryStmt) (interface{}, error) {
+	l.lintBlock(stmt.Body)
+
+	if stmt.CatchName != nil {
+		l.beginScope()
+		l.declare(stmt.CatchName, "")
+		l.lintBlock(stmt.CatchBody)
+		l.endScope()
+	}
+
+	if stmt.FinallyBody != nil {
+		l.lintBlock(stmt.FinallyBody)
+	}
+
+	return nil, nil
+}
+
+func (l *linter) VisitTestStmt(stmt *generated.TestStmt) (interface{}, error) {
+	l.lintBlock(stmt.Body)
+	return nil, nil
+}
+
+func (l *linter) VisitMatchStmt(stmt *generated.MatchStmt) (interface{}, error) {
+	l.lintMatch(stmt.Subject, stmt.Cases)
+	return nil, nil
+}
+
+func (l *linter) VisitMatchExpr(expr *generated.MatchExpr) (interface{}, error) {
+	l.lintMatch(expr.Subject, expr.Cases)
+	return nil, nil
+}
+
+func (l *linter) lintMatch(subject generated.Expr, cases []*generated.MatchCase) {
+	l.lint(subject)
+
+	for _, mc := range cases {
+		l.beginScope()
+
+		if mc.Binding != nil {
+			l.declare(mc.Binding, RuleUnusedVariable)
+		}
+
+		for _, pattern := range mc.Patterns {
+			l.lint(pattern)
+		}
+
+		if mc.Guard != nil {
+			l.lint(mc.Guard)
+		}
+
+		if mc.Body != nil {
+			mc.Body.Accept(l)
+		} else {
+			l.lint(mc.Value)
+		}
+
+		l.endScope()
+	}
+}
+
+func (l *linter) VisitAssign(expr *generated.Assign) (interface{}, error) {
+	if v, ok := expr.Value.(*generated.VarExpr); ok && v.Name.GetLexeme() == expr.Name.GetLexeme() {
+		l.warn(expr.Name.GetLine(), RuleSelfAssignment, "'%s' is assigned to itself.", expr.Name.GetLexeme())
+	}
+
+	l.lint(expr.Value)
+
+	return nil, nil
+}
+
+func (l *linter) VisitLogical(expr *generated.Logical) (interface{}, error) {
+	l.lint(expr.Left)
+	l.lint(expr.Right)
+
+	return nil, nil
+}
+
+func (l *linter) VisitBinary(expr *generated.Binary) (interface{}, error) {
+	l.lint(expr.Left)
+	l.lint(expr.Right)
+
+	if result, ok := constantComparison(expr); ok {
+		l.warn(expr.Operator.GetLine(), RuleConstantComparison, "Comparison is always %t.", result)
+	}
+
+	return nil, nil
+}
+
+// constantComparison reports whether expr compares two literals, and if
+// so what the comparison always evaluates to. A variable compared with
+// itself is not reported: that is false for nan, and an error for values
+// that do not compare at all.
+func constantComparison(expr *generated.Binary) (bool, bool) {
+	op := expr.Operator.GetType()
+	switch op {
+	case token.EQUAL_EQUAL, token.BANG_EQUAL, token.GREATER, token.GREATER_EQUAL, token.LESS, token.LESS_EQUAL:
+	default:
+		return false, false
+	}
+
+	ll, lok := expr.Left.(*generated.Literal)
+	rl, rok := expr.Right.(*generated.Literal)
+	if !lok || !rok {
+		return false, false
+	}
+
+	switch op {
+	case token.EQUAL_EQUAL:
+		return literalsEqual(ll.Value, rl.Value), true
+	case token.BANG_EQUAL:
+		return !literalsEqual(ll.Value, rl.Value), true
+	}
+
+	a, aok := toFloat(ll.Value)
+	b, bok := toFloat(rl.Value)
+	if !aok || !bok {
+		return false, false
+	}
+
+	switch op {
+	case token.GREATER:
+		return a > b, true
+	case token.GREATER_EQUAL:
+		return a >= b, true
+	case token.LESS:
+		return a < b, true
+	}
+
+	return a <= b, true
+}
+
+func literalsEqual(a, b interface{}) bool {
+	af, aok := toFloat(a)
+	bf, bok := toFloat(b)
+	if aok && bok {
+		return af == bf
+	}
+
+	return a == b
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case int64:
+		return float64(n), true
+	case float64:
+		return n, true
+	}
+
+	return 0, false
+}
+
+func (l *linter) VisitTernary(expr *generated.Ternary) (interface{}, error) {
+	l.lint(expr.Condition)
+	l.lint(expr.ValueTrue)
+	l.lint(expr.ValueFalse)
+
+	return nil, nil
+}
+
+func (l *linter) VisitGrouping(expr *generated.Grouping) (interface{}, error) {
+	l.lint(expr.Expression)
+	return nil, nil
+}
+
+func (l *linter) VisitLiteral(expr *generated.Literal) (interface{}, error) {
+	return nil, nil
+}
+
+func (l *linter) VisitUnary(expr *generated.Unary) (interface{}, error) {
+	l.lint(expr.Right)
+	return nil, nil
+}
+
+func (l *linter) VisitCall(expr *generated.Call) (interface{}, error) {
+	l.lint(expr.Callee)
+
+	for _, arg := range expr.Arguments {
+		l.lint(arg)
+	}
+
+	return nil, nil
+}
+
+func (l *linter) VisitVarExpr(expr *generated.VarExpr) (interface{}, error) {
+	l.use(expr.Name)
+	return nil, nil
+}
+
+func (l *linter) VisitGet(expr *generated.Get) (interface{}, error) {
+	l.lint(expr.Object)
+	return nil, nil
+}
@@ -0,0 +1,62 @@
+package lint
+
+import (
+	"fmt"
+	"glox/parser"
+	"glox/scanner"
+	"reflect"
+	"testing"
+)
+
+func TestLint(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+		want   []string
+	}{
+		{"unused variable", "fun f() {\n  var a = 1;\n}\nf();", []string{"2 unused-variable: Local variable 'a' is never used."}},
+		{"used variable", "fun f() { var a = 1; return a; } f();", nil},
+		{"underscore variable", "fun f() { var _a = 1; } f();", nil},
+		{"unused global", "var a = 1;", nil},
+
+		{"unused parameter", "fun f(a,\n  b) { return a; } f(1, 2);", []string{"2 unused-parameter: Parameter 'b' is never used."}},
+		{"used parameters", "fun f(a, ...rest) { return a + len(rest); } f(1);", nil},
+
+		{"code after return", "fun f() {\n  return 1;\n  print 2;\n  print 3;\n} f();", []string{"3 unreachable-code: Code after return is unreachable."}},
+		{"code after throw", "fun f() {\n  if (true) { throw 1; } else {\n    return;\n  }\n\n  print 2;\n} f();", []string{"6 unreachable-code: Code after throw is unreachable."}},
+		{"return last", "fun f() { print 1; return; } f();", nil},
+		{"one branch returns", "fun f(a) { if (a) return; print 1; } f(1);", nil},
+
+		{"shadowing", "var a = 1;\nfun f() {\n  var a = 2;\n  return a;\n} f();", []string{"3 shadowing: 'a' shadows a variable in an enclosing scope."}},
+		{"no shadowing", "fun f() { var a = 1; return a; } fun g() { var a = 2; return a; } f(); g();", nil},
+
+		{"self-assignment", "var a = 1;\na = a;", []string{"2 self-assignment: 'a' is assigned to itself."}},
+		{"assignment", "var a = 1; var b = 2; a = b;", nil},
+
+		{"literal comparison", "print 1 < 2;\nprint \"a\" == \"b\";", []string{"1 constant-comparison: Comparison is always true.", "2 constant-comparison: Comparison is always false."}},
+		{"variable compared with itself", "var x = nan; print x == x; print x >= x; var l = list(); print l == l;", nil},
+		{"variable comparison", "var x = 1; print x < 2;", nil},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tokens, err := scanner.NewScanner(tt.source).ScanTokens()
+			if err != nil {
+				t.Fatal(err)
+			}
+			stmts, err := parser.NewParser(tokens).Parse()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got []string
+			for _, w := range NewLinter().Lint(stmts) {
+				got = append(got, fmt.Sprintf("%d %s: %s", w.Line, w.Rule, w.Message))
+			}
+
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
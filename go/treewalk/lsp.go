@@ -0,0 +1,12 @@
+package main
+
+import (
+	"glox/lsp"
+	"os"
+)
+
+// lspCmd implements `glox lsp`, a language server speaking LSP over stdin
+// and stdout.
+func lspCmd() int {
+	return lsp.NewServer(os.Stdin, os.Stdout).Run()
+}
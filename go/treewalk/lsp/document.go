@@ -0,0 +1,425 @@
+package lsp
+
+import (
+	"glox/generated"
+	"glox/interpreter"
+	"glox/lerr"
+	"glox/parser"
+	"glox/resolver"
+	"glox/scanner"
+	"glox/token"
+	"io"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// builtins maps the names of the globals every script starts with to
+// their completion item kind.
+var builtins = func() map[string]int {
+	// The interpreter never runs anything here, but whatever it printed
+	// must not reach the protocol stream.
+	env := interpreter.NewInterpreter(interpreter.WithStdout(io.Discard)).GetGlobalEnv()
+
+	kinds := map[string]int{}
+	for _, name := range env.Names() {
+		v, _ := env.Get(token.NewToken(token.IDENTIFIER, name, nil, 0, 0))
+		if _, ok := v.(interpreter.LoxCallable); ok {
+			kinds[name] = CompletionFunction
+		} else {
+			kinds[name] = CompletionVariable
+		}
+	}
+
+	return kinds
+}()
+
+// document is the analysis of one version of an open file. The resolver
+// fills in decls and refs through the resolver.Indexer interface.
+type document struct {
+	uri         string
+	lines       []string
+	tokens      []token.Token
+	index       map[token.Token]int
+	stmts       []generated.Stmt
+	diagnostics []Diagnostic
+	decls       []token.Token
+	declared    map[token.Token]bool
+	refs        map[token.Token]token.Token
+}
+
+func newDocument(uri, text string) *document {
+	d := &document{
+		uri:         uri,
+		lines:       strings.Split(text, "\n"),
+		index:       map[token.Token]int{},
+		diagnostics: []Diagnostic{},
+		declared:    map[token.Token]bool{},
+		refs:        map[token.Token]token.Token{},
+	}
+
+	d.analyze(text)
+
+	return d
+}
+
+// analyze runs the front end over text. Navigation only works once the
+// whole file parses; until then only completion of keywords and builtins
+// is offered.
+func (d *document) analyze(text string) {
+	tokens, err := scanner.NewScanner(text).ScanTokens()
+	if err != nil {
+		d.report(err)
+		return
+	}
+
+	d.tokens = tokens
+	for idx, t := range tokens {
+		d.index[t] = idx
+	}
+
+	stmts, err := parser.NewParser(tokens).Parse()
+	if err != nil {
+		d.report(err)
+		return
+	}
+
+	d.stmts = stmts
+
+	err = resolver.NewResolver(d).Resolve(stmts)
+	if err != nil {
+		d.report(err)
+	}
+}
+
+// report adds a diagnostic for err, covering the source it was found in,
+// or the whole line when only that is known.
+func (d *document) report(err error) {
+	diag := lerr.Diagnose(err)
+
+	line := diag.Line
+	if line < 1 {
+		line = 1
+	}
+	if line > len(d.lines) {
+		line = len(d.lines)
+	}
+
+	r := d.spanRange(lerr.Span{
+		Start: lerr.Position{Line: line, Column: 1},
+		End:   lerr.Position{Line: line, Column: utf8.RuneCountInString(d.lines[line-1]) + 1},
+	})
+	if span := diag.Span; span != nil && span.End.Line <= len(d.lines) {
+		r = d.spanRange(*span)
+	}
+
+	d.diagnostics = append(d.diagnostics, Diagnostic{
+		Range:    r,
+		Severity: SeverityError,
+		Source:   "glox",
+		Message:  diag.Message,
+	})
+}
+
+// Resolve implements resolver.Binder. The server never runs scripts, so
+// the scope distances are not needed.
+func (d *document) Resolve(generated.Expr, int) {}
+
+func (d *document) Declare(name token.Token) {
+	d.decls = append(d.decls, name)
+	d.declared[name] = true
+}
+
+func (d *document) Reference(use token.Token, decl token.Token) {
+	d.refs[use] = decl
+}
+
+// tokenRange returns the range of the source t was scanned from.
+func (d *document) tokenRange(t token.Token) Range {
+	return d.spanRange(lerr.SpanOf(t))
+}
+
+// spanRange returns the range of a span. Spans count columns in runes from
+// 1, while LSP counts characters in UTF-16 code units from 0, so the two
+// differ on lines holding characters outside the Basic Multilingual Plane.
+func (d *document) spanRange(span lerr.Span) Range {
+	return Range{Start: d.position(span.Start), End: d.position(span.End)}
+}
+
+func (d *document) position(p lerr.Position) Position {
+	line, col := p.Line-1, p.Column-1
+
+	char := 0
+	if line >= 0 && line < len(d.lines) {
+		for _, r := range d.lines[line] {
+			if col == 0 {
+				break
+			}
+			if r >= 0x10000 {
+				char += 2
+			} else {
+				char++
+			}
+			col--
+		}
+	}
+
+	return Position{Line: line, Character: char + col}
+}
+
+func (d *document) location(t token.Token) Location {
+	return Location{URI: d.uri, Range: d.tokenRange(t)}
+}
+
+// identifierAt returns the identifier touching pos, or nil.
+func (d *document) identifierAt(pos Position) token.Token {
+	for _, t := range d.tokens {
+		if t.GetType() != token.IDENTIFIER {
+			continue
+		}
+
+		r := d.tokenRange(t)
+		if r.Start.Line == pos.Line && r.Start.Character <= pos.Character && pos.Character <= r.End.Character {
+			return t
+		}
+	}
+
+	return nil
+}
+
+// declaration returns the declaration t refers to, which is t itself when
+// t is a declaration, or nil for builtins and undefined names.
+func (d *document) declaration(t token.Token) token.Token {
+	if t == nil || d.declared[t] {
+		return t
+	}
+
+	return d.refs[t]
+}
+
+func (d *document) definition(pos Position) *Location {
+	decl := d.declaration(d.identifierAt(pos))
+	if decl == nil {
+		return nil
+	}
+
+	loc := d.location(decl)
+
+	return &loc
+}
+
+func (d *document) references(pos Position, includeDeclaration bool) []Location {
+	locs := []Location{}
+
+	decl := d.declaration(d.identifierAt(pos))
+	if decl == nil {
+		return locs
+	}
+
+	for _, t := range d.tokens {
+		if (t == decl && includeDeclaration) || d.refs[t] == decl {
+			locs = append(locs, d.location(t))
+		}
+	}
+
+	return locs
+}
+
+// hover shows the source line declaring the identifier at pos.
+func (d *document) hover(pos Position) *Hover {
+	t := d.identifierAt(pos)
+	if t == nil {
+		return nil
+	}
+
+	var text string
+	if decl := d.declaration(t); decl != nil {
+		text = "```lox\n" + strings.TrimSpace(d.lines[decl.GetLine()-1]) + "\n```"
+	} else if _, ok := builtins[t.GetLexeme()]; ok {
+		text = "(builtin) " + t.GetLexeme()
+	} else {
+		return nil
+	}
+
+	return &Hover{
+		Contents: markupContent{Kind: "markdown", Value: text},
+		Range:    d.tokenRange(t),
+	}
+}
+
+// symbols lists the functions, globals and imports declared at the top
+// level of the document.
+func (d *document) symbols() []DocumentSymbol {
+	symbols := []DocumentSymbol{}
+	add := func(name token.Token, kind int, detail string) {
+		symbols = append(symbols, DocumentSymbol{
+			Name:           name.GetLexeme(),
+			Detail:         detail,
+			Kind:           kind,
+			Range:          d.tokenRange(name),
+			SelectionRange: d.tokenRange(name),
+		})
+	}
+
+	for _, stmt := range d.stmts {
+		switch s := stmt.(type) {
+		case *generated.FunctionStmt:
+			add(s.Name, SymbolFunction, signature(s))
+		case *generated.VarStmt:
+			var detail string
+			if s.Type != nil {
+				detail = s.Type.GetLexeme()
+			}
+			add(s.Name, SymbolVariable, detail)
+		case *generated.ImportStmt:
+			if s.Alias != nil {
+				add(s.Alias, SymbolModule, s.Path.GetLexeme())
+			}
+			for _, name := range s.Names {
+				add(name, SymbolVariable, s.Path.GetLexeme())
+			}
+		}
+	}
+
+	return symbols
+}
+
+func signature(stmt *generated.FunctionStmt) string {
+	params := []string{}
+	for idx, param := range stmt.Params {
+		p := param.GetLexeme()
+		if stmt.ParamTypes[idx] != nil {
+			p += ": " + stmt.ParamTypes[idx].GetLexeme()
+		}
+		if stmt.Defaults[idx] != nil {
+			p += " = …"
+		}
+		params = append(params, p)
+	}
+
+	if stmt.Rest != nil {
+		params = append(params, "..."+stmt.Rest.GetLexeme())
+	}
+
+	sig := "fun(" + strings.Join(params, ", ") + ")"
+	if stmt.ReturnType != nil {
+		sig += ": " + stmt.ReturnType.GetLexeme()
+	}
+
+	return sig
+}
+
+// completion offers the keywords, the builtins and the variables in scope
+// at pos.
+func (d *document) completion(pos Position) []CompletionItem {
+	items := []CompletionItem{}
+	seen := map[string]bool{}
+	add := func(label string, kind int, detail string) {
+		if !seen[label] {
+			seen[label] = true
+			items = append(items, CompletionItem{Label: label, Kind: kind, Detail: detail})
+		}
+	}
+
+	// Inner declarations come later in the source, so walking backwards
+	// lets them win over the outer ones they shadow.
+	for idx := len(d.decls) - 1; idx >= 0; idx-- {
+		decl := d.decls[idx]
+		if !d.inScope(decl, pos) {
+			continue
+		}
+
+		kind := CompletionVariable
+		if i := d.index[decl]; i > 0 && d.tokens[i-1].GetType() == token.FUN {
+			kind = CompletionFunction
+		}
+		add(decl.GetLexeme(), kind, "")
+	}
+
+	names := make([]string, 0, len(builtins))
+	for name := range builtins {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		add(name, builtins[name], "builtin")
+	}
+
+	keywords := make([]string, 0, len(token.Keywords))
+	for kw := range token.Keywords {
+		keywords = append(keywords, kw)
+	}
+	sort.Strings(keywords)
+	for _, kw := range keywords {
+		add(kw, CompletionKeyword, "")
+	}
+
+	return items
+}
+
+// inScope reports whether decl is visible at pos: it must be declared
+// before pos, and pos must come before the end of decl's scope. Globals are
+// visible everywhere, since functions may use globals declared after them.
+func (d *document) inScope(decl token.Token, pos Position) bool {
+	end := d.scopeEnd(d.index[decl])
+	if end == len(d.tokens) {
+		return true
+	}
+
+	return before(d.tokenRange(decl).End, pos) && before(pos, d.tokenRange(d.tokens[end]).Start)
+}
+
+func before(a, b Position) bool {
+	return a.Line < b.Line || (a.Line == b.Line && a.Character <= b.Character)
+}
+
+// scopeEnd returns the index of the token closing the scope the
+// declaration at idx belongs to, or len(d.tokens) for a global. Names
+// declared in parentheses, such as parameters and the name of a caught
+// exception, belong to the block after the closing parenthesis.
+func (d *document) scopeEnd(idx int) int {
+	depth := 0
+	for i := idx - 1; i >= 0; i-- {
+		switch d.tokens[i].GetType() {
+		case token.RIGHT_PAREN, token.RIGHT_BRACE:
+			depth++
+		case token.LEFT_PAREN, token.LEFT_BRACE:
+			if depth > 0 {
+				depth--
+				continue
+			}
+
+			if d.tokens[i].GetType() == token.LEFT_BRACE {
+				return d.closing(i)
+			}
+
+			after := d.closing(i) + 1
+			if after < len(d.tokens) && d.tokens[after].GetType() == token.LEFT_BRACE {
+				return d.closing(after)
+			}
+
+			return d.scopeEnd(i)
+		}
+	}
+
+	return len(d.tokens)
+}
+
+// closing returns the index of the token closing the bracket at idx, or
+// the index of the EOF token if it is never closed.
+func (d *document) closing(idx int) int {
+	depth := 0
+	for i := idx; i < len(d.tokens); i++ {
+		switch d.tokens[i].GetType() {
+		case token.LEFT_PAREN, token.LEFT_BRACE:
+			depth++
+		case token.RIGHT_PAREN, token.RIGHT_BRACE:
+			depth--
+			if depth == 0 {
+				return i
+			}
+		}
+	}
+
+	return len(d.tokens) - 1
+}
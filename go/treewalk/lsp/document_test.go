@@ -0,0 +1,32 @@
+package lsp
+
+import (
+	"reflect"
+	"testing"
+)
+
+// TestUTF16 checks that positions count UTF-16 code units, in which the
+// emoji below takes two, both in results and in requests.
+func TestUTF16(t *testing.T) {
+	d := newDocument("file:///a.lox", "var s = \"😀\"; var x = s;\nprint x; print \"😀\" + ;")
+
+	want := Range{Start: Position{Line: 1, Character: 22}, End: Position{Line: 1, Character: 23}}
+	if len(d.diagnostics) != 1 || d.diagnostics[0].Range != want {
+		t.Errorf("got diagnostics %+v, want one at %+v", d.diagnostics, want)
+	}
+
+	// Navigation needs a file that parses.
+	d = newDocument("file:///a.lox", "var s = \"😀\"; var x = s;\nprint x;")
+
+	loc := d.definition(Position{Line: 1, Character: 6})
+	want = Range{Start: Position{Line: 0, Character: 18}, End: Position{Line: 0, Character: 19}}
+	if loc == nil || loc.Range != want {
+		t.Fatalf("got definition %+v, want %+v", loc, want)
+	}
+
+	refs := d.references(Position{Line: 0, Character: 18}, false)
+	wantRefs := []Location{{URI: "file:///a.lox", Range: Range{Start: Position{Line: 1, Character: 6}, End: Position{Line: 1, Character: 7}}}}
+	if !reflect.DeepEqual(refs, wantRefs) {
+		t.Errorf("got references %+v, want %+v", refs, wantRefs)
+	}
+}
@@ -0,0 +1,217 @@
+package lsp
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/textproto"
+	"strconv"
+)
+
+// Server is a language server for Lox speaking JSON-RPC over a pair of
+// streams. Documents are synchronized in full on every change and analyzed
+// with the scanner, parser and resolver.
+type Server struct {
+	in       *bufio.Reader
+	out      io.Writer
+	docs     map[string]*document
+	shutdown bool
+}
+
+func NewServer(in io.Reader, out io.Writer) *Server {
+	return &Server{
+		in:   bufio.NewReader(in),
+		out:  out,
+		docs: map[string]*document{},
+	}
+}
+
+// Run serves requests until the client sends exit or closes the input, and
+// returns the exit status the protocol asks for: 0 if the client asked for
+// a shutdown first and 1 otherwise.
+func (s *Server) Run() int {
+	for {
+		body, err := s.read()
+		if err != nil {
+			break
+		}
+
+		var req request
+		if err := json.Unmarshal(body, &req); err != nil {
+			s.reply(nil, nil, &responseError{Code: codeParseError, Message: err.Error()})
+			continue
+		}
+
+		if req.Method == "exit" {
+			break
+		}
+
+		s.handle(&req)
+	}
+
+	if s.shutdown {
+		return 0
+	}
+
+	return 1
+}
+
+// read returns the body of the next message.
+func (s *Server) read() ([]byte, error) {
+	header, err := textproto.NewReader(s.in).ReadMIMEHeader()
+	if err != nil {
+		return nil, err
+	}
+
+	length, err := strconv.Atoi(header.Get("Content-Length"))
+	if err != nil {
+		return nil, fmt.Errorf("invalid Content-Length: %w", err)
+	}
+
+	body := make([]byte, length)
+	_, err = io.ReadFull(s.in, body)
+
+	return body, err
+}
+
+func (s *Server) write(msg interface{}) {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return
+	}
+
+	fmt.Fprintf(s.out, "Content-Length: %d\r\n\r\n%s", len(body), body)
+}
+
+func (s *Server) reply(id *json.RawMessage, result interface{}, rerr *responseError) {
+	if rerr != nil {
+		s.write(errorResponse{JSONRPC: "2.0", ID: id, Error: rerr})
+		return
+	}
+
+	s.write(response{JSONRPC: "2.0", ID: id, Result: result})
+}
+
+func (s *Server) notify(method string, params interface{}) {
+	s.write(notification{JSONRPC: "2.0", Method: method, Params: params})
+}
+
+// handle dispatches one request or notification. Notifications, which
+// have no ID, never get a reply.
+func (s *Server) handle(req *request) {
+	defer func() {
+		if r := recover(); r != nil && req.ID != nil {
+			s.reply(req.ID, nil, &responseError{Code: codeInternalError, Message: fmt.Sprint(r)})
+		}
+	}()
+
+	result, rerr := s.dispatch(req)
+	if req.ID != nil {
+		s.reply(req.ID, result, rerr)
+	}
+}
+
+func (s *Server) dispatch(req *request) (interface{}, *responseError) {
+	switch req.Method {
+	case "initialize":
+		return map[string]interface{}{
+			"capabilities": map[string]interface{}{
+				"textDocumentSync":       1,
+				"definitionProvider":     true,
+				"referencesProvider":     true,
+				"hoverProvider":          true,
+				"documentSymbolProvider": true,
+				"completionProvider":     map[string]interface{}{},
+			},
+			"serverInfo": map[string]string{"name": "glox"},
+		}, nil
+	case "initialized":
+		return nil, nil
+	case "shutdown":
+		s.shutdown = true
+		return nil, nil
+
+	case "textDocument/didOpen":
+		var params didOpenParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+
+		s.open(params.TextDocument.URI, params.TextDocument.Text)
+		return nil, nil
+	case "textDocument/didChange":
+		var params didChangeParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+
+		// With full synchronization the last change holds the whole text.
+		if n := len(params.ContentChanges); n > 0 {
+			s.open(params.TextDocument.URI, params.ContentChanges[n-1].Text)
+		}
+		return nil, nil
+	case "textDocument/didClose":
+		var params didCloseParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+
+		delete(s.docs, params.TextDocument.URI)
+		s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+			URI:         params.TextDocument.URI,
+			Diagnostics: []Diagnostic{},
+		})
+		return nil, nil
+
+	case "textDocument/definition", "textDocument/references", "textDocument/hover", "textDocument/completion":
+		var params positionParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+
+		doc, ok := s.docs[params.TextDocument.URI]
+		if !ok {
+			return nil, nil
+		}
+
+		switch req.Method {
+		case "textDocument/definition":
+			return doc.definition(params.Position), nil
+		case "textDocument/references":
+			return doc.references(params.Position, params.Context.IncludeDeclaration), nil
+		case "textDocument/hover":
+			return doc.hover(params.Position), nil
+		}
+		return doc.completion(params.Position), nil
+	case "textDocument/documentSymbol":
+		var params documentParams
+		if err := json.Unmarshal(req.Params, &params); err != nil {
+			return nil, invalidParams(err)
+		}
+
+		doc, ok := s.docs[params.TextDocument.URI]
+		if !ok {
+			return []DocumentSymbol{}, nil
+		}
+		return doc.symbols(), nil
+	}
+
+	return nil, &responseError{Code: codeMethodNotFound, Message: "Method not found: " + req.Method}
+}
+
+// open analyzes a new version of a document and publishes its
+// diagnostics.
+func (s *Server) open(uri, text string) {
+	doc := newDocument(uri, text)
+	s.docs[uri] = doc
+
+	s.notify("textDocument/publishDiagnostics", publishDiagnosticsParams{
+		URI:         uri,
+		Diagnostics: doc.diagnostics,
+	})
+}
+
+func invalidParams(err error) *responseError {
+	return &responseError{Code: codeInvalidParams, Message: err.Error()}
+}
@@ -2,9 +2,15 @@ package main
 
 import (
 	"bufio"
+	"flag"
 	"fmt"
+	"glox/cache"
+	"glox/checker"
+	"glox/generated"
 	"glox/interpreter"
+	"glox/optimizer"
 	"glox/parser"
+	"glox/profiler"
 	"glox/resolver"
 	"glox/scanner"
 	"log"
@@ -12,28 +18,108 @@ import (
 )
 
 func main() {
-	args := os.Args
-	if len(args) > 2 {
-		log.Panic("cannot take more than one arguments")
+	if len(os.Args) > 1 {
+		switch os.Args[1] {
+		case "lint":
+			os.Exit(lintCmd(os.Args[2:]))
+		case "lsp":
+			os.Exit(lspCmd())
+		case "debug":
+			os.Exit(debugCmd(os.Args[2:]))
+		case "test":
+			os.Exit(testCmd(os.Args[2:]))
+		case "build":
+			os.Exit(buildCmd(os.Args[2:]))
+		}
+	}
+
+	seed := flag.Int64("seed", 0, "seed for the random number natives")
+	check := flag.Bool("check", false, "type check the script without running it")
+	optimize := flag.Bool("O", false, "fold constant expressions and remove dead branches before running")
+	profile := flag.String("profile", "", "profile the script, printing a report to stderr and writing a pprof `file`")
+	cached := flag.Bool("cache", false, "cache the resolved tree of each script next to it, as script.loxc")
+	diagnostics := flag.String("diagnostics", "text", "report errors as text, or as JSON lines on stderr with json")
+	flag.Parse()
+
+	if *diagnostics != "text" && *diagnostics != "json" {
+		fmt.Fprintf(os.Stderr, "Unknown diagnostics format '%s'.\n", *diagnostics)
+		os.Exit(64)
+	}
+
+	var opts []interpreter.Option
+	flag.Visit(func(f *flag.Flag) {
+		if f.Name == "seed" {
+			opts = append(opts, interpreter.WithSeed(*seed))
+		}
+	})
+
+	if *optimize {
+		opts = append(opts, interpreter.WithOptimizer(optimizer.NewOptimizer()))
+	}
+
+	args := flag.Args()
+	r := reporter{json: *diagnostics == "json"}
+	if len(args) > 0 {
+		r.file = args[0]
+	}
+
+	if *check {
+		if len(args) == 0 {
+			fmt.Fprintln(os.Stderr, "Usage: glox --check script")
+			os.Exit(64)
+		}
+
+		checkFile(args[0], r)
 		return
-	} else if len(args) == 1 {
-		runPrompt()
+	}
+
+	var prof *profiler.Profiler
+	if *profile != "" {
+		if len(args) == 0 {
+			fmt.Fprintln(os.Stderr, "Usage: glox --profile file script")
+			os.Exit(64)
+		}
+
+		prof = profiler.NewProfiler()
+		opts = append(opts, interpreter.WithProfiler(prof))
+	}
+
+	var status int
+	if len(args) == 0 {
+		status = runPrompt(r, opts...)
 	} else {
-		runFile(args[1])
+		opts = append(opts, interpreter.WithArgs(args[1:]), interpreter.WithScriptPath(args[0]))
+		if *cached {
+			opts = append(opts, interpreter.WithCache())
+		}
+		status = runFile(args[0], *cached, r, opts...)
+	}
+
+	// Failing scripts are profiled too, so the profile is written before
+	// exiting rather than deferred.
+	if prof != nil {
+		writeProfile(prof, *profile)
 	}
+
+	os.Exit(status)
 }
 
-func runPrompt() {
+// runPrompt runs each line read from stdin as a script, and returns the
+// exit status of the prompt.
+func runPrompt(r reporter, opts ...interpreter.Option) int {
 	scanner := bufio.NewScanner(os.Stdin)
 	fmt.Print("> ")
 	for scanner.Scan() {
 		if scanner.Text() == "exit" {
-			os.Exit(64)
+			return 64
 		}
 
-		err := run(scanner.Text())
+		status, err := run("", scanner.Text(), r, opts...)
 		if err != nil {
-			fmt.Print(err)
+			r.report(err, false)
+		}
+		if status != 0 {
+			return status
 		}
 
 		fmt.Print("> ")
@@ -42,43 +128,112 @@ func runPrompt() {
 	if err := scanner.Err(); err != nil {
 		log.Panic(err)
 	}
+
+	return 0
 }
 
-func runFile(file string) {
+// runFile runs the script in file, reading its resolved tree from the AST
+// cache if cached is set, and returns its exit status.
+func runFile(file string, cached bool, r reporter, opts ...interpreter.Option) int {
 	prog, err := os.ReadFile(file)
 	if err != nil {
 		log.Panic(err)
 	}
 
-	err = run(string(prog))
+	path := ""
+	if cached {
+		path = file
+	}
+
+	status, err := run(path, string(prog), r, opts...)
 	if err != nil {
-		fmt.Print(err)
-		os.Exit(65)
+		r.report(err, false)
+		return 65
 	}
+
+	return status
 }
 
-func run(source string) error {
-	scanner := scanner.NewScanner(source)
-	tokens, err := scanner.ScanTokens()
+// checkFile type checks a script without running it, printing every
+// error found.
+func checkFile(file string, r reporter) {
+	prog, err := os.ReadFile(file)
 	if err != nil {
 		log.Panic(err)
 	}
 
-	parser := parser.NewParser(tokens)
-	stmts, err := parser.Parse()
+	stmts, err := parse(string(prog))
 	if err != nil {
-		return nil
+		r.report(err, true)
+		os.Exit(65)
 	}
 
-	interpreter := interpreter.NewInterpreter()
-	resolver := resolver.NewResolver(interpreter)
+	err = resolver.NewResolver(interpreter.NewInterpreter()).Resolve(stmts)
+	if err != nil {
+		r.report(err, true)
+		os.Exit(65)
+	}
 
-	err = resolver.Resolve(stmts)
+	errs := checker.NewChecker().Check(stmts)
+	for _, err := range errs {
+		r.report(err, true)
+	}
+
+	if len(errs) != 0 {
+		os.Exit(65)
+	}
+}
+
+// writeProfile prints the flat report of a profile to stderr and saves it
+// for go tool pprof.
+func writeProfile(prof *profiler.Profiler, file string) {
+	prof.Report(os.Stderr)
+
+	f, err := os.Create(file)
 	if err != nil {
-		return err
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(74)
 	}
+	defer f.Close()
 
-	interpreter.Interpret(stmts)
+	if err := prof.WritePprof(f); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(74)
+	}
+}
+
+func parse(source string) ([]generated.Stmt, error) {
+	scanner := scanner.NewScanner(source)
+	tokens, err := scanner.ScanTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	parser := parser.NewParser(tokens)
+
+	return parser.Parse()
+}
+
+// run runs source as a script, returning its exit status, or the error
+// that kept it from running. r reports runtime errors. Unless path is
+// empty, the front end goes through the AST cache kept next to the script
+// at path.
+func run(path, source string, r reporter, opts ...interpreter.Option) (int, error) {
+	interpreter := interpreter.NewInterpreter(opts...)
+
+	var stmts []generated.Stmt
+	var err error
+	if path != "" {
+		stmts, err = cache.Load(path, source, interpreter)
+	} else {
+		stmts, err = parse(source)
+		if err == nil {
+			err = resolver.NewResolver(interpreter).Resolve(stmts)
+		}
+	}
+	if err != nil {
+		return 0, err
+	}
 
-	return nil
+	return r.interpret(interpreter, stmts), nil
 }
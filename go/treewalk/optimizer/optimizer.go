@@ -0,0 +1,314 @@
+package optimizer
+
+import (
+	"glox/generated"
+	"glox/interpreter"
+	"glox/token"
+)
+
+// Optimizer rewrites resolved statements so that they do less work when
+// run. It implements interpreter.Optimizer.
+//
+// Expressions whose operands are all literals are folded into literals by
+// evaluating them with an interpreter of their own, so a folded value is
+// exactly what the expression would have produced. Expressions that fail,
+// like "a" - 1, are left alone to fail at run time with the same error.
+// Conditions that fold to constants decide ifs, ternaries, logical
+// operators and loops that never run at compile time.
+//
+// Nodes are rewritten in place, and variables and assignments are never
+// replaced, so that the scopes the resolver bound to them still apply.
+type Optimizer interface {
+	generated.VisitorStmt
+	generated.VisitorExpr
+	Optimize([]generated.Stmt) []generated.Stmt
+}
+
+type optimizer struct {
+	in interpreter.Interpreter
+}
+
+func NewOptimizer() Optimizer {
+	return &optimizer{in: interpreter.NewInterpreter()}
+}
+
+func (o *optimizer) Optimize(stmts []generated.Stmt) []generated.Stmt {
+	return o.stmts(stmts)
+}
+
+// stmts optimizes a list of statements, leaving out those that can never
+// run. A nil list stays nil.
+func (o *optimizer) stmts(stmts []generated.Stmt) []generated.Stmt {
+	if stmts == nil {
+		return nil
+	}
+
+	result := make([]generated.Stmt, 0, len(stmts))
+	for _, stmt := range stmts {
+		if stmt = o.stmt(stmt); stmt != nil {
+			result = append(result, stmt)
+		}
+	}
+
+	return result
+}
+
+// stmt returns the optimized form of stmt, or nil if it does nothing.
+func (o *optimizer) stmt(stmt generated.Stmt) generated.Stmt {
+	if stmt == nil {
+		return nil
+	}
+
+	result, _ := stmt.Accept(o)
+	if result == nil {
+		return nil
+	}
+
+	return result.(generated.Stmt)
+}
+
+// body is like stmt for a statement that must be kept, such as the body of
+// a loop, replacing it with an empty block if it does nothing.
+func (o *optimizer) body(stmt generated.Stmt) generated.Stmt {
+	if stmt = o.stmt(stmt); stmt != nil {
+		return stmt
+	}
+
+	return generated.NewBlockStmt([]generated.Stmt{})
+}
+
+func (o *optimizer) expr(expr generated.Expr) generated.Expr {
+	if expr == nil {
+		return nil
+	}
+
+	result, _ := expr.Accept(o)
+
+	return result.(generated.Expr)
+}
+
+// constant returns the value of expr if it is a literal.
+func constant(expr generated.Expr) (interface{}, bool) {
+	if l, ok := expr.(*generated.Literal); ok {
+		return l.Value, true
+	}
+
+	return nil, false
+}
+
+// fold evaluates an expression whose operands are literals.
+func (o *optimizer) fold(expr generated.Expr) generated.Expr {
+	value, err := expr.Accept(o.in)
+	if err != nil {
+		return expr
+	}
+
+	return generated.NewLiteral(value)
+}
+
+func (o *optimizer) cases(cases []*generated.MatchCase) {
+	for _, mc := range cases {
+		for idx, pattern := range mc.Patterns {
+			mc.Patterns[idx] = o.expr(pattern)
+		}
+
+		mc.Guard = o.expr(mc.Guard)
+		if mc.Body != nil {
+			mc.Body = o.body(mc.Body)
+		}
+		mc.Value = o.expr(mc.Value)
+	}
+}
+
+func (o *optimizer) VisitBlockStmt(stmt *generated.BlockStmt) (interface{}, error) {
+	stmt.Statements = o.stmts(stmt.Statements)
+	return stmt, nil
+}
+
+func (o *optimizer) VisitIfStmt(stmt *generated.IfStmt) (interface{}, error) {
+	stmt.Condition = o.expr(stmt.Condition)
+
+	if cond, ok := constant(stmt.Condition); ok {
+		if interpreter.Truthy(cond) {
+			return o.stmt(stmt.IfBranch), nil
+		}
+
+		return o.stmt(stmt.ElseBranch), nil
+	}
+
+	stmt.IfBranch = o.body(stmt.IfBranch)
+	stmt.ElseBranch = o.stmt(stmt.ElseBranch)
+
+	return stmt, nil
+}
+
+func (o *optimizer) VisitWhileStmt(stmt *generated.WhileStmt) (interface{}, error) {
+	stmt.Condition = o.expr(stmt.Condition)
+
+	if cond, ok := constant(stmt.Condition); ok && !interpreter.Truthy(cond) {
+		return nil, nil
+	}
+
+	stmt.Stmt = o.body(stmt.Stmt)
+
+	return stmt, nil
+}
+
+func (o *optimizer) VisitExprStmt(stmt *generated.ExprStmt) (interface{}, error) {
+	stmt.Expr = o.expr(stmt.Expr)
+	return stmt, nil
+}
+
+func (o *optimizer) VisitPrintStmt(stmt *generated.PrintStmt) (interface{}, error) {
+	stmt.Expr = o.expr(stmt.Expr)
+	return stmt, nil
+}
+
+func (o *optimizer) VisitVarStmt(stmt *generated.VarStmt) (interface{}, error) {
+	stmt.Initializer = o.expr(stmt.Initializer)
+	return stmt, nil
+}
+
+func (o *optimizer) VisitFunctionStmt(stmt *generated.FunctionStmt) (interface{}, error) {
+	for idx, def := range stmt.Defaults {
+		stmt.Defaults[idx] = o.expr(def)
+	}
+
+	stmt.Body = o.stmts(stmt.Body)
+
+	return stmt, nil
+}
+
+func (o *optimizer) VisitReturnStmt(stmt *generated.ReturnStmt) (interface{}, error) {
+	stmt.Value = o.expr(stmt.Value)
+	return stmt, nil
+}
+
+func (o *optimizer) VisitImportStmt(stmt *generated.ImportStmt) (interface{}, error) {
+	return stmt, nil
+}
+
+func (o *optimizer) VisitThrowStmt(stmt *generated.ThrowStmt) (interface{}, error) {
+	stmt.Value = o.expr(stmt.Value)
+	return stmt, nil
+}
+
+func (o *optimizer) VisitTryStmt(stmt *generated.TryStmt) (interface{}, error) {
+	stmt.Body = o.stmts(stmt.Body)
+	stmt.CatchBody = o.stmts(stmt.CatchBody)
+	stmt.FinallyBody = o.stmts(stmt.FinallyBody)
+	return stmt, nil
+}
+
+func (o *optimizer) VisitMatchStmt(stmt *generated.MatchStmt) (interface{}, error) {
+	stmt.Subject = o.expr(stmt.Subject)
+	o.cases(stmt.Cases)
+	return stmt, nil
+}
+
+func (o *optimizer) VisitTestStmt(stmt *generated.TestStmt) (interface{}, error) {
+	stmt.Body = o.stmts(stmt.Body)
+	return stmt, nil
+}
+
+func (o *optimizer) VisitAssign(expr *generated.Assign) (interface{}, error) {
+	expr.Value = o.expr(expr.Value)
+	return expr, nil
+}
+
+func (o *optimizer) VisitLogical(expr *generated.Logical) (interface{}, error) {
+	expr.Left = o.expr(expr.Left)
+	expr.Right = o.expr(expr.Right)
+
+	if left, ok := constant(expr.Left); ok {
+		decided := interpreter.Truthy(left)
+		if expr.Operator.GetType() != token.OR {
+			decided = !decided
+		}
+
+		if decided {
+			return expr.Left, nil
+		}
+
+		return expr.Right, nil
+	}
+
+	return expr, nil
+}
+
+func (o *optimizer) VisitBinary(expr *generated.Binary) (interface{}, error) {
+	expr.Left = o.expr(expr.Left)
+	expr.Right = o.expr(expr.Right)
+
+	_, leftOk := constant(expr.Left)
+	_, rightOk := constant(expr.Right)
+	if leftOk && rightOk {
+		return o.fold(expr), nil
+	}
+
+	return expr, nil
+}
+
+func (o *optimizer) VisitTernary(expr *generated.Ternary) (interface{}, error) {
+	expr.Condition = o.expr(expr.Condition)
+	expr.ValueTrue = o.expr(expr.ValueTrue)
+	expr.ValueFalse = o.expr(expr.ValueFalse)
+
+	if cond, ok := constant(expr.Condition); ok {
+		if interpreter.Truthy(cond) {
+			return expr.ValueTrue, nil
+		}
+
+		return expr.ValueFalse, nil
+	}
+
+	return expr, nil
+}
+
+func (o *optimizer) VisitGrouping(expr *generated.Grouping) (interface{}, error) {
+	expr.Expression = o.expr(expr.Expression)
+
+	if _, ok := constant(expr.Expression); ok {
+		return expr.Expression, nil
+	}
+
+	return expr, nil
+}
+
+func (o *optimizer) VisitLiteral(expr *generated.Literal) (interface{}, error) {
+	return expr, nil
+}
+
+func (o *optimizer) VisitUnary(expr *generated.Unary) (interface{}, error) {
+	expr.Right = o.expr(expr.Right)
+
+	if _, ok := constant(expr.Right); ok {
+		return o.fold(expr), nil
+	}
+
+	return expr, nil
+}
+
+func (o *optimizer) VisitCall(expr *generated.Call) (interface{}, error) {
+	expr.Callee = o.expr(expr.Callee)
+	for idx, arg := range expr.Arguments {
+		expr.Arguments[idx] = o.expr(arg)
+	}
+
+	return expr, nil
+}
+
+func (o *optimizer) VisitVarExpr(expr *generated.VarExpr) (interface{}, error) {
+	return expr, nil
+}
+
+func (o *optimizer) VisitGet(expr *generated.Get) (interface{}, error) {
+	expr.Object = o.expr(expr.Object)
+	return expr, nil
+}
+
+func (o *optimizer) VisitMatchExpr(expr *generated.MatchExpr) (interface{}, error) {
+	expr.Subject = o.expr(expr.Subject)
+	o.cases(expr.Cases)
+	return expr, nil
+}
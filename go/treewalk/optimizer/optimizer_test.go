@@ -0,0 +1,134 @@
+package optimizer
+
+import (
+	"glox/generated"
+	"glox/interpreter"
+	"glox/parser"
+	"glox/playground"
+	"glox/resolver"
+	"glox/scanner"
+	"reflect"
+	"testing"
+)
+
+// TestSemanticsUnchanged runs each program with and without the optimizer
+// and expects the same output, diagnostics and exit status.
+func TestSemanticsUnchanged(t *testing.T) {
+	tests := []struct {
+		name   string
+		source string
+	}{
+		{"arithmetic", `print -(3 * 4) + 2.5; print (1 + 2) * 3; print 10 / 4; print 7 ~/ 2;`},
+		{"bitwise", `print 1 << 3 | 1; print ~5 & 7; print 6 ^ 3;`},
+		{"strings", `print "s" + "t"; print "a" == "a";`},
+		{"mixed equality", `print 2 == 2.0; print 1 != "1"; print nil == false;`},
+		{"unary", `print !nil; print !0; print -(-1);`},
+		{"string minus number", `print "before"; print "a" - 1;`},
+		{"string plus number", `print "a" + 1;`},
+		{"integer division by zero", `print 7 ~/ 0;`},
+		{"modulo by zero", `print 7 % 0;`},
+		{"negated string", `print -"a";`},
+		{"error in dead branch", `if (false) print "a" - 1; print "ok";`},
+		{"error in live branch", `if (true) print "a" - 1;`},
+		{"and short-circuits", `fun f() { print "called"; return true; } print false and f(); print nil and f();`},
+		{"or short-circuits", `fun f() { print "called"; return true; } print true or f(); print 0 or f();`},
+		{"logical keeps operand", `print nil or "x"; print 1 and 2; print false or nil;`},
+		{"logical runs right", `fun f() { print "called"; return 1; } print true and f(); print false or f();`},
+		{"while false", `var x = 0; while (false) x = 1; print x;`},
+		{"while false with error", `while (false) print "a" - 1; print "done";`},
+		{"for false", `for (var i = 0; false; i = i + 1) print i; print "done";`},
+		{"literal ternary", `print true ? "yes" : "no"; print nil ? "yes" : "no"; print 0 ? "yes" : "no";`},
+		{"ternary with error in dead arm", `print true ? 1 : "a" - 1;`},
+		{"literal if", `if (0) print "zero is truthy"; else print "zero is falsy"; if (nil) print "nil is truthy";`},
+		{"shadowed variables", `var a = 1; { var b = a + 1; var a = b * 2; print a; } print a;`},
+		{"closures", `fun mk() { var n = 1 + 1; fun g() { n = n * 2; return n; } return g; } var g = mk(); print g(); print g();`},
+		{"match", `print match (1 + 1) { case 1 => "one"; case 2 => "two"; default => "other"; };`},
+		{"try", `try { print "a" - 1; } catch (e) { print get(e, "message"); }`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			want := playground.Eval(tt.source)
+			if want.Status == 65 {
+				t.Fatalf("does not compile: %+v", want.Diagnostics)
+			}
+
+			got := playground.Eval(tt.source, interpreter.WithOptimizer(NewOptimizer()))
+
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("optimized run differs:\n got %+v\nwant %+v", got, want)
+			}
+		})
+	}
+}
+
+// TestFolds checks that constant expressions are folded, and that those
+// that would fail are left to fail at run time.
+func TestFolds(t *testing.T) {
+	tests := []struct {
+		source string
+		want   interface{}
+		folded bool
+	}{
+		{`print 1 + 2;`, int64(3), true},
+		{`print (2 * 3.5);`, 7.0, true},
+		{`print "a" + "b";`, "ab", true},
+		{`print !nil;`, true, true},
+		{`print nil or 1;`, int64(1), true},
+		{`print true ? "y" : "n";`, "y", true},
+		{`print "a" - 1;`, nil, false},
+		{`print 1 ~/ 0;`, nil, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			stmts := optimize(t, tt.source)
+			if len(stmts) != 1 {
+				t.Fatalf("got %d statements, want 1", len(stmts))
+			}
+
+			expr := stmts[0].(*generated.PrintStmt).Expr
+			lit, ok := expr.(*generated.Literal)
+			if ok != tt.folded {
+				t.Fatalf("got %T, folded should be %v", expr, tt.folded)
+			}
+			if ok && !reflect.DeepEqual(lit.Value, tt.want) {
+				t.Errorf("folded to %#v, want %#v", lit.Value, tt.want)
+			}
+		})
+	}
+}
+
+// TestRemovesDeadCode checks that statements that can never run are left
+// out.
+func TestRemovesDeadCode(t *testing.T) {
+	stmts := optimize(t, `while (false) print 1; if (nil) print 2; if (true) print 3; else print 4;`)
+	if len(stmts) != 1 {
+		t.Fatalf("got %d statements, want 1", len(stmts))
+	}
+
+	lit, ok := stmts[0].(*generated.PrintStmt).Expr.(*generated.Literal)
+	if !ok || lit.Value != int64(3) {
+		t.Errorf("got %#v, want print 3", stmts[0])
+	}
+}
+
+func optimize(t *testing.T, source string) []generated.Stmt {
+	t.Helper()
+
+	tokens, err := scanner.NewScanner(source).ScanTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	stmts, err := parser.NewParser(tokens).Parse()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := resolver.NewResolver(interpreter.NewInterpreter()).Resolve(stmts); err != nil {
+		t.Fatal(err)
+	}
+
+	return NewOptimizer().Optimize(stmts)
+}
@@ -1,8 +1,6 @@
 package parser
 
 import (
-	"fmt"
-
 	"glox/generated"
 	"glox/lerr"
 	"glox/token"
@@ -15,6 +13,10 @@ type Parser interface {
 type parser struct {
 	tokens []token.Token
 	curr   int
+
+	// err is the first syntax error found. Not every caller of consume
+	// stops at a missing token, so Parse reports it once it is done.
+	err error
 }
 
 func NewParser(tokens []token.Token) Parser {
@@ -34,6 +36,10 @@ func (p *parser) Parse() ([]generated.Stmt, error) {
 		stmts = append(stmts, stmt)
 	}
 
+	if p.err != nil {
+		return nil, p.err
+	}
+
 	return stmts, nil
 }
 
@@ -42,7 +48,7 @@ func (p *parser) declaration() (generated.Stmt, error) {
 		vd, err := p.varDeclaration()
 		if err != nil {
 			p.synchronize()
-			return nil, nil
+			return nil, err
 		}
 
 		return vd, nil
@@ -50,15 +56,107 @@ func (p *parser) declaration() (generated.Stmt, error) {
 		fun, err := p.funDeclaration("function")
 		if err != nil {
 			p.synchronize()
-			return nil, nil
+			return nil, err
 		}
 
 		return fun, nil
+	} else if p.match(token.IMPORT) {
+		return p.importDeclaration()
+	} else if p.checkContextual("from") && p.checkNext(token.STRING) {
+		p.advance()
+		return p.fromImportDeclaration()
+	} else if p.checkContextual("test") && p.checkNext(token.STRING) {
+		p.advance()
+		return p.testDeclaration()
 	}
 
 	return p.statement()
 }
 
+// testDeclaration parses `test "name" { ... }`. test is only a keyword
+// when followed by a string, so it remains usable as a name.
+func (p *parser) testDeclaration() (generated.Stmt, error) {
+	keyword := p.previous()
+
+	name, err := p.consume(token.STRING, "Expect test name after 'test'.")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(token.LEFT_BRACE, "Expect '{' before test body.")
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.blockStmt()
+	if err != nil {
+		return nil, err
+	}
+
+	return generated.NewTestStmt(keyword, name, body), nil
+}
+
+// importDeclaration parses `import "path" as name;`.
+func (p *parser) importDeclaration() (generated.Stmt, error) {
+	keyword := p.previous()
+
+	path, err := p.consume(token.STRING, "Expect module path after 'import'.")
+	if err != nil {
+		return nil, err
+	}
+
+	if !p.checkContextual("as") {
+		return nil, p.perror(p.peek(), "Expect 'as' after module path.")
+	}
+	p.advance()
+
+	alias, err := p.consume(token.IDENTIFIER, "Expect module name after 'as'.")
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(token.SEMICOLON, "Expect ; after import.")
+	if err != nil {
+		return nil, err
+	}
+
+	return generated.NewImportStmt(keyword, path, alias, nil), nil
+}
+
+// fromImportDeclaration parses `from "path" import a, b;`.
+func (p *parser) fromImportDeclaration() (generated.Stmt, error) {
+	path, err := p.consume(token.STRING, "Expect module path after 'from'.")
+	if err != nil {
+		return nil, err
+	}
+
+	keyword, err := p.consume(token.IMPORT, "Expect 'import' after module path.")
+	if err != nil {
+		return nil, err
+	}
+
+	names := []token.Token{}
+	for {
+		name, err := p.consume(token.IDENTIFIER, "Expect name to import.")
+		if err != nil {
+			return nil, err
+		}
+
+		names = append(names, name)
+
+		if !p.match(token.COMMA) {
+			break
+		}
+	}
+
+	_, err = p.consume(token.SEMICOLON, "Expect ; after import.")
+	if err != nil {
+		return nil, err
+	}
+
+	return generated.NewImportStmt(keyword, path, nil, names), nil
+}
+
 func (p *parser) funDeclaration(kind string) (generated.Stmt, error) {
 	name, err := p.consume(token.IDENTIFIER, "Expect "+kind+" name.")
 	if err != nil {
@@ -68,11 +166,22 @@ func (p *parser) funDeclaration(kind string) (generated.Stmt, error) {
 	p.consume(token.LEFT_PAREN, "Expect '(' after function name.")
 
 	params := []token.Token{}
+	paramTypes := []token.Token{}
+	defaults := []generated.Expr{}
+	var rest token.Token
 	if !p.check(token.RIGHT_PAREN) {
 		for {
 			if len(params) >= 255 {
-				p.perror(p.peek(), "Cannot have more than 255 parameters.")
-				return nil, lerr.NewParseErr()
+				return nil, p.perror(p.peek(), "Cannot have more than 255 parameters.")
+			}
+
+			if p.match(token.ELLIPSIS) {
+				rest, err = p.consume(token.IDENTIFIER, "Expect rest parameter name after '...'.")
+				if err != nil {
+					return nil, err
+				}
+
+				break
 			}
 
 			param, err := p.consume(token.IDENTIFIER, "Expect parameter name.")
@@ -80,7 +189,24 @@ func (p *parser) funDeclaration(kind string) (generated.Stmt, error) {
 				return nil, err
 			}
 
+			paramType, err := p.typeAnnotation()
+			if err != nil {
+				return nil, err
+			}
+
+			var def generated.Expr
+			if p.match(token.EQUAL) {
+				def, err = p.expression()
+				if err != nil {
+					return nil, err
+				}
+			} else if len(defaults) > 0 && defaults[len(defaults)-1] != nil {
+				return nil, p.perror(param, "Parameter without default cannot follow one with a default.")
+			}
+
 			params = append(params, param)
+			paramTypes = append(paramTypes, paramType)
+			defaults = append(defaults, def)
 
 			if !p.match(token.COMMA) {
 				break
@@ -88,7 +214,15 @@ func (p *parser) funDeclaration(kind string) (generated.Stmt, error) {
 		}
 	}
 
-	p.consume(token.RIGHT_PAREN, "Expect ')' after parameters.")
+	_, err = p.consume(token.RIGHT_PAREN, "Expect ')' after parameters.")
+	if err != nil {
+		return nil, err
+	}
+
+	returnType, err := p.typeAnnotation()
+	if err != nil {
+		return nil, err
+	}
 
 	p.consume(token.LEFT_BRACE, "Expect '{' before "+kind+" body.")
 
@@ -97,11 +231,31 @@ func (p *parser) funDeclaration(kind string) (generated.Stmt, error) {
 		return nil, err
 	}
 
-	return generated.NewFunctionStmt(name, params, body), nil
+	return generated.NewFunctionStmt(name, params, paramTypes, defaults, rest, returnType, body), nil
+}
+
+// typeAnnotation parses an optional `: type` and returns the type name, or
+// nil if there is no annotation. Types are only used by the checker; the
+// interpreter ignores them.
+func (p *parser) typeAnnotation() (token.Token, error) {
+	if !p.match(token.COLON) {
+		return nil, nil
+	}
+
+	if p.match(token.IDENTIFIER, token.FUN, token.NIL) {
+		return p.previous(), nil
+	}
+
+	return nil, p.perror(p.peek(), "Expect type name after ':'.")
 }
 
 func (p *parser) varDeclaration() (generated.Stmt, error) {
-	name, err := p.consume(token.IDENTIFIER, "Expect variable name.\n")
+	name, err := p.consume(token.IDENTIFIER, "Expect variable name.")
+	if err != nil {
+		return nil, err
+	}
+
+	typ, err := p.typeAnnotation()
 	if err != nil {
 		return nil, err
 	}
@@ -114,9 +268,9 @@ func (p *parser) varDeclaration() (generated.Stmt, error) {
 		}
 	}
 
-	p.consume(token.SEMICOLON, "Expect ; after variable declaration.\n")
+	p.consume(token.SEMICOLON, "Expect ; after variable declaration.")
 
-	return generated.NewVarStmt(name, initializer), nil
+	return generated.NewVarStmt(name, typ, initializer), nil
 }
 
 func (p *parser) statement() (generated.Stmt, error) {
@@ -130,6 +284,18 @@ func (p *parser) statement() (generated.Stmt, error) {
 		return p.whileStmt()
 	} else if p.match(token.RETURN) {
 		return p.returnStmt()
+	} else if p.match(token.THROW) {
+		return p.throwStmt()
+	} else if p.match(token.TRY) {
+		return p.tryStmt()
+	} else if p.match(token.MATCH) {
+		keyword := p.previous()
+		subject, cases, err := p.matchBody(false)
+		if err != nil {
+			return nil, err
+		}
+
+		return generated.NewMatchStmt(keyword, subject, cases), nil
 	} else if p.match(token.LEFT_BRACE) {
 		block, err := p.blockStmt()
 		if err != nil {
@@ -158,6 +324,219 @@ func (p *parser) returnStmt() (generated.Stmt, error) {
 	return generated.NewReturnStmt(keyword, value), nil
 }
 
+func (p *parser) throwStmt() (generated.Stmt, error) {
+	keyword := p.previous()
+
+	value, err := p.expression()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.consume(token.SEMICOLON, "Expect ; after thrown value.")
+	if err != nil {
+		return nil, err
+	}
+
+	return generated.NewThrowStmt(keyword, value), nil
+}
+
+func (p *parser) tryStmt() (generated.Stmt, error) {
+	keyword := p.previous()
+
+	_, err := p.consume(token.LEFT_BRACE, "Expect '{' after 'try'.")
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := p.blockStmt()
+	if err != nil {
+		return nil, err
+	}
+
+	var catchName token.Token
+	var catchBody []generated.Stmt
+	if p.match(token.CATCH) {
+		_, err = p.consume(token.LEFT_PAREN, "Expect '(' after 'catch'.")
+		if err != nil {
+			return nil, err
+		}
+
+		catchName, err = p.consume(token.IDENTIFIER, "Expect exception variable name.")
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.consume(token.RIGHT_PAREN, "Expect ')' after exception variable.")
+		if err != nil {
+			return nil, err
+		}
+
+		_, err = p.consume(token.LEFT_BRACE, "Expect '{' before catch body.")
+		if err != nil {
+			return nil, err
+		}
+
+		catchBody, err = p.blockStmt()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var finallyBody []generated.Stmt
+	if p.match(token.FINALLY) {
+		_, err = p.consume(token.LEFT_BRACE, "Expect '{' after 'finally'.")
+		if err != nil {
+			return nil, err
+		}
+
+		finallyBody, err = p.blockStmt()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if catchName == nil && finallyBody == nil {
+		return nil, p.perror(p.peek(), "Expect 'catch' or 'finally' after try block.")
+	}
+
+	return generated.NewTryStmt(keyword, body, catchName, catchBody, finallyBody), nil
+}
+
+// matchBody parses the subject and arms of a match, after the 'match'
+// keyword. Arms of a match statement end in a statement, while arms of a
+// match expression end in an expression followed by ';'.
+func (p *parser) matchBody(isExpr bool) (generated.Expr, []*generated.MatchCase, error) {
+	_, err := p.consume(token.LEFT_PAREN, "Expect '(' after 'match'.")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	subject, err := p.expression()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = p.consume(token.RIGHT_PAREN, "Expect ')' after match value.")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	_, err = p.consume(token.LEFT_BRACE, "Expect '{' before match cases.")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	cases := []*generated.MatchCase{}
+	for !p.check(token.RIGHT_BRACE) && !p.isAtEnd() {
+		mc, err := p.matchCase()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		_, err = p.consume(token.ARROW, "Expect '=>' after match pattern.")
+		if err != nil {
+			return nil, nil, err
+		}
+
+		if isExpr {
+			mc.Value, err = p.expression()
+			if err != nil {
+				return nil, nil, err
+			}
+
+			_, err = p.consume(token.SEMICOLON, "Expect ; after match case value.")
+		} else {
+			mc.Body, err = p.statement()
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+
+		cases = append(cases, mc)
+	}
+
+	_, err = p.consume(token.RIGHT_BRACE, "Expect '}' after match cases.")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return subject, cases, nil
+}
+
+// matchCase parses the head of an arm: `default`, `case name [if guard]`
+// or `case literal, ... [if guard]`.
+func (p *parser) matchCase() (*generated.MatchCase, error) {
+	if p.match(token.DEFAULT) {
+		return &generated.MatchCase{Keyword: p.previous()}, nil
+	}
+
+	keyword, err := p.consume(token.CASE, "Expect 'case' or 'default'.")
+	if err != nil {
+		return nil, err
+	}
+
+	mc := &generated.MatchCase{Keyword: keyword}
+	if p.match(token.IDENTIFIER) {
+		mc.Binding = p.previous()
+	} else {
+		for {
+			pattern, err := p.literalPattern()
+			if err != nil {
+				return nil, err
+			}
+
+			mc.Patterns = append(mc.Patterns, pattern)
+
+			if !p.match(token.COMMA) {
+				break
+			}
+		}
+	}
+
+	if p.match(token.IF) {
+		mc.Guard, err = p.expression()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return mc, nil
+}
+
+func (p *parser) literalPattern() (generated.Expr, error) {
+	if p.match(token.MINUS) {
+		num, err := p.consume(token.NUMBER, "Expect number after '-' in pattern.")
+		if err != nil {
+			return nil, err
+		}
+
+		switch n := num.GetLiteral().(type) {
+		case int64:
+			return generated.NewLiteral(-n), nil
+		default:
+			return generated.NewLiteral(-n.(float64)), nil
+		}
+	}
+
+	if p.match(token.FALSE) {
+		return generated.NewLiteral(false), nil
+	}
+
+	if p.match(token.TRUE) {
+		return generated.NewLiteral(true), nil
+	}
+
+	if p.match(token.NIL) {
+		return generated.NewLiteral(nil), nil
+	}
+
+	if p.match(token.NUMBER, token.STRING) {
+		return generated.NewLiteral(p.previous().GetLiteral()), nil
+	}
+
+	return nil, p.perror(p.peek(), "Expect literal or name in match pattern.")
+}
+
 func (p *parser) blockStmt() ([]generated.Stmt, error) {
 	stmts := []generated.Stmt{}
 
@@ -175,6 +554,7 @@ func (p *parser) blockStmt() ([]generated.Stmt, error) {
 }
 
 func (p *parser) ifStmt() (generated.Stmt, error) {
+	keyword := p.previous()
 	p.consume(token.LEFT_PAREN, "Expect '(' after if.")
 
 	condition, err := p.expression()
@@ -197,10 +577,11 @@ func (p *parser) ifStmt() (generated.Stmt, error) {
 		}
 	}
 
-	return generated.NewIfStmt(condition, thenBranch, elseBranch), nil
+	return generated.NewIfStmt(keyword, condition, thenBranch, elseBranch), nil
 }
 
 func (p *parser) whileStmt() (generated.Stmt, error) {
+	keyword := p.previous()
 	p.consume(token.LEFT_PAREN, "Expect '(' after 'while'.")
 	condition, err := p.expression()
 	if err != nil {
@@ -214,10 +595,11 @@ func (p *parser) whileStmt() (generated.Stmt, error) {
 		return nil, err
 	}
 
-	return generated.NewWhileStmt(condition, body), nil
+	return generated.NewWhileStmt(keyword, condition, body), nil
 }
 
 func (p *parser) forStmt() (generated.Stmt, error) {
+	keyword := p.previous()
 	p.consume(token.LEFT_PAREN, "Expect '(' after 'for'.")
 
 	var initializer generated.Stmt
@@ -269,7 +651,7 @@ func (p *parser) forStmt() (generated.Stmt, error) {
 		condition = generated.NewLiteral(true)
 	}
 
-	body = generated.NewWhileStmt(condition, body)
+	body = generated.NewWhileStmt(keyword, condition, body)
 
 	if initializer != nil {
 		body = generated.NewBlockStmt([]generated.Stmt{initializer, body})
@@ -279,6 +661,8 @@ func (p *parser) forStmt() (generated.Stmt, error) {
 }
 
 func (p *parser) printStmt() (generated.Stmt, error) {
+	keyword := p.previous()
+
 	expr, err := p.expression()
 	if err != nil {
 		return nil, err
@@ -289,7 +673,7 @@ func (p *parser) printStmt() (generated.Stmt, error) {
 		return nil, err
 	}
 
-	return generated.NewPrintStmt(expr), nil
+	return generated.NewPrintStmt(keyword, expr), nil
 }
 
 func (p *parser) expressionStmt() (generated.Stmt, error) {
@@ -341,6 +725,7 @@ func (p *parser) ternary() (generated.Expr, error) {
 	}
 
 	for p.match(token.QUESTION) {
+		question := p.previous()
 		exprTrue, err := p.expression()
 		if err != nil {
 			return nil, err
@@ -356,7 +741,7 @@ func (p *parser) ternary() (generated.Expr, error) {
 			return nil, err
 		}
 
-		expr = generated.NewTernary(expr, exprTrue, exprFalse)
+		expr = generated.NewTernary(question, expr, exprTrue, exprFalse)
 	}
 
 	return expr, nil
@@ -420,14 +805,53 @@ func (p *parser) equality() (generated.Expr, error) {
 }
 
 func (p *parser) comparsion() (generated.Expr, error) {
-	expr, err := p.terminal()
+	expr, err := p.bitOr()
 	if err != nil {
 		return nil, err
 	}
 
 	for p.match(token.GREATER, token.GREATER_EQUAL, token.LESS, token.LESS_EQUAL) {
 		operator := p.previous()
-		right, err := p.terminal()
+		right, err := p.bitOr()
+		if err != nil {
+			return nil, err
+		}
+		expr = generated.NewBinary(expr, operator, right)
+	}
+
+	return expr, nil
+}
+
+// The bitwise operators bind tighter than comparisons, unlike in C, so that
+// `x & 1 == 0` means `(x & 1) == 0`.
+
+func (p *parser) bitOr() (generated.Expr, error) {
+	return p.binaryLevel(p.bitXor, token.PIPE)
+}
+
+func (p *parser) bitXor() (generated.Expr, error) {
+	return p.binaryLevel(p.bitAnd, token.CARET)
+}
+
+func (p *parser) bitAnd() (generated.Expr, error) {
+	return p.binaryLevel(p.shift, token.AMPERSAND)
+}
+
+func (p *parser) shift() (generated.Expr, error) {
+	return p.binaryLevel(p.terminal, token.LESS_LESS, token.GREATER_GREATER)
+}
+
+// binaryLevel parses a left-associative chain of operands separated by the
+// given operators.
+func (p *parser) binaryLevel(operand func() (generated.Expr, error), operators ...token.TokenType) (generated.Expr, error) {
+	expr, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for p.match(operators...) {
+		operator := p.previous()
+		right, err := operand()
 		if err != nil {
 			return nil, err
 		}
@@ -461,7 +885,7 @@ func (p *parser) factor() (generated.Expr, error) {
 		return nil, err
 	}
 
-	for p.match(token.SLASH, token.STAR) {
+	for p.match(token.SLASH, token.STAR, token.PERCENT, token.TILDE_SLASH) {
 		operator := p.previous()
 		right, err := p.unary()
 		if err != nil {
@@ -474,7 +898,7 @@ func (p *parser) factor() (generated.Expr, error) {
 }
 
 func (p *parser) unary() (generated.Expr, error) {
-	if p.match(token.BANG, token.MINUS) {
+	if p.match(token.BANG, token.MINUS, token.TILDE) {
 		operator := p.previous()
 		right, err := p.unary()
 		if err != nil {
@@ -500,6 +924,13 @@ func (p *parser) call() (generated.Expr, error) {
 				return nil, err
 			}
 
+		} else if p.match(token.DOT) {
+			name, err := p.consume(token.IDENTIFIER, "Expect property name after '.'.")
+			if err != nil {
+				return nil, err
+			}
+
+			expr = generated.NewGet(expr, name)
 		} else {
 			break
 		}
@@ -508,14 +939,24 @@ func (p *parser) call() (generated.Expr, error) {
 	return expr, nil
 }
 
+// finishCall parses the arguments of a call. Arguments written as
+// `name: value` are named; they must follow all positional arguments.
 func (p *parser) finishCall(callee generated.Expr) (generated.Expr, error) {
 	args := []generated.Expr{}
+	names := []token.Token{}
 
 	if !p.check(token.RIGHT_PAREN) {
 		for {
 			if len(args) >= 255 {
-				p.perror(p.peek(), "Cannot have more than 255 arguments.")
-				return nil, lerr.NewParseErr()
+				return nil, p.perror(p.peek(), "Cannot have more than 255 arguments.")
+			}
+
+			var name token.Token
+			if p.check(token.IDENTIFIER) && p.checkNext(token.COLON) {
+				name = p.advance()
+				p.advance()
+			} else if len(names) > 0 && names[len(names)-1] != nil {
+				return nil, p.perror(p.peek(), "Positional argument cannot follow a named argument.")
 			}
 
 			arg, err := p.expression()
@@ -524,6 +965,7 @@ func (p *parser) finishCall(callee generated.Expr) (generated.Expr, error) {
 			}
 
 			args = append(args, arg)
+			names = append(names, name)
 
 			if !p.match(token.COMMA) {
 				break
@@ -533,7 +975,7 @@ func (p *parser) finishCall(callee generated.Expr) (generated.Expr, error) {
 
 	p.consume(token.RIGHT_PAREN, "Expect ')' after arguments.")
 
-	return generated.NewCall(callee, p.previous(), args), nil
+	return generated.NewCall(callee, p.previous(), args, names), nil
 }
 
 func (p *parser) primary() (generated.Expr, error) {
@@ -557,6 +999,16 @@ func (p *parser) primary() (generated.Expr, error) {
 		return generated.NewVarExpr(p.previous()), nil
 	}
 
+	if p.match(token.MATCH) {
+		keyword := p.previous()
+		subject, cases, err := p.matchBody(true)
+		if err != nil {
+			return nil, err
+		}
+
+		return generated.NewMatchExpr(keyword, subject, cases), nil
+	}
+
 	if p.match(token.LEFT_PAREN) {
 		expr, err := p.expression()
 		if err != nil {
@@ -578,19 +1030,19 @@ func (p *parser) consume(t token.TokenType, msg string) (token.Token, error) {
 		return p.advance(), nil
 	}
 
-	serr := p.perror(p.peek(), msg)
-	if serr != nil {
-		fmt.Print(serr.Error())
+	err := p.perror(p.peek(), msg)
+	if p.err == nil {
+		p.err = err
 	}
 
-	return nil, lerr.NewParseErr()
+	return nil, err
 }
 
 func (p *parser) perror(t token.Token, msg string) error {
 	if t.GetType() == token.EOF {
-		return lerr.NewSyntaxErr(p.peek().GetLine(), " at end", msg)
+		return lerr.NewSyntaxErrAt(lerr.SpanOf(t), " at end", msg)
 	} else {
-		return lerr.NewSyntaxErr(p.peek().GetLine(), " at end", msg)
+		return lerr.NewSyntaxErrAt(lerr.SpanOf(t), " at '"+t.GetLexeme()+"'", msg)
 	}
 }
 
@@ -613,6 +1065,20 @@ func (p *parser) check(t token.TokenType) bool {
 	return p.peek().GetType() == t
 }
 
+// checkContextual reports whether the next token is the identifier word,
+// for keywords such as 'as' that are only reserved in one position.
+func (p *parser) checkContextual(word string) bool {
+	return p.check(token.IDENTIFIER) && p.peek().GetLexeme() == word
+}
+
+func (p *parser) checkNext(t token.TokenType) bool {
+	if p.isAtEnd() || p.tokens[p.curr+1].GetType() == token.EOF {
+		return false
+	}
+
+	return p.tokens[p.curr+1].GetType() == t
+}
+
 func (p *parser) advance() token.Token {
 	if !p.isAtEnd() {
 		p.curr++
@@ -642,7 +1108,7 @@ func (p *parser) synchronize() {
 		}
 
 		switch p.peek().GetType() {
-		case token.CLASS, token.FUN, token.VAR, token.FOR, token.IF, token.WHILE, token.PRINT, token.RETURN:
+		case token.CLASS, token.FUN, token.VAR, token.FOR, token.IF, token.WHILE, token.PRINT, token.RETURN, token.IMPORT, token.THROW, token.TRY, token.MATCH:
 			return
 		}
 
@@ -0,0 +1,67 @@
+package parser
+
+import (
+	"glox/lerr"
+	"glox/scanner"
+	"strings"
+	"testing"
+)
+
+// TestErrorLocation checks that syntax errors name the token they were
+// found at, and that the first one is reported.
+func TestErrorLocation(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{`var a = ;`, "at ';': Expect expression."},
+		{`print 1`, "at end: Expect ; after value."},
+		{`var = 1; var b = ;`, "at '=': Expect variable name."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			tokens, err := scanner.NewScanner(tt.source).ScanTokens()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = NewParser(tokens).Parse()
+			if err == nil || !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("got %v, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+// TestDiagnosticMessage checks that syntax errors carry bare messages,
+// which editors show as they are.
+func TestDiagnosticMessage(t *testing.T) {
+	tests := []struct {
+		source string
+		want   string
+	}{
+		{`var a = ;`, "Expect expression."},
+		{`var = 1;`, "Expect variable name."},
+		{`var a = 1`, "Expect ; after variable declaration."},
+		{`print 1`, "Expect ; after value."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.source, func(t *testing.T) {
+			tokens, err := scanner.NewScanner(tt.source).ScanTokens()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			_, err = NewParser(tokens).Parse()
+			if err == nil {
+				t.Fatal("no error")
+			}
+
+			if got := lerr.Diagnose(err).Message; got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
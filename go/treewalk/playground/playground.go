@@ -0,0 +1,60 @@
+// Package playground runs scripts in memory, for hosts that embed the
+// interpreter rather than run it as a command, like the WebAssembly builds.
+package playground
+
+import (
+	"bytes"
+	"glox/interpreter"
+	"glox/lerr"
+	"glox/parser"
+	"glox/resolver"
+	"glox/scanner"
+	"strings"
+)
+
+// Result is the outcome of Eval. Status is the exit status glox would
+// have exited with: 65 for a script that does not compile, 70 for one that
+// failed at run time and 0 otherwise.
+type Result struct {
+	Stdout      string            `json:"stdout"`
+	Diagnostics []lerr.Diagnostic `json:"diagnostics"`
+	Status      int               `json:"status"`
+}
+
+// Eval runs source as a script, capturing what it prints. Scripts read an
+// empty stdin unless opts give them one.
+func Eval(source string, opts ...interpreter.Option) Result {
+	var stdout bytes.Buffer
+	opts = append([]interpreter.Option{interpreter.WithStdin(strings.NewReader(""))}, opts...)
+	in := interpreter.NewInterpreter(append(opts, interpreter.WithStdout(&stdout))...)
+
+	result := Result{Diagnostics: []lerr.Diagnostic{}}
+	fail := func(err error, status int) Result {
+		result.Stdout = stdout.String()
+		result.Diagnostics = append(result.Diagnostics, lerr.Diagnose(err))
+		result.Status = status
+		return result
+	}
+
+	tokens, err := scanner.NewScanner(source).ScanTokens()
+	if err != nil {
+		return fail(err, 65)
+	}
+
+	stmts, err := parser.NewParser(tokens).Parse()
+	if err != nil {
+		return fail(err, 65)
+	}
+
+	if err := resolver.NewResolver(in).Resolve(stmts); err != nil {
+		return fail(err, 65)
+	}
+
+	if err := in.Run(stmts); err != nil {
+		return fail(err, 70)
+	}
+
+	result.Stdout = stdout.String()
+
+	return result
+}
@@ -0,0 +1,187 @@
+package profiler
+
+import (
+	"compress/gzip"
+	"io"
+	"sort"
+)
+
+// Field numbers from profile.proto in github.com/google/pprof.
+const (
+	profileSampleType    = 1
+	profileSample        = 2
+	profileLocation      = 4
+	profileFunction      = 5
+	profileStringTable   = 6
+	profileTimeNanos     = 9
+	profileDurationNanos = 10
+
+	valueTypeType = 1
+	valueTypeUnit = 2
+
+	sampleLocationID = 1
+	sampleValue      = 2
+
+	locationID   = 1
+	locationLine = 4
+
+	lineFunctionID = 1
+	lineLine       = 2
+
+	functionID        = 1
+	functionName      = 2
+	functionFilename  = 4
+	functionStartLine = 5
+)
+
+// WritePprof writes the profile in the gzipped protocol buffer format read
+// by go tool pprof. Every sample holds the number of calls and the time
+// spent on one call stack, and every function has a single location at
+// its declaration.
+func (p *Profiler) WritePprof(w io.Writer) error {
+	p.Stop()
+
+	var strs []string
+	index := map[string]int64{}
+	str := func(s string) int64 {
+		if idx, ok := index[s]; ok {
+			return idx
+		}
+		index[s] = int64(len(strs))
+		strs = append(strs, s)
+		return index[s]
+	}
+	str("")
+
+	var b protoBuf
+	for _, vt := range [][2]string{{"calls", "count"}, {"time", "nanoseconds"}} {
+		var m protoBuf
+		m.int(valueTypeType, str(vt[0]))
+		m.int(valueTypeUnit, str(vt[1]))
+		b.message(profileSampleType, m)
+	}
+
+	samples := make([]*sample, 0, len(p.samples))
+	for _, s := range p.samples {
+		samples = append(samples, s)
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return less(samples[i].stack, samples[j].stack)
+	})
+
+	for _, s := range samples {
+		// pprof lists the leaf first.
+		ids := make([]uint64, 0, len(s.stack))
+		for idx := len(s.stack) - 1; idx >= 0; idx-- {
+			ids = append(ids, s.stack[idx].id)
+		}
+
+		var m protoBuf
+		m.packed(sampleLocationID, ids)
+		m.packed(sampleValue, []uint64{uint64(s.calls), uint64(s.time)})
+		b.message(profileSample, m)
+	}
+
+	fns := p.functions()
+	sort.Slice(fns, func(i, j int) bool {
+		return fns[i].id < fns[j].id
+	})
+
+	for _, f := range fns {
+		var l protoBuf
+		l.uint(lineFunctionID, f.id)
+		l.int(lineLine, int64(f.Line))
+
+		var m protoBuf
+		m.uint(locationID, f.id)
+		m.message(locationLine, l)
+		b.message(profileLocation, m)
+	}
+
+	for _, f := range fns {
+		var m protoBuf
+		m.uint(functionID, f.id)
+		m.int(functionName, str(f.Name))
+		m.int(functionFilename, str(f.Path))
+		m.int(functionStartLine, int64(f.Line))
+		b.message(profileFunction, m)
+	}
+
+	for _, s := range strs {
+		b.bytes(profileStringTable, []byte(s))
+	}
+
+	b.int(profileTimeNanos, p.start.UnixNano())
+	b.int(profileDurationNanos, int64(p.end.Sub(p.start)))
+
+	gz := gzip.NewWriter(w)
+	if _, err := gz.Write(b); err != nil {
+		return err
+	}
+
+	return gz.Close()
+}
+
+// less orders call stacks by the IDs of their functions, so that the
+// output does not depend on map iteration order.
+func less(a, b []*function) bool {
+	for idx := 0; idx < len(a) && idx < len(b); idx++ {
+		if a[idx].id != b[idx].id {
+			return a[idx].id < b[idx].id
+		}
+	}
+
+	return len(a) < len(b)
+}
+
+// protoBuf encodes the few protocol buffer wire types the profile needs.
+type protoBuf []byte
+
+const (
+	wireVarint = 0
+	wireBytes  = 2
+)
+
+func (b *protoBuf) varint(v uint64) {
+	for v >= 0x80 {
+		*b = append(*b, byte(v)|0x80)
+		v >>= 7
+	}
+	*b = append(*b, byte(v))
+}
+
+func (b *protoBuf) key(field int, wire int) {
+	b.varint(uint64(field)<<3 | uint64(wire))
+}
+
+// uint writes a varint field, leaving out zero values as proto3 does.
+func (b *protoBuf) uint(field int, v uint64) {
+	if v == 0 {
+		return
+	}
+
+	b.key(field, wireVarint)
+	b.varint(v)
+}
+
+func (b *protoBuf) int(field int, v int64) {
+	b.uint(field, uint64(v))
+}
+
+func (b *protoBuf) bytes(field int, v []byte) {
+	b.key(field, wireBytes)
+	b.varint(uint64(len(v)))
+	*b = append(*b, v...)
+}
+
+func (b *protoBuf) message(field int, m protoBuf) {
+	b.bytes(field, m)
+}
+
+func (b *protoBuf) packed(field int, vs []uint64) {
+	var m protoBuf
+	for _, v := range vs {
+		m.varint(v)
+	}
+	b.bytes(field, m)
+}
@@ -0,0 +1,241 @@
+package profiler
+
+import (
+	"fmt"
+	"glox/interpreter"
+	"io"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+// function is the profile of one Lox function. Top-level code is profiled
+// as a function named "script".
+type function struct {
+	interpreter.Function
+	id uint64
+
+	calls int64
+
+	// exclusive is the time spent in the function itself and inclusive
+	// also counts the functions it called. Recursive calls are only
+	// counted once towards inclusive.
+	exclusive time.Duration
+	inclusive time.Duration
+	active    int
+}
+
+type frame struct {
+	fn    *function
+	start time.Time
+}
+
+// sample is the cost attributed to one call stack.
+type sample struct {
+	stack []*function
+	calls int64
+	time  time.Duration
+}
+
+type line struct {
+	path string
+	line int
+}
+
+// Profiler records how often each function is called and each line runs,
+// and where the time goes. It implements interpreter.Profiler.
+//
+// The profile is exact rather than sampled: on every call and return the
+// time since the previous one is charged to the function on top of the
+// stack.
+type Profiler struct {
+	now func() time.Time
+
+	start time.Time
+	last  time.Time
+	end   time.Time
+
+	funcs   map[interpreter.Function]*function
+	stack   []frame
+	samples map[string]*sample
+	lines   map[line]int64
+}
+
+func NewProfiler() *Profiler {
+	p := &Profiler{
+		now:     time.Now,
+		funcs:   map[interpreter.Function]*function{},
+		samples: map[string]*sample{},
+		lines:   map[line]int64{},
+	}
+
+	p.start = p.now()
+	p.last = p.start
+	p.push(interpreter.Function{Name: "script"}, p.start)
+
+	return p
+}
+
+func (p *Profiler) Enter(f interpreter.Function) {
+	now := p.tick()
+	p.push(f, now)
+}
+
+func (p *Profiler) Exit() {
+	// The script itself is never exited.
+	if len(p.stack) < 2 {
+		return
+	}
+
+	now := p.tick()
+
+	top := p.stack[len(p.stack)-1]
+	p.stack = p.stack[:len(p.stack)-1]
+
+	top.fn.active--
+	if top.fn.active == 0 {
+		top.fn.inclusive += now.Sub(top.start)
+	}
+}
+
+func (p *Profiler) Line(path string, n int) {
+	p.lines[line{path: path, line: n}]++
+}
+
+// Stop ends the profile, charging the time since the last call or return
+// to the functions still running.
+func (p *Profiler) Stop() {
+	if !p.end.IsZero() {
+		return
+	}
+
+	p.end = p.tick()
+	for _, f := range p.stack {
+		if f.fn.active > 0 {
+			f.fn.inclusive += p.end.Sub(f.start)
+			f.fn.active = 0
+		}
+	}
+}
+
+func (p *Profiler) push(f interpreter.Function, now time.Time) {
+	fn, ok := p.funcs[f]
+	if !ok {
+		fn = &function{Function: f, id: uint64(len(p.funcs) + 1)}
+		p.funcs[f] = fn
+	}
+
+	fn.calls++
+	fn.active++
+	p.stack = append(p.stack, frame{fn: fn, start: now})
+	p.current().calls++
+}
+
+// tick charges the time since the last event to the function on top of
+// the stack.
+func (p *Profiler) tick() time.Time {
+	now := p.now()
+	elapsed := now.Sub(p.last)
+	p.last = now
+
+	p.stack[len(p.stack)-1].fn.exclusive += elapsed
+	p.current().time += elapsed
+
+	return now
+}
+
+// current returns the sample for the current call stack.
+func (p *Profiler) current() *sample {
+	var key strings.Builder
+	for _, f := range p.stack {
+		fmt.Fprintf(&key, "%d;", f.fn.id)
+	}
+
+	s, ok := p.samples[key.String()]
+	if !ok {
+		s = &sample{stack: make([]*function, 0, len(p.stack))}
+		for _, f := range p.stack {
+			s.stack = append(s.stack, f.fn)
+		}
+		p.samples[key.String()] = s
+	}
+
+	return s
+}
+
+// functions returns the profiled functions, most expensive first.
+func (p *Profiler) functions() []*function {
+	fns := make([]*function, 0, len(p.funcs))
+	for _, f := range p.funcs {
+		fns = append(fns, f)
+	}
+
+	sort.Slice(fns, func(i, j int) bool {
+		if fns[i].exclusive != fns[j].exclusive {
+			return fns[i].exclusive > fns[j].exclusive
+		}
+		return fns[i].id < fns[j].id
+	})
+
+	return fns
+}
+
+// Report writes a flat profile: the functions by the time spent in them,
+// followed by the lines by how many statements ran on them.
+func (p *Profiler) Report(w io.Writer) {
+	p.Stop()
+	total := p.end.Sub(p.start)
+
+	fmt.Fprintf(w, "Total time: %v\n\n", total)
+	fmt.Fprintf(w, "%10s %12s %7s %12s %7s  %s\n", "calls", "flat", "flat%", "cum", "cum%", "function")
+	for _, f := range p.functions() {
+		fmt.Fprintf(w, "%10d %12v %6.2f%% %12v %6.2f%%  %s\n",
+			f.calls, f.exclusive, percent(f.exclusive, total), f.inclusive, percent(f.inclusive, total), describe(f.Function))
+	}
+
+	lines := make([]line, 0, len(p.lines))
+	for l := range p.lines {
+		lines = append(lines, l)
+	}
+
+	sort.Slice(lines, func(i, j int) bool {
+		a, b := lines[i], lines[j]
+		if p.lines[a] != p.lines[b] {
+			return p.lines[a] > p.lines[b]
+		}
+		if a.path != b.path {
+			return a.path < b.path
+		}
+		return a.line < b.line
+	})
+
+	fmt.Fprintf(w, "\n%10s  %s\n", "hits", "line")
+	for _, l := range lines {
+		fmt.Fprintf(w, "%10d  %s\n", p.lines[l], location(l.path, l.line))
+	}
+}
+
+func percent(d, total time.Duration) float64 {
+	if total == 0 {
+		return 0
+	}
+
+	return 100 * float64(d) / float64(total)
+}
+
+func describe(f interpreter.Function) string {
+	if f.Line == 0 {
+		return f.Name
+	}
+
+	return f.Name + " (" + location(f.Path, f.Line) + ")"
+}
+
+func location(path string, line int) string {
+	if path == "" {
+		return fmt.Sprintf("line %d", line)
+	}
+
+	return fmt.Sprintf("%s:%d", filepath.Base(path), line)
+}
@@ -0,0 +1,56 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"glox/generated"
+	"glox/interpreter"
+	"glox/lerr"
+	"os"
+)
+
+// reporter reports the errors that stop scripts: as text on stdout, or
+// with --diagnostics=json as one JSON lerr.Diagnostic per line on stderr,
+// apart from what scripts print, for editors and CI annotators.
+type reporter struct {
+	json bool
+	// file is the script errors are attributed to, or empty for the
+	// prompt.
+	file string
+}
+
+// report reports err, which kept a script from running. newline ends
+// text reports with a newline, which the messages of syntax errors lack.
+func (r reporter) report(err error, newline bool) {
+	if !r.json {
+		fmt.Print(err)
+		if newline {
+			fmt.Println()
+		}
+		return
+	}
+
+	d := lerr.Diagnose(err)
+	if d.File == "" {
+		d.File = r.file
+	}
+
+	enc := json.NewEncoder(os.Stderr)
+	enc.SetEscapeHTML(false)
+	enc.Encode(d)
+}
+
+// interpret runs stmts, reporting the runtime error that stopped them,
+// and returns the exit status of the script.
+func (r reporter) interpret(in interpreter.Interpreter, stmts []generated.Stmt) int {
+	if !r.json {
+		return in.Interpret(stmts)
+	}
+
+	if err := in.Run(stmts); err != nil {
+		r.report(err, false)
+		return 70
+	}
+
+	return 0
+}
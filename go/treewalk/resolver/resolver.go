@@ -2,7 +2,6 @@ package resolver
 
 import (
 	"glox/generated"
-	"glox/interpreter"
 	"glox/lerr"
 	"glox/token"
 )
@@ -14,6 +13,35 @@ const (
 	FunctionTypeFunction functionType = "function"
 )
 
+// Version is bumped whenever the resolver binds the same tree
+// differently, such as a change to the scope distances it reports or to
+// which calls are in tail position, so that saved bindings are not reused
+// across the change.
+const Version = 1
+
+// Binder records the scope distance of each resolved local variable. It is
+// implemented by the interpreter.
+type Binder interface {
+	Resolve(generated.Expr, int)
+}
+
+// Indexer is implemented by binders that also want to know where each
+// variable is declared and which declaration each use refers to, such as
+// the language server. Uses of globals that are never declared are not
+// reported.
+type Indexer interface {
+	Declare(name token.Token)
+	Reference(use token.Token, decl token.Token)
+}
+
+// TailCaller is implemented by binders that want to know which calls are
+// in tail position: calls whose value a function returns directly, outside
+// of any try statement, so that nothing is left for the function to do
+// once they return.
+type TailCaller interface {
+	TailCall(call *generated.Call)
+}
+
 type Resolver interface {
 	generated.VisitorStmt
 	generated.VisitorExpr
@@ -21,16 +49,36 @@ type Resolver interface {
 }
 
 type resolver struct {
-	interpreter  interpreter.Interpreter
+	interpreter  Binder
 	scopes       []map[string]bool
 	currFunction functionType
+
+	// tryDepth counts the try statements enclosing the current function
+	// body. Calls returned inside them are not tail calls, since the catch
+	// and finally clauses may still have to run.
+	tryDepth int
+
+	// The fields below are only maintained when the binder is an Indexer.
+	// decls parallels scopes, globals holds the top-level declarations and
+	// globalUses the uses of globals, which are only matched up once the
+	// whole program has been seen since functions may refer to globals
+	// declared after them.
+	indexer    Indexer
+	decls      []map[string]token.Token
+	globals    map[string]token.Token
+	globalUses []token.Token
 }
 
-func NewResolver(in interpreter.Interpreter) Resolver {
+func NewResolver(in Binder) Resolver {
+	indexer, _ := in.(Indexer)
+
 	return &resolver{
 		interpreter:  in,
 		scopes:       []map[string]bool{},
 		currFunction: FunctionTypeNone,
+		indexer:      indexer,
+		decls:        []map[string]token.Token{},
+		globals:      map[string]token.Token{},
 	}
 }
 
@@ -48,7 +96,17 @@ func (r *resolver) VisitBlockStmt(stmt *generated.BlockStmt) (interface{}, error
 }
 
 func (r *resolver) Resolve(stmts []generated.Stmt) error {
-	return r.resolveStmts(stmts)
+	err := r.resolveStmts(stmts)
+
+	if r.indexer != nil {
+		for _, use := range r.globalUses {
+			if decl, ok := r.globals[use.GetLexeme()]; ok {
+				r.indexer.Reference(use, decl)
+			}
+		}
+	}
+
+	return err
 }
 
 func (r *resolver) resolveStmts(stmts []generated.Stmt) error {
@@ -72,10 +130,12 @@ func (r *resolver) resolveExpr(expr generated.Expr) (interface{}, error) {
 
 func (r *resolver) beginScope() {
 	r.scopes = append(r.scopes, map[string]bool{})
+	r.decls = append(r.decls, map[string]token.Token{})
 }
 
 func (r *resolver) endScope() {
 	r.scopes = r.scopes[:len(r.scopes)-1]
+	r.decls = r.decls[:len(r.decls)-1]
 }
 
 func (r *resolver) VisitVarStmt(stmt *generated.VarStmt) (interface{}, error) {
@@ -96,15 +156,23 @@ func (r *resolver) VisitVarStmt(stmt *generated.VarStmt) (interface{}, error) {
 }
 
 func (r *resolver) declare(name token.Token) error {
+	if r.indexer != nil {
+		r.indexer.Declare(name)
+	}
+
 	if len(r.scopes) == 0 {
+		if _, ok := r.globals[name.GetLexeme()]; !ok {
+			r.globals[name.GetLexeme()] = name
+		}
 		return nil
 	}
 
 	scope := r.scopes[len(r.scopes)-1]
 	if _, ok := scope[name.GetLexeme()]; ok {
-		return lerr.NewSyntaxErr(name.GetLine(), "", "Variable with this name already declared in this scope.")
+		return lerr.NewSyntaxErrAt(lerr.SpanOf(name), "", "Variable with this name already declared in this scope.")
 	}
 	scope[name.GetLexeme()] = false
+	r.decls[len(r.decls)-1][name.GetLexeme()] = name
 
 	return nil
 }
@@ -122,7 +190,7 @@ func (r *resolver) VisitVarExpr(expr *generated.VarExpr) (interface{}, error) {
 	if len(r.scopes) != 0 {
 		scope := r.scopes[len(r.scopes)-1]
 		if _, ok := scope[expr.Name.GetLexeme()]; ok && !scope[expr.Name.GetLexeme()] {
-			return nil, lerr.NewRuntimeErr(expr.Name, "Cannot read local variable in its own initializer.")
+			return nil, lerr.NewSyntaxErrAt(lerr.SpanOf(expr.Name), "", "Cannot read local variable in its own initializer.")
 		}
 	}
 
@@ -135,9 +203,16 @@ func (r *resolver) resolveLocal(expr generated.Expr, name token.Token) {
 	for i := len(r.scopes) - 1; i >= 0; i-- {
 		if _, ok := r.scopes[i][name.GetLexeme()]; ok {
 			r.interpreter.Resolve(expr, len(r.scopes)-1-i)
+			if r.indexer != nil {
+				r.indexer.Reference(name, r.decls[i][name.GetLexeme()])
+			}
 			return
 		}
 	}
+
+	if r.indexer != nil {
+		r.globalUses = append(r.globalUses, name)
+	}
 }
 
 func (r *resolver) VisitAssign(expr *generated.Assign) (interface{}, error) {
@@ -168,26 +243,43 @@ func (r *resolver) VisitFunctionStmt(stmt *generated.FunctionStmt) (interface{},
 }
 
 func (r *resolver) resolveFunction(stmt *generated.FunctionStmt, typ functionType) error {
-	enclosingFunction := r.currFunction
-	r.currFunction = typ
+	enclosingFunction, enclosingTryDepth := r.currFunction, r.tryDepth
+	r.currFunction, r.tryDepth = typ, 0
 
 	r.beginScope()
-	for _, param := range stmt.Params {
-		err := r.declare(stmt.Name)
+	for idx, param := range stmt.Params {
+		// A default may refer to the parameters before it, which is how
+		// the interpreter binds them at call time.
+		if stmt.Defaults[idx] != nil {
+			_, err := r.resolveExpr(stmt.Defaults[idx])
+			if err != nil {
+				return err
+			}
+		}
+
+		err := r.declare(param)
 		if err != nil {
 			return err
 		}
 		r.define(param)
 	}
 
+	if stmt.Rest != nil {
+		err := r.declare(stmt.Rest)
+		if err != nil {
+			return err
+		}
+		r.define(stmt.Rest)
+	}
+
 	err := r.resolveStmts(stmt.Body)
 	if err != nil {
-		return nil
+		return err
 	}
 
 	r.endScope()
 
-	r.currFunction = enclosingFunction
+	r.currFunction, r.tryDepth = enclosingFunction, enclosingTryDepth
 
 	return nil
 }
@@ -233,7 +325,7 @@ func (r *resolver) VisitWhileStmt(stmt *generated.WhileStmt) (interface{}, error
 
 func (r *resolver) VisitReturnStmt(stmt *generated.ReturnStmt) (interface{}, error) {
 	if r.currFunction == FunctionTypeNone {
-		return nil, lerr.NewSyntaxErr(stmt.Keyword.GetLine(), "", "Cannot return from top-level code.")
+		return nil, lerr.NewSyntaxErrAt(lerr.SpanOf(stmt.Keyword), "", "Cannot return from top-level code.")
 	}
 
 	if stmt.Value != nil {
@@ -243,6 +335,12 @@ func (r *resolver) VisitReturnStmt(stmt *generated.ReturnStmt) (interface{}, err
 		}
 	}
 
+	if call, ok := stmt.Value.(*generated.Call); ok && r.tryDepth == 0 {
+		if tc, ok := r.interpreter.(TailCaller); ok {
+			tc.TailCall(call)
+		}
+	}
+
 	return nil, nil
 }
 
@@ -324,3 +422,137 @@ func (r *resolver) VisitCall(expr *generated.Call) (interface{}, error) {
 
 	return nil, nil
 }
+
+func (r *resolver) VisitGet(expr *generated.Get) (interface{}, error) {
+	return r.resolveExpr(expr.Object)
+}
+
+func (r *resolver) VisitImportStmt(stmt *generated.ImportStmt) (interface{}, error) {
+	names := stmt.Names
+	if stmt.Alias != nil {
+		names = []token.Token{stmt.Alias}
+	}
+
+	for _, name := range names {
+		err := r.declare(name)
+		if err != nil {
+			return nil, err
+		}
+
+		r.define(name)
+	}
+
+	return nil, nil
+}
+
+func (r *resolver) VisitThrowStmt(stmt *generated.ThrowStmt) (interface{}, error) {
+	return r.resolveExpr(stmt.Value)
+}
+
+func (r *resolver) VisitTryStmt(stmt *generated.TryStmt) (interface{}, error) {
+	r.tryDepth++
+	defer func() {
+		r.tryDepth--
+	}()
+
+	err := r.resolveBlock(stmt.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if stmt.CatchName != nil {
+		r.beginScope()
+		r.declare(stmt.CatchName)
+		r.define(stmt.CatchName)
+
+		err = r.resolveBlock(stmt.CatchBody)
+		if err != nil {
+			return nil, err
+		}
+
+		r.endScope()
+	}
+
+	if stmt.FinallyBody != nil {
+		err = r.resolveBlock(stmt.FinallyBody)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return nil, nil
+}
+
+func (r *resolver) VisitTestStmt(stmt *generated.TestStmt) (interface{}, error) {
+	if len(r.scopes) > 0 || r.currFunction != FunctionTypeNone {
+		return nil, lerr.NewSyntaxErrAt(lerr.SpanOf(stmt.Keyword), "", "Tests must be declared at the top level.")
+	}
+
+	return nil, r.resolveBlock(stmt.Body)
+}
+
+func (r *resolver) resolveBlock(stmts []generated.Stmt) error {
+	r.beginScope()
+
+	err := r.resolveStmts(stmts)
+	if err != nil {
+		return err
+	}
+
+	r.endScope()
+
+	return nil
+}
+
+func (r *resolver) VisitMatchStmt(stmt *generated.MatchStmt) (interface{}, error) {
+	return nil, r.resolveMatch(stmt.Subject, stmt.Cases)
+}
+
+func (r *resolver) VisitMatchExpr(expr *generated.MatchExpr) (interface{}, error) {
+	return nil, r.resolveMatch(expr.Subject, expr.Cases)
+}
+
+// resolveMatch resolves each arm in a scope of its own, in which the arm's
+// binding, if any, is visible to its guard and body.
+func (r *resolver) resolveMatch(subject generated.Expr, cases []*generated.MatchCase) error {
+	_, err := r.resolveExpr(subject)
+	if err != nil {
+		return err
+	}
+
+	for _, mc := range cases {
+		r.beginScope()
+
+		if mc.Binding != nil {
+			r.declare(mc.Binding)
+			r.define(mc.Binding)
+		}
+
+		for _, pattern := range mc.Patterns {
+			_, err := r.resolveExpr(pattern)
+			if err != nil {
+				return err
+			}
+		}
+
+		if mc.Guard != nil {
+			_, err := r.resolveExpr(mc.Guard)
+			if err != nil {
+				return err
+			}
+		}
+
+		if mc.Body != nil {
+			_, err = r.resolveStmt(mc.Body)
+		} else {
+			_, err = r.resolveExpr(mc.Value)
+		}
+		if err != nil {
+			return err
+		}
+
+		r.endScope()
+	}
+
+	return nil
+}
@@ -0,0 +1,227 @@
+package rt
+
+import (
+	"fmt"
+	"glox/interpreter"
+	"glox/lerr"
+)
+
+var _ interpreter.LoxCallable = (*Function)(nil)
+
+// Unset marks the parameters a call did not bind, which compiled
+// functions give their default or report as missing.
+var Unset Value = &struct{ unset bool }{}
+
+// Function is a compiled Lox function. Its body gets the values of its
+// parameters, followed by the list of surplus arguments if it has a rest
+// parameter, and closes over the variables of the code declaring it.
+type Function struct {
+	name     Token
+	params   []string
+	required int
+	rest     bool
+	body     func(params []Value) Value
+}
+
+// NewFunction returns a function declared as name, with the given
+// parameters, of which required have no default.
+func NewFunction(name Token, params []string, required int, rest bool, body func(params []Value) Value) *Function {
+	return &Function{name: name, params: params, required: required, rest: rest, body: body}
+}
+
+func (f *Function) Arity() (int, int) {
+	if f.rest {
+		return f.required, -1
+	}
+
+	return f.required, len(f.params)
+}
+
+// Call implements interpreter.LoxCallable, for natives calling back into
+// compiled code.
+func (f *Function) Call(_ interpreter.Interpreter, args []interface{}) (value interface{}, err error) {
+	err = run(func() { value = f.call(args, nil, nil) })
+	return value, err
+}
+
+func (f *Function) String() string {
+	return "<fn " + f.name.GetLexeme() + ">"
+}
+
+// call calls the function, then any function it tail calls, and so on,
+// until one returns a value. Tail calls made this way are not in the trace
+// of an error, except for the one the error was raised in.
+func (f *Function) call(args []Value, names []Token, named []Value) Value {
+	var paren Token
+	for {
+		value := f.run(paren, args, names, named)
+
+		next, ok := value.(*tailCall)
+		if !ok {
+			return value
+		}
+
+		f, paren = next.callee, next.paren
+		args, names, named = next.args, next.names, next.named
+	}
+}
+
+// run binds positional and named arguments to the parameters and runs the
+// body, as the interpreter does for Lox functions. paren is set when the
+// call is a tail call.
+func (f *Function) run(paren Token, args []Value, names []Token, named []Value) Value {
+	if paren != nil {
+		defer frame(f, paren)
+	}
+
+	if !f.rest && len(args) > len(f.params) {
+		Fail(f.name, fmt.Sprintf("Expected at most %d arguments but got %d.", len(f.params), len(args)))
+	}
+
+	params := make([]Value, len(f.params), len(f.params)+1)
+	for idx := range params {
+		params[idx] = Unset
+	}
+	copy(params, args)
+
+	for idx, name := range names {
+		pos := f.paramIndex(name.GetLexeme())
+		if pos < 0 {
+			Fail(name, fmt.Sprintf("%s has no parameter '%s'.", f, name.GetLexeme()))
+		}
+
+		if params[pos] != Unset {
+			Fail(name, fmt.Sprintf("Argument '%s' given more than once.", name.GetLexeme()))
+		}
+
+		params[pos] = named[idx]
+	}
+
+	if f.rest {
+		rest := []interface{}{}
+		if len(args) > len(f.params) {
+			rest = append(rest, args[len(f.params):]...)
+		}
+
+		params = append(params, interpreter.NewList(rest))
+	}
+
+	return f.body(params)
+}
+
+func (f *Function) paramIndex(name string) int {
+	for idx, param := range f.params {
+		if param == name {
+			return idx
+		}
+	}
+
+	return -1
+}
+
+// MissingArg reports that the parameter param of the function declared as
+// name was not given.
+func MissingArg(param, name Token) {
+	Fail(param, fmt.Sprintf("Missing argument '%s' to <fn %s>.", param.GetLexeme(), name.GetLexeme()))
+}
+
+// tailCall is a call to a Lox function in tail position, returned by the
+// function making it for Function.call to make.
+type tailCall struct {
+	callee *Function
+	paren  Token
+	args   []Value
+	names  []Token
+	named  []Value
+}
+
+// prepare checks a call to callee at paren as the interpreter does,
+// separating positional arguments from named ones. names holds the name of
+// each named argument, and nil for the positional ones.
+func prepare(callee Value, paren Token, names []Token, args []Value) (*tailCall, interpreter.LoxCallable) {
+	function, ok := callee.(interpreter.LoxCallable)
+	if !ok {
+		Fail(paren, "Can only call functions and classes.")
+	}
+
+	c := &tailCall{paren: paren}
+	if names == nil {
+		c.args = args
+	} else {
+		for idx, arg := range args {
+			if names[idx] == nil {
+				c.args = append(c.args, arg)
+				continue
+			}
+
+			c.names = append(c.names, names[idx])
+			c.named = append(c.named, arg)
+		}
+	}
+
+	c.callee, ok = function.(*Function)
+	switch {
+	case c.names == nil:
+		if err := interpreter.CheckArity(function, paren, len(c.args)); err != nil {
+			panic(err)
+		}
+	case !ok:
+		Fail(paren, fmt.Sprintf("%s does not take named arguments.", function))
+	}
+
+	return c, function
+}
+
+// Call calls callee with positional arguments, at paren.
+func Call(callee Value, paren Token, args ...Value) Value {
+	return CallNamed(callee, paren, nil, args...)
+}
+
+// CallNamed calls callee at paren. names holds the name of each named
+// argument, and nil for the positional ones.
+func CallNamed(callee Value, paren Token, names []Token, args ...Value) Value {
+	c, function := prepare(callee, paren, names, args)
+	if c.callee == nil {
+		value, err := function.Call(in, c.args)
+		if err != nil {
+			interpreter.AddFrame(err, function, paren)
+			panic(err)
+		}
+
+		return value
+	}
+
+	defer frame(c.callee, paren)
+
+	return c.callee.call(c.args, c.names, c.named)
+}
+
+// TailCall returns a call in tail position from a compiled function. A
+// call to a Lox function is made by Function.call once the function has
+// returned; any other is made at once.
+func TailCall(callee Value, paren Token, args ...Value) Value {
+	return TailCallNamed(callee, paren, nil, args...)
+}
+
+// TailCallNamed is TailCall with named arguments, as for CallNamed.
+func TailCallNamed(callee Value, paren Token, names []Token, args ...Value) Value {
+	c, _ := prepare(callee, paren, names, args)
+	if c.callee == nil {
+		return CallNamed(callee, paren, names, args...)
+	}
+
+	return c
+}
+
+// frame adds a call to the trace of the error being raised through it.
+func frame(callee interpreter.LoxCallable, paren Token) {
+	if r := recover(); r != nil {
+		if err, ok := r.(*lerr.RuntimeErr); ok {
+			interpreter.AddFrame(err, callee, paren)
+		} else if t, ok := r.(*interpreter.Throw); ok {
+			interpreter.AddFrame(t, callee, paren)
+		}
+
+		panic(r)
+	}
+}
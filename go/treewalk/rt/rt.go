@@ -0,0 +1,273 @@
+// Package rt is the runtime of Lox programs compiled to Go by glox build.
+//
+// Compiled code keeps Lox values as they are in the interpreter, and
+// leaves operators, natives and errors to the interpreter's own rules, so
+// that a compiled program behaves as the script would when run by glox.
+// Local variables become Go variables, captured by Go closures the way
+// Lox closures capture their environment; globals stay in environments,
+// looked up by name when used.
+//
+// Runtime errors are raised as panics carrying the error the interpreter
+// would have returned. They unwind to the nearest Try, or to Main, which
+// reports them and exits like the interpreter does.
+package rt
+
+import (
+	"fmt"
+	"glox/environment"
+	"glox/interpreter"
+	"glox/lerr"
+	"glox/token"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+)
+
+// Value is a Lox value.
+type Value = interface{}
+
+// Token is a token of the compiled script, kept for the line numbers of
+// errors.
+type Token = token.Token
+
+// Tok returns a token of the compiled script.
+func Tok(typ, lexeme string, line, column int) Token {
+	return token.NewToken(token.TokenType(typ), lexeme, nil, line, column)
+}
+
+// in provides the natives with the interpreter they expect.
+var in interpreter.Interpreter
+
+// Main runs the main module of a compiled program, which was compiled from
+// the script at path, and exits with status 70 if it raises an error.
+func Main(path string, body func(m *Module)) {
+	in = interpreter.NewInterpreter(interpreter.WithArgs(os.Args[1:]))
+
+	m := &Module{path: path, Env: in.GetGlobalEnv(), loading: true}
+	modules[path] = m
+	importing = []string{path}
+
+	err := run(func() { body(m) })
+	if err != nil {
+		fmt.Printf("Error while interpreting : %v\n", err)
+		os.Exit(70)
+	}
+}
+
+// run calls fn, returning the Lox error it raised.
+func run(fn func()) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(r)
+		}
+	}()
+
+	fn()
+
+	return nil
+}
+
+// recovered returns the Lox error carried by a panic, and panics again
+// with anything else, like a Go runtime error.
+func recovered(r interface{}) error {
+	if err, ok := r.(error); ok {
+		if _, ok := err.(runtime.Error); !ok {
+			return err
+		}
+	}
+
+	panic(r)
+}
+
+// Fail raises a runtime error at t.
+func Fail(t Token, msg string) {
+	panic(lerr.NewRuntimeErr(t, msg))
+}
+
+// check raises err if it is not nil, and returns value otherwise.
+func check(value Value, err error) Value {
+	if err != nil {
+		panic(err)
+	}
+
+	return value
+}
+
+// Print prints a value like the print statement.
+func Print(v Value) {
+	fmt.Printf("%s\n", interpreter.Stringify(v))
+}
+
+func Truthy(v Value) bool {
+	return interpreter.Truthy(v)
+}
+
+func Equal(a, b Value) bool {
+	return interpreter.Equal(a, b)
+}
+
+func Binary(operator Token, left, right Value) Value {
+	return check(interpreter.Binary(operator, left, right))
+}
+
+func Unary(operator Token, right Value) Value {
+	return check(interpreter.Unary(operator, right))
+}
+
+// Read returns v. Compiled code reads a variable through it when a later
+// operand could change the variable, since Go only orders the calls among
+// the operands it evaluates.
+func Read(v Value) Value {
+	return v
+}
+
+// Set assigns a value to a local variable, returning the value.
+func Set(variable *Value, value Value) Value {
+	*variable = value
+	return value
+}
+
+// Get returns the property called name of a module or map.
+func Get(object Value, name Token) Value {
+	if m, ok := object.(*Module); ok {
+		value, found := m.Env.Lookup(name.GetLexeme())
+		if !found {
+			Fail(name, fmt.Sprintf("Undefined property '%s' on %s.", name.GetLexeme(), m))
+		}
+
+		return value
+	}
+
+	return check(interpreter.Property(object, name))
+}
+
+// Throw raises a value, as the throw statement at keyword does.
+func Throw(keyword Token, value Value) {
+	panic(&interpreter.Throw{Value: value, Keyword: keyword})
+}
+
+// Try runs a try statement. Each of its blocks reports whether it left by
+// returning from the function it is in, with the value returned. catch is
+// nil without a catch clause, and finally without a finally clause.
+//
+// catch runs if body raised a value or runtime error, with the value it
+// binds. finally runs however body and catch were left, and an error or
+// return from finally takes precedence.
+func Try(body func() (Value, bool), catch func(Value) (Value, bool), finally func() (Value, bool)) (Value, bool) {
+	value, returned, err := try(body)
+
+	if err != nil && catch != nil {
+		if caught, ok := interpreter.Caught(err); ok {
+			value, returned, err = try(func() (Value, bool) { return catch(caught) })
+		}
+	}
+
+	if finally != nil {
+		fvalue, freturned, ferr := try(finally)
+		if ferr != nil {
+			panic(ferr)
+		}
+		if freturned {
+			return fvalue, true
+		}
+	}
+
+	if err != nil {
+		panic(err)
+	}
+
+	return value, returned
+}
+
+func try(block func() (Value, bool)) (value Value, returned bool, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = recovered(r)
+		}
+	}()
+
+	value, returned = block()
+
+	return value, returned, nil
+}
+
+// Module is a compiled Lox source file and the environment holding its
+// globals.
+type Module struct {
+	path    string
+	Env     *environment.Environment
+	loading bool
+}
+
+func (m *Module) String() string {
+	return "<module " + strings.TrimSuffix(filepath.Base(m.path), filepath.Ext(m.path)) + ">"
+}
+
+// Define defines a global.
+func (m *Module) Define(name string, value Value) {
+	m.Env.Define(name, value)
+}
+
+// Get returns the value of a global, or of a native.
+func (m *Module) Get(name Token) Value {
+	return check(m.Env.Get(name))
+}
+
+// Set assigns a value to a global, returning the value.
+func (m *Module) Set(name Token, value Value) Value {
+	if err := m.Env.Assign(name, value); err != nil {
+		panic(err)
+	}
+
+	return value
+}
+
+var (
+	modules   = map[string]*Module{}
+	importing []string
+)
+
+// Import returns the module compiled from the file at path, running body
+// to load it the first time it is imported. pathTok is the path in the
+// import statement.
+func Import(pathTok Token, path string, body func(m *Module)) *Module {
+	if m, ok := modules[path]; ok {
+		if m.loading {
+			chain := append(append([]string{}, importing...), path)
+			Fail(pathTok, "Import cycle: "+strings.Join(chain, " -> ")+".")
+		}
+
+		return m
+	}
+
+	builtins := in.GetGlobalEnv().Enclosing()
+	m := &Module{path: path, Env: environment.NewEnvironment(builtins), loading: true}
+	modules[path] = m
+
+	importing = append(importing, path)
+	defer func() {
+		importing = importing[:len(importing)-1]
+
+		// A module that failed to load is run again by the next import.
+		if m.loading {
+			delete(modules, path)
+		}
+	}()
+
+	body(m)
+	m.loading = false
+
+	return m
+}
+
+// Member returns the definition called name in module m, for an import
+// statement listing names.
+func Member(m *Module, name Token) Value {
+	value, ok := m.Env.Lookup(name.GetLexeme())
+	if !ok {
+		Fail(name, fmt.Sprintf("Module %s has no definition '%s'.", m, name.GetLexeme()))
+	}
+
+	return value
+}
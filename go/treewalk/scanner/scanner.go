@@ -4,6 +4,7 @@ import (
 	"glox/lerr"
 	"glox/token"
 	"strconv"
+	"unicode/utf8"
 )
 
 type Scanner interface {
@@ -16,25 +17,34 @@ type scanner struct {
 	start   int
 	current int
 	line    int
+
+	// lineStart is the offset of the first byte of the current line, and
+	// startLine and startColumn the position of the token being scanned.
+	lineStart   int
+	startLine   int
+	startColumn int
 }
 
 func NewScanner(source string) Scanner {
 	return &scanner{
 		source: source,
 		tokens: make([]token.Token, 0),
+		line:   1,
 	}
 }
 
 func (s *scanner) ScanTokens() ([]token.Token, error) {
 	for !s.isAtEnd() {
 		s.start = s.current
+		s.startLine = s.line
+		s.startColumn = s.column()
 		err := s.scanToken()
 		if err != nil {
 			return nil, err
 		}
 	}
 
-	s.tokens = append(s.tokens, token.NewToken(token.EOF, "", nil, s.line))
+	s.tokens = append(s.tokens, token.NewToken(token.EOF, "", nil, s.line, s.column()))
 
 	return s.tokens, nil
 }
@@ -55,7 +65,12 @@ func (s *scanner) scanToken() error {
 	case ',':
 		s.addToken(token.COMMA, nil)
 	case '.':
-		s.addToken(token.DOT, nil)
+		if s.peek() == '.' && s.peekNext() == '.' {
+			s.current += 2
+			s.addToken(token.ELLIPSIS, nil)
+		} else {
+			s.addToken(token.DOT, nil)
+		}
 	case '-':
 		s.addToken(token.MINUS, nil)
 	case '+':
@@ -64,6 +79,24 @@ func (s *scanner) scanToken() error {
 		s.addToken(token.SEMICOLON, nil)
 	case '*':
 		s.addToken(token.STAR, nil)
+	case '%':
+		s.addToken(token.PERCENT, nil)
+	case '?':
+		s.addToken(token.QUESTION, nil)
+	case ':':
+		s.addToken(token.COLON, nil)
+	case '&':
+		s.addToken(token.AMPERSAND, nil)
+	case '|':
+		s.addToken(token.PIPE, nil)
+	case '^':
+		s.addToken(token.CARET, nil)
+	case '~':
+		if s.match('/') {
+			s.addToken(token.TILDE_SLASH, nil)
+		} else {
+			s.addToken(token.TILDE, nil)
+		}
 
 	// logical operators
 	case '!':
@@ -75,18 +108,24 @@ func (s *scanner) scanToken() error {
 	case '=':
 		if s.match('=') {
 			s.addToken(token.EQUAL_EQUAL, nil)
+		} else if s.match('>') {
+			s.addToken(token.ARROW, nil)
 		} else {
 			s.addToken(token.EQUAL, nil)
 		}
 	case '<':
 		if s.match('=') {
 			s.addToken(token.LESS_EQUAL, nil)
+		} else if s.match('<') {
+			s.addToken(token.LESS_LESS, nil)
 		} else {
 			s.addToken(token.LESS, nil)
 		}
 	case '>':
 		if s.match('=') {
 			s.addToken(token.GREATER_EQUAL, nil)
+		} else if s.match('>') {
+			s.addToken(token.GREATER_GREATER, nil)
 		} else {
 			s.addToken(token.GREATER, nil)
 		}
@@ -110,19 +149,19 @@ func (s *scanner) scanToken() error {
 		break
 
 	case '\n':
-		s.line++
+		s.newline()
 
 	// string
 	case '"':
-		s.strScan()
+		return s.strScan()
 	default:
 		if s.isDigit(c) {
-			s.numScan()
+			return s.numScan()
 		} else if s.isAlpha(c) {
 			s.idenScan()
 		} else {
 
-			return lerr.NewSyntaxErr(s.line, "", "Unexpected character.")
+			return lerr.NewSyntaxErrAt(s.span(), "", "Unexpected character.")
 		}
 	}
 
@@ -131,22 +170,45 @@ func (s *scanner) scanToken() error {
 
 func (s *scanner) addToken(tokenType token.TokenType, literal interface{}) {
 	text := s.source[s.start:s.current]
-	s.tokens = append(s.tokens, token.NewToken(tokenType, text, literal, s.line))
+	s.tokens = append(s.tokens, token.NewToken(tokenType, text, literal, s.startLine, s.startColumn))
 }
 
-func (s *scanner) strScan() {
+// newline is called after consuming a line break.
+func (s *scanner) newline() {
+	s.line++
+	s.lineStart = s.current
+}
+
+// column returns the column of the next character.
+func (s *scanner) column() int {
+	return utf8.RuneCountInString(s.source[s.lineStart:s.current])
+}
+
+// span returns the span of the source scanned for the current token.
+func (s *scanner) span() lerr.Span {
+	return lerr.Span{
+		Start: lerr.Position{Line: s.startLine, Column: s.startColumn + 1},
+		End:   lerr.Position{Line: s.line, Column: s.column() + 1},
+	}
+}
+
+func (s *scanner) strScan() error {
 	for s.peek() != '"' && !s.isAtEnd() {
-		if s.peek() == '\n' {
-			s.line++
+		if s.advance() == '\n' {
+			s.newline()
 		}
+	}
 
-		s.advance()
+	if s.isAtEnd() {
+		return lerr.NewSyntaxErrAt(s.span(), "", "Unterminated string.")
 	}
 
 	s.advance()
 
 	value := s.source[s.start+1 : s.current-1]
 	s.addToken(token.STRING, value)
+
+	return nil
 }
 
 func (s *scanner) numScan() error {
@@ -160,11 +222,21 @@ func (s *scanner) numScan() error {
 		for s.isDigit(s.peek()) {
 			s.advance()
 		}
+
+		num, err := strconv.ParseFloat(s.source[s.start:s.current], 64)
+		if err != nil {
+			return err
+		}
+
+		s.addToken(token.NUMBER, num)
+
+		return nil
 	}
 
-	num, err := strconv.ParseFloat(s.source[s.start:s.current], 64)
+	// Literals without a fractional part are integers.
+	num, err := strconv.ParseInt(s.source[s.start:s.current], 10, 64)
 	if err != nil {
-		return err
+		return lerr.NewSyntaxErrAt(s.span(), "", "Integer literal out of range.")
 	}
 
 	s.addToken(token.NUMBER, num)
@@ -220,7 +292,7 @@ func (s *scanner) peek() rune {
 }
 
 func (s *scanner) peekNext() rune {
-	if s.current+1 > len(s.source) {
+	if s.current+1 >= len(s.source) {
 		return rune(0)
 	}
 
@@ -0,0 +1,48 @@
+package scanner
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPositions(t *testing.T) {
+	tokens, err := NewScanner("var s = \"a\nb\";\n  print \"é\" + s;").ScanTokens()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := []struct {
+		lexeme       string
+		line, column int
+	}{
+		{"var", 1, 0},
+		{"s", 1, 4},
+		{"=", 1, 6},
+		{"\"a\nb\"", 1, 8},
+		{";", 2, 2},
+		{"print", 3, 2},
+		{"\"é\"", 3, 8},
+		{"+", 3, 12},
+		{"s", 3, 14},
+		{";", 3, 15},
+		{"", 3, 16},
+	}
+	if len(tokens) != len(want) {
+		t.Fatalf("got %d tokens, want %d", len(tokens), len(want))
+	}
+
+	for idx, w := range want {
+		tok := tokens[idx]
+		if tok.GetLexeme() != w.lexeme || tok.GetLine() != w.line || tok.GetColumn() != w.column {
+			t.Errorf("token %d: got %q at %d:%d, want %q at %d:%d", idx,
+				tok.GetLexeme(), tok.GetLine(), tok.GetColumn(), w.lexeme, w.line, w.column)
+		}
+	}
+}
+
+func TestUnterminatedString(t *testing.T) {
+	_, err := NewScanner("print \"abc;").ScanTokens()
+	if err == nil || !strings.Contains(err.Error(), "Unterminated string.") {
+		t.Errorf("got %v, want an unterminated string error", err)
+	}
+}
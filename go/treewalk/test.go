@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"glox/coverage"
+	"glox/interpreter"
+	"glox/tester"
+	"io"
+	"os"
+)
+
+// testCmd implements `glox test [flags] [path...]`. Each path is a test
+// script, or a directory searched for scripts named *_test.lox; the
+// default is the working directory. Scripts declare tests with
+// `test "name" { ... }`, and a script declaring none is a test of its own.
+//
+// Results are reported as text, TAP or JUnit XML. With --coverage the
+// statements, branches and functions the tests ran are summarized and
+// written as LCOV. It returns the exit status: 1 if a test failed.
+func testCmd(args []string) int {
+	flags := flag.NewFlagSet("test", flag.ExitOnError)
+	format := flags.String("format", "text", "report results as text, tap or junit")
+	output := flags.String("output", "", "write the report to `file` instead of stdout")
+	cover := flags.Bool("coverage", false, "record which statements and branches the tests run")
+	lcov := flags.String("lcov", "lcov.info", "write coverage in LCOV format to `file`")
+	flags.Parse(args)
+
+	if *format != "text" && *format != "tap" && *format != "junit" {
+		fmt.Fprintf(os.Stderr, "Unknown format '%s'.\n", *format)
+		return 64
+	}
+
+	paths := flags.Args()
+	if len(paths) == 0 {
+		paths = []string{"."}
+	}
+
+	scripts, err := tester.Find(paths)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 66
+	}
+
+	var out io.Writer = os.Stdout
+	if *output != "" {
+		f, err := os.Create(*output)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 73
+		}
+		defer f.Close()
+		out = f
+	}
+
+	// Text reports stream what tests print; the others carry it.
+	var opts []interpreter.Option
+	if *format == "text" {
+		opts = append(opts, interpreter.WithStdout(os.Stdout))
+	}
+
+	var collector *coverage.Collector
+	if *cover {
+		collector = coverage.NewCollector()
+		opts = append(opts, interpreter.WithCoverage(collector))
+	}
+
+	var results []tester.Result
+	for _, script := range scripts {
+		r := tester.Run(script, opts...)
+		if *format == "text" {
+			tester.WriteText(out, r)
+		}
+		results = append(results, r...)
+	}
+
+	passed, failed := tester.Summary(results)
+	switch *format {
+	case "text":
+		fmt.Fprintf(out, "\n%d passed, %d failed\n", passed, failed)
+	case "tap":
+		tester.WriteTAP(out, results)
+	case "junit":
+		if err := tester.WriteJUnit(out, results); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 74
+		}
+	}
+
+	if collector != nil {
+		// Keep machine-readable reports on stdout parseable.
+		summary := os.Stdout
+		if *format != "text" && *output == "" {
+			summary = os.Stderr
+		}
+
+		fmt.Fprintln(summary)
+		collector.Summary(summary, isTerminal(summary))
+
+		if err := writeLCOV(collector, *lcov); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 74
+		}
+	}
+
+	if failed != 0 {
+		return 1
+	}
+
+	return 0
+}
+
+func writeLCOV(collector *coverage.Collector, file string) error {
+	f, err := os.Create(file)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return collector.WriteLCOV(f)
+}
+
+// isTerminal reports whether f is a terminal that should get colored
+// output.
+func isTerminal(f *os.File) bool {
+	if os.Getenv("NO_COLOR") != "" || os.Getenv("TERM") == "dumb" {
+		return false
+	}
+
+	info, err := f.Stat()
+	if err != nil {
+		return false
+	}
+
+	return info.Mode()&os.ModeCharDevice != 0
+}
@@ -0,0 +1,162 @@
+package tester
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Summary counts the results.
+func Summary(results []Result) (passed, failed int) {
+	for _, r := range results {
+		if r.Passed() {
+			passed++
+		} else {
+			failed++
+		}
+	}
+
+	return passed, failed
+}
+
+// WriteText writes one line per result, followed by the reason for each
+// failure.
+func WriteText(w io.Writer, results []Result) {
+	for _, r := range results {
+		status := "ok  "
+		if !r.Passed() {
+			status = "FAIL"
+		}
+
+		fmt.Fprintf(w, "%s %s (%s)\n", status, r.Title(), duration(r.Duration))
+		if !r.Passed() {
+			fmt.Fprintf(w, "    %s\n", strings.ReplaceAll(r.Message(), "\n", "\n    "))
+		}
+	}
+}
+
+// WriteTAP writes the results in version 13 of the Test Anything Protocol,
+// with a YAML block describing each failure and what each test printed.
+func WriteTAP(w io.Writer, results []Result) {
+	fmt.Fprintln(w, "TAP version 13")
+	fmt.Fprintf(w, "1..%d\n", len(results))
+
+	for idx, r := range results {
+		status := "ok"
+		if !r.Passed() {
+			status = "not ok"
+		}
+
+		fmt.Fprintf(w, "%s %d - %s # time=%s\n", status, idx+1, r.Title(), duration(r.Duration))
+		if r.Passed() && r.Output == "" {
+			continue
+		}
+
+		fmt.Fprintln(w, "  ---")
+		if !r.Passed() {
+			fmt.Fprintf(w, "  message: %s\n", strconv.Quote(r.Message()))
+			fmt.Fprintf(w, "  file: %s\n", strconv.Quote(r.File))
+			if r.Line > 0 {
+				fmt.Fprintf(w, "  line: %d\n", r.Line)
+			}
+		}
+		if r.Output != "" {
+			fmt.Fprintf(w, "  output: %s\n", strconv.Quote(r.Output))
+		}
+		fmt.Fprintln(w, "  ...")
+	}
+}
+
+type junitSuites struct {
+	XMLName  xml.Name     `xml:"testsuites"`
+	Tests    int          `xml:"tests,attr"`
+	Failures int          `xml:"failures,attr"`
+	Time     string       `xml:"time,attr"`
+	Suites   []junitSuite `xml:"testsuite"`
+}
+
+type junitSuite struct {
+	Name     string      `xml:"name,attr"`
+	Tests    int         `xml:"tests,attr"`
+	Failures int         `xml:"failures,attr"`
+	Time     string      `xml:"time,attr"`
+	Cases    []junitCase `xml:"testcase"`
+}
+
+type junitCase struct {
+	Name      string        `xml:"name,attr"`
+	Classname string        `xml:"classname,attr"`
+	File      string        `xml:"file,attr"`
+	Line      int           `xml:"line,attr,omitempty"`
+	Time      string        `xml:"time,attr"`
+	Failure   *junitFailure `xml:"failure,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitFailure struct {
+	Message string `xml:"message,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the results as JUnit XML, with one test suite per
+// script and what each test printed as its system-out.
+func WriteJUnit(w io.Writer, results []Result) error {
+	var doc junitSuites
+	var total time.Duration
+	var times []time.Duration
+	index := map[string]int{}
+
+	for _, r := range results {
+		idx, ok := index[r.File]
+		if !ok {
+			idx = len(doc.Suites)
+			index[r.File] = idx
+			doc.Suites = append(doc.Suites, junitSuite{Name: r.File})
+			times = append(times, 0)
+		}
+
+		name := r.Name
+		if name == "" {
+			name = r.File
+		}
+
+		c := junitCase{Name: name, Classname: r.File, File: r.File, Line: r.Line, Time: seconds(r.Duration), SystemOut: r.Output}
+		if !r.Passed() {
+			c.Failure = &junitFailure{Message: r.Message(), Text: r.Message()}
+			doc.Suites[idx].Failures++
+			doc.Failures++
+		}
+
+		s := &doc.Suites[idx]
+		s.Cases = append(s.Cases, c)
+		s.Tests++
+		doc.Tests++
+		times[idx] += r.Duration
+		total += r.Duration
+	}
+
+	for idx := range doc.Suites {
+		doc.Suites[idx].Time = seconds(times[idx])
+	}
+	doc.Time = seconds(total)
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(w, "%s%s\n", xml.Header, out)
+
+	return err
+}
+
+func duration(d time.Duration) string {
+	return fmt.Sprintf("%.3fms", float64(d)/float64(time.Millisecond))
+}
+
+func seconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', 6, 64)
+}
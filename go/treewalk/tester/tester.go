@@ -0,0 +1,205 @@
+package tester
+
+import (
+	"bytes"
+	"fmt"
+	"glox/generated"
+	"glox/interpreter"
+	"glox/lerr"
+	"glox/parser"
+	"glox/resolver"
+	"glox/scanner"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// Result is the outcome of one test. A script declaring no tests is a
+// test of its own, as is a script that fails before its tests can run;
+// their results have no Name.
+type Result struct {
+	File     string
+	Name     string
+	Line     int
+	Err      error
+	Duration time.Duration
+	// Output is what the test printed, including what the script printed
+	// when run for it.
+	Output string
+}
+
+func (r Result) Passed() bool {
+	return r.Err == nil
+}
+
+// Title names the test in reports.
+func (r Result) Title() string {
+	if r.Name == "" {
+		return r.File
+	}
+
+	return r.File + " > " + r.Name
+}
+
+// Message describes why the test failed, adding the line runtime errors
+// were raised on.
+func (r Result) Message() string {
+	if r.Err == nil {
+		return ""
+	}
+
+	msg := strings.TrimSpace(r.Err.Error())
+	if e, ok := r.Err.(*lerr.RuntimeErr); ok && e.Line() > 0 {
+		msg = fmt.Sprintf("[line %d] %s", e.Line(), msg)
+	}
+
+	return msg
+}
+
+// Find expands the directories among paths into the test scripts they
+// contain, which are named *_test.lox.
+func Find(paths []string) ([]string, error) {
+	var scripts []string
+	for _, path := range paths {
+		info, err := os.Stat(path)
+		if err != nil {
+			return nil, err
+		}
+
+		if !info.IsDir() {
+			scripts = append(scripts, path)
+			continue
+		}
+
+		err = filepath.WalkDir(path, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if !d.IsDir() && strings.HasSuffix(p, "_test.lox") {
+				scripts = append(scripts, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return scripts, nil
+}
+
+// test is a test declared by a script. out holds what its interpreter
+// printed.
+type test struct {
+	name string
+	line int
+	run  func() error
+	out  *bytes.Buffer
+}
+
+// recorder collects the tests a script declares, and what the interpreter
+// running it prints. It implements interpreter.Tests.
+type recorder struct {
+	tests []test
+	out   bytes.Buffer
+}
+
+func (r *recorder) Test(name string, line int, run func() error) {
+	r.tests = append(r.tests, test{name: name, line: line, run: run, out: &r.out})
+}
+
+// Run runs the tests in a script. Each test gets an interpreter of its
+// own, which first runs the whole script, so that a test sees the state
+// the script sets up but not what other tests did to it. opts configure
+// every interpreter; what the tests print is captured in their results
+// unless opts set where it goes.
+func Run(script string, opts ...interpreter.Option) []Result {
+	start := time.Now()
+	fail := func(err error, output string) []Result {
+		return []Result{{File: script, Err: err, Duration: time.Since(start), Output: output}}
+	}
+
+	prog, err := os.ReadFile(script)
+	if err != nil {
+		return fail(err, "")
+	}
+
+	stmts, err := parse(string(prog))
+	if err != nil {
+		return fail(err, "")
+	}
+
+	rec, err := load(script, stmts, opts)
+	if err != nil {
+		return fail(err, rec.out.String())
+	}
+
+	tests := rec.tests
+	if len(tests) == 0 {
+		return []Result{{File: script, Duration: time.Since(start), Output: rec.out.String()}}
+	}
+
+	results := make([]Result, 0, len(tests))
+	for idx, t := range tests {
+		result := Result{File: script, Name: t.name, Line: t.line}
+
+		if idx > 0 {
+			fresh, err := load(script, stmts, opts)
+			if err == nil && len(fresh.tests) != len(tests) {
+				err = fmt.Errorf("Script declared %d tests when run again, not %d.", len(fresh.tests), len(tests))
+			}
+			if err != nil {
+				result.Err = err
+				result.Output = fresh.out.String()
+				results = append(results, result)
+				continue
+			}
+
+			t = fresh.tests[idx]
+		}
+
+		start := time.Now()
+		result.Err = t.run()
+		result.Duration = time.Since(start)
+		result.Output = t.out.String()
+		results = append(results, result)
+	}
+
+	return results
+}
+
+// load runs a script in a new interpreter and returns the recorder of the
+// tests it declared, which holds what it printed even if it failed.
+func load(script string, stmts []generated.Stmt, opts []interpreter.Option) (*recorder, error) {
+	rec := &recorder{}
+
+	opts = append([]interpreter.Option{
+		interpreter.WithScriptPath(script),
+		interpreter.WithTests(rec),
+		interpreter.WithStdout(&rec.out),
+	}, opts...)
+	in := interpreter.NewInterpreter(opts...)
+
+	err := resolver.NewResolver(in).Resolve(stmts)
+	if err != nil {
+		return rec, err
+	}
+
+	err = in.Run(stmts)
+	if err != nil {
+		return rec, err
+	}
+
+	return rec, nil
+}
+
+func parse(source string) ([]generated.Stmt, error) {
+	tokens, err := scanner.NewScanner(source).ScanTokens()
+	if err != nil {
+		return nil, err
+	}
+
+	return parser.NewParser(tokens).Parse()
+}
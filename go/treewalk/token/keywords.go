@@ -1,20 +1,28 @@
 package token
 
 var Keywords map[string]TokenType = map[string]TokenType{
-	"and":    AND,
-	"class":  CLASS,
-	"else":   ELSE,
-	"false":  FALSE,
-	"for":    FOR,
-	"fun":    FUN,
-	"if":     IF,
-	"nil":    NIL,
-	"or":     OR,
-	"print":  PRINT,
-	"return": RETURN,
-	"super":  SUPER,
-	"this":   THIS,
-	"true":   TRUE,
-	"var":    VAR,
-	"while":  WHILE,
+	"and":     AND,
+	"case":    CASE,
+	"catch":   CATCH,
+	"class":   CLASS,
+	"default": DEFAULT,
+	"else":    ELSE,
+	"false":   FALSE,
+	"finally": FINALLY,
+	"for":     FOR,
+	"fun":     FUN,
+	"if":      IF,
+	"import":  IMPORT,
+	"match":   MATCH,
+	"nil":     NIL,
+	"or":      OR,
+	"print":   PRINT,
+	"return":  RETURN,
+	"super":   SUPER,
+	"this":    THIS,
+	"throw":   THROW,
+	"true":    TRUE,
+	"try":     TRY,
+	"var":     VAR,
+	"while":   WHILE,
 }
@@ -8,6 +8,9 @@ type Token interface {
 	GetLiteral() interface{}
 	GetType() TokenType
 	GetLine() int
+	// GetColumn returns the 0-based offset of the token in its line,
+	// counted in runes.
+	GetColumn() int
 }
 
 type token struct {
@@ -15,6 +18,7 @@ type token struct {
 	lexeme    string
 	literal   interface{}
 	line      int
+	column    int
 }
 
 func (t *token) Show() string {
@@ -37,16 +41,22 @@ func (t *token) GetLine() int {
 	return t.line
 }
 
+func (t *token) GetColumn() int {
+	return t.column
+}
+
 func NewToken(
 	tokenType TokenType,
 	lexeme string,
 	literal interface{},
 	line int,
+	column int,
 ) Token {
 	return &token{
 		tokenType: tokenType,
 		lexeme:    lexeme,
 		literal:   literal,
 		line:      line,
+		column:    column,
 	}
 }
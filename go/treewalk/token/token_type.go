@@ -10,21 +10,31 @@ var (
 	RIGHT_BRACE TokenType = "RIGHT_BRACE"
 	COMMA       TokenType = "COMMA"
 	DOT         TokenType = "DOT"
+	ELLIPSIS    TokenType = "ELLIPSIS"
 	MINUS       TokenType = "MINUS"
 	PLUS        TokenType = "PLUS"
 	SEMICOLON   TokenType = "SEMICOLON"
 	SLASH       TokenType = "SLASH"
 	STAR        TokenType = "STAR"
+	PERCENT     TokenType = "PERCENT"
+	AMPERSAND   TokenType = "AMPERSAND"
+	PIPE        TokenType = "PIPE"
+	CARET       TokenType = "CARET"
+	TILDE       TokenType = "TILDE"
 
 	// One or two character tokens.
-	BANG          TokenType = "BANG"
-	BANG_EQUAL    TokenType = "BANG_EQUAL"
-	EQUAL         TokenType = "EQUAL"
-	EQUAL_EQUAL   TokenType = "EQUAL_EQUAL"
-	GREATER       TokenType = "GREATER"
-	GREATER_EQUAL TokenType = "GREATER_EQUAL"
-	LESS          TokenType = "LESS"
-	LESS_EQUAL    TokenType = "LESS_EQUAL"
+	BANG            TokenType = "BANG"
+	BANG_EQUAL      TokenType = "BANG_EQUAL"
+	EQUAL           TokenType = "EQUAL"
+	EQUAL_EQUAL     TokenType = "EQUAL_EQUAL"
+	GREATER         TokenType = "GREATER"
+	GREATER_EQUAL   TokenType = "GREATER_EQUAL"
+	LESS            TokenType = "LESS"
+	LESS_EQUAL      TokenType = "LESS_EQUAL"
+	ARROW           TokenType = "ARROW"
+	TILDE_SLASH     TokenType = "TILDE_SLASH"
+	LESS_LESS       TokenType = "LESS_LESS"
+	GREATER_GREATER TokenType = "GREATER_GREATER"
 
 	//Conditional
 	QUESTION TokenType = "?"
@@ -36,21 +46,29 @@ var (
 	NUMBER     TokenType = "NUMBER"
 
 	// Keywords.
-	AND    TokenType = "AND"
-	CLASS  TokenType = "CLASS"
-	ELSE   TokenType = "ELSE"
-	FALSE  TokenType = "FALSE"
-	FUN    TokenType = "FUN"
-	FOR    TokenType = "FOR"
-	IF     TokenType = "IF"
-	NIL    TokenType = "NIL"
-	OR     TokenType = "OR"
-	PRINT  TokenType = "PRINT"
-	RETURN TokenType = "RETURN"
-	SUPER  TokenType = "SUPER"
-	THIS   TokenType = "THIS"
-	TRUE   TokenType = "TRUE"
-	VAR    TokenType = "VAR"
-	WHILE  TokenType = "WHILE"
-	EOF    TokenType = "EOF"
+	AND     TokenType = "AND"
+	CASE    TokenType = "CASE"
+	CATCH   TokenType = "CATCH"
+	CLASS   TokenType = "CLASS"
+	DEFAULT TokenType = "DEFAULT"
+	ELSE    TokenType = "ELSE"
+	FALSE   TokenType = "FALSE"
+	FINALLY TokenType = "FINALLY"
+	FUN     TokenType = "FUN"
+	FOR     TokenType = "FOR"
+	IF      TokenType = "IF"
+	IMPORT  TokenType = "IMPORT"
+	MATCH   TokenType = "MATCH"
+	NIL     TokenType = "NIL"
+	OR      TokenType = "OR"
+	PRINT   TokenType = "PRINT"
+	RETURN  TokenType = "RETURN"
+	SUPER   TokenType = "SUPER"
+	THIS    TokenType = "THIS"
+	THROW   TokenType = "THROW"
+	TRUE    TokenType = "TRUE"
+	TRY     TokenType = "TRY"
+	VAR     TokenType = "VAR"
+	WHILE   TokenType = "WHILE"
+	EOF     TokenType = "EOF"
 )
@@ -0,0 +1,20 @@
+//go:build (js && wasm) || wasip1
+
+// Command wasm is the interpreter compiled to WebAssembly. Scripts run in
+// memory through package playground, with what they print captured rather
+// than written out.
+//
+// For browsers and Node.js, build it with
+//
+//	GOOS=js GOARCH=wasm go build -o glox.wasm ./wasm
+//
+// and load it with the wasm_exec.js shipped in $(go env GOROOT)/lib/wasm.
+// It defines glox.eval(source).
+//
+// For WASI runtimes, build it with
+//
+//	GOOS=wasip1 GOARCH=wasm go build -o glox.wasm ./wasm
+//
+// and run it on a script, or with the script on stdin, to get its result
+// as JSON.
+package main
@@ -0,0 +1,37 @@
+//go:build js && wasm
+
+package main
+
+import (
+	"encoding/json"
+	"glox/playground"
+	"syscall/js"
+)
+
+// main exposes the interpreter to JavaScript as the global object glox,
+// then waits for calls to it.
+//
+// glox.eval(source) runs a script and returns an object holding what it
+// printed as stdout, the errors that stopped it as diagnostics and its
+// exit status as status, as described by playground.Result.
+func main() {
+	js.Global().Set("glox", js.ValueOf(map[string]interface{}{
+		"eval": js.FuncOf(eval),
+	}))
+
+	select {}
+}
+
+func eval(this js.Value, args []js.Value) interface{} {
+	source := ""
+	if len(args) > 0 {
+		source = args[0].String()
+	}
+
+	out, err := json.Marshal(playground.Eval(source))
+	if err != nil {
+		panic(err)
+	}
+
+	return js.Global().Get("JSON").Call("parse", string(out))
+}
@@ -0,0 +1,39 @@
+//go:build wasip1
+
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"glox/playground"
+	"io"
+	"os"
+)
+
+// main runs the script named by its argument, or read from stdin without
+// one, and writes the playground.Result of running it to stdout as JSON.
+// It exits with the status of the script.
+func main() {
+	var source []byte
+	var err error
+	if len(os.Args) > 1 {
+		source, err = os.ReadFile(os.Args[1])
+	} else {
+		source, err = io.ReadAll(os.Stdin)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(66)
+	}
+
+	result := playground.Eval(string(source))
+
+	out, err := json.Marshal(result)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(74)
+	}
+
+	fmt.Println(string(out))
+	os.Exit(result.Status)
+}